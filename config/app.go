@@ -4,30 +4,46 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	AppUrl             string
-	FrontendURL        string
-	ServerPort         int
-	DBHost             string
-	DBPort             int
-	DBUser             string
-	DBPassword         string
-	DBName             string
-	RedisAddr          string
-	RedisPassword      string
-	RedisDB            int
-	JWTSecretKey       string
-	JWTExpirationHours int
-	Minio              MinioConfig
-	Email              EmailConfig
-	GoogleClientID     string
-	GoogleClientSecret string
-	CookieDomain       string
-	SentryDSN          string
+	AppUrl               string
+	FrontendURL          string
+	ServerPort           int
+	DBHost               string
+	DBPort               int
+	DBUser               string
+	DBPassword           string
+	DBName               string
+	RedisAddr            string
+	RedisPassword        string
+	RedisDB              int
+	JWTSigningAlg        string
+	JWTExpirationHours   int
+	JWTKeyRetentionHours int
+	AccessTokenMinutes   int
+	RefreshTokenHours    int
+	// ImpersonationMinutes adalah umur token impersonation; tidak bisa diperpanjang.
+	ImpersonationMinutes int
+	Minio                MinioConfig
+	Email                EmailConfig
+	CookieDomain         string
+	// TrustedProxies adalah IP/CIDR reverse proxy yang header X-Forwarded-For-nya
+	// dipercaya untuk menentukan IP client. Kosong berarti tidak ada yang dipercaya.
+	TrustedProxies    []string
+	SentryDSN         string
+	OIDC              OIDCConfig
+	WebAuthn          WebAuthnConfig
+	RateLimit         RateLimitConfig
+	LoginLockout      LoginLockoutConfig
+	PasswordPolicy    PasswordPolicyConfig
+	EmailVerification EmailVerificationConfig
+	IdentityProviders []IdentityProviderConfig
+	LDAP              LDAPConfig
 }
 
 type MinioConfig struct {
@@ -39,6 +55,106 @@ type MinioConfig struct {
 	URL             string
 }
 
+type OIDCConfig struct {
+	Issuer string
+}
+
+// WebAuthnConfig menentukan relying party untuk passkey. RPID harus sama dengan
+// (atau domain induk dari) host halaman login, dan Origin adalah origin halaman tersebut.
+type WebAuthnConfig struct {
+	RPID   string
+	RPName string
+	Origin string
+}
+
+// RateLimitConfig berisi policy rate limit yang dipakai per grup route.
+// Allowlist berisi IP atau CIDR (mis. NAT kampus) yang dibebaskan dari policy
+// per IP, namun tetap terkena policy per akun dan per client.
+type RateLimitConfig struct {
+	Allowlist []string
+	Policies  map[string]RateLimitPolicyConfig
+}
+
+type RateLimitPolicyConfig struct {
+	// Scope adalah "ip", "account", atau "client"
+	Scope string
+	// Algorithm adalah "sliding_window" atau "token_bucket"
+	Algorithm string
+	Limit     int
+	Window    time.Duration
+}
+
+// LoginLockoutConfig mengatur penguncian akun bertahap setelah password salah
+// berulang kali. Durasi kunci berlipat dua untuk setiap kegagalan setelah
+// Threshold, dibatasi MaxDuration. Hitungan kegagalan hilang setelah ResetAfter
+// tanpa kegagalan baru.
+type LoginLockoutConfig struct {
+	Threshold    int
+	BaseDuration time.Duration
+	MaxDuration  time.Duration
+	ResetAfter   time.Duration
+}
+
+// PasswordPolicyConfig mengatur syarat password baru. BreachedListPath menunjuk
+// file berisi hash SHA-1 password yang pernah bocor, satu per baris (format
+// "HASH" atau "HASH:jumlah" seperti dump Pwned Passwords). Kosongkan untuk
+// menonaktifkan pemeriksaan tersebut.
+type PasswordPolicyConfig struct {
+	MinLength        int
+	RequireUpper     bool
+	RequireLower     bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	HistorySize      int
+	BreachedListPath string
+}
+
+// EmailVerificationConfig mengatur link verifikasi email. Link mengarah ke
+// backend, lalu user diarahkan ke SuccessURL atau FailureURL di frontend.
+type EmailVerificationConfig struct {
+	TTL            time.Duration
+	ResendCooldown time.Duration
+	DailyLimit     int
+	SuccessURL     string
+	FailureURL     string
+}
+
+// IdentityProviderConfig adalah satu IdP upstream berbasis OAuth 2.0/OIDC
+// (mis. Google atau Microsoft 365). TrustEmail mengizinkan akun ditautkan
+// otomatis lewat email terverifikasi dari provider; aktifkan hanya untuk
+// provider yang emailnya dikelola kampus.
+type IdentityProviderConfig struct {
+	Name         string
+	DisplayName  string
+	ClientID     string
+	ClientSecret string
+	AuthURL      string
+	TokenURL     string
+	UserInfoURL  string
+	RedirectURL  string
+	Scopes       []string
+	TrustEmail   bool
+}
+
+// LDAPConfig mengaktifkan autentikasi ke direktori LDAP/Active Directory bila
+// URL diisi. User dicari dengan UserFilter (%s diganti email yang sudah
+// di-escape) memakai akun service BindDN, lalu password dicek dengan bind
+// sebagai DN user tersebut.
+type LDAPConfig struct {
+	URL                string
+	StartTLS           bool
+	InsecureSkipVerify bool
+	BindDN             string
+	BindPassword       string
+	BaseDN             string
+	UserFilter         string
+	EmailAttribute     string
+	NameAttribute      string
+	Timeout            time.Duration
+	// Provision membuat user lokal saat login LDAP pertama bila belum ada.
+	Provision bool
+}
+
 type EmailConfig struct {
 	Host        string
 	Port        int
@@ -69,9 +185,14 @@ func LoadConfig() {
 		RedisPassword: getEnv("REDIS_PASSWORD", ""),
 		RedisDB:       getEnvAsInt("REDIS_DB", 0),
 
-		JWTSecretKey:       getEnv("JWT_SECRET_KEY", "secret"),
-		JWTExpirationHours: getEnvAsInt("JWT_EXPIRATION_HOURS", 72),
-		CookieDomain:       getEnv("COOKIE_DOMAIN", "localhost"),
+		JWTSigningAlg:        getEnv("JWT_SIGNING_ALG", "RS256"),
+		JWTExpirationHours:   getEnvAsInt("JWT_EXPIRATION_HOURS", 72),
+		JWTKeyRetentionHours: getEnvAsInt("JWT_KEY_RETENTION_HOURS", getEnvAsInt("JWT_EXPIRATION_HOURS", 72)),
+		AccessTokenMinutes:   getEnvAsInt("ACCESS_TOKEN_MINUTES", 15),
+		RefreshTokenHours:    getEnvAsInt("REFRESH_TOKEN_HOURS", 720),
+		ImpersonationMinutes: getEnvAsInt("IMPERSONATION_TTL_MINUTES", 30),
+		CookieDomain:         getEnv("COOKIE_DOMAIN", "localhost"),
+		TrustedProxies:       getEnvAsList("TRUSTED_PROXIES"),
 
 		Minio: MinioConfig{
 			AccessKeyID:     getEnv("MINIO_ACCESS_KEY_ID", "minioadmin"),
@@ -90,12 +211,184 @@ func LoadConfig() {
 			SenderName:  getEnv("MAIL_FROM_NAME", "JTI Super App"),
 			SenderEmail: getEnv("MAIL_FROM_ADDRESS", "no-reply-jti@polije.ac.id"),
 		},
-		GoogleClientID:     getEnv("GOOGLE_CLIENT_ID", ""),
-		GoogleClientSecret: getEnv("GOOGLE_CLIENT_SECRET", ""),
-		SentryDSN:          getEnv("SENTRY_DSN", ""),
+		SentryDSN: getEnv("SENTRY_DSN", ""),
+
+		OIDC: OIDCConfig{
+			Issuer: getEnv("OIDC_ISSUER", getEnv("APP_URL", "http://localhost:8000")),
+		},
+		WebAuthn: WebAuthnConfig{
+			RPID:   getEnv("WEBAUTHN_RP_ID", "localhost"),
+			RPName: getEnv("WEBAUTHN_RP_NAME", "JTI Super App"),
+			Origin: getEnv("WEBAUTHN_ORIGIN", getEnv("OIDC_ISSUER", getEnv("APP_URL", "http://localhost:8000"))),
+		},
+		RateLimit: RateLimitConfig{
+			Allowlist: getEnvAsList("RATE_LIMIT_ALLOWLIST"),
+			Policies:  loadRateLimitPolicies(),
+		},
+		LoginLockout: LoginLockoutConfig{
+			Threshold:    getEnvAsInt("LOGIN_LOCKOUT_THRESHOLD", 5),
+			BaseDuration: time.Duration(getEnvAsInt("LOGIN_LOCKOUT_BASE_SECONDS", 60)) * time.Second,
+			MaxDuration:  time.Duration(getEnvAsInt("LOGIN_LOCKOUT_MAX_SECONDS", 3600)) * time.Second,
+			ResetAfter:   time.Duration(getEnvAsInt("LOGIN_LOCKOUT_RESET_MINUTES", 60)) * time.Minute,
+		},
+		EmailVerification: EmailVerificationConfig{
+			TTL:            time.Duration(getEnvAsInt("EMAIL_VERIFICATION_TTL_HOURS", 24)) * time.Hour,
+			ResendCooldown: time.Duration(getEnvAsInt("EMAIL_VERIFICATION_COOLDOWN_SECONDS", 60)) * time.Second,
+			DailyLimit:     getEnvAsInt("EMAIL_VERIFICATION_DAILY_LIMIT", 5),
+			SuccessURL:     getEnv("EMAIL_VERIFIED_REDIRECT_URL", getEnv("APP_FRONTEND_URL", "http://localhost:3000")+"/email/verified"),
+			FailureURL:     getEnv("EMAIL_VERIFY_FAILED_REDIRECT_URL", getEnv("APP_FRONTEND_URL", "http://localhost:3000")+"/email/verify-failed"),
+		},
+		IdentityProviders: loadIdentityProviders(),
+		LDAP: LDAPConfig{
+			URL:                getEnv("LDAP_URL", ""),
+			StartTLS:           getEnvAsInt("LDAP_STARTTLS", 0) == 1,
+			InsecureSkipVerify: getEnvAsInt("LDAP_INSECURE_SKIP_VERIFY", 0) == 1,
+			BindDN:             getEnv("LDAP_BIND_DN", ""),
+			BindPassword:       getEnv("LDAP_BIND_PASSWORD", ""),
+			BaseDN:             getEnv("LDAP_BASE_DN", ""),
+			UserFilter:         getEnv("LDAP_USER_FILTER", "(&(objectClass=person)(mail=%s))"),
+			EmailAttribute:     getEnv("LDAP_EMAIL_ATTRIBUTE", "mail"),
+			NameAttribute:      getEnv("LDAP_NAME_ATTRIBUTE", "cn"),
+			Timeout:            time.Duration(getEnvAsInt("LDAP_TIMEOUT_SECONDS", 5)) * time.Second,
+			Provision:          getEnvAsInt("LDAP_PROVISION", 1) == 1,
+		},
+		PasswordPolicy: PasswordPolicyConfig{
+			MinLength:        getEnvAsInt("PASSWORD_MIN_LENGTH", 8),
+			RequireUpper:     getEnvAsInt("PASSWORD_REQUIRE_UPPER", 1) == 1,
+			RequireLower:     getEnvAsInt("PASSWORD_REQUIRE_LOWER", 1) == 1,
+			RequireDigit:     getEnvAsInt("PASSWORD_REQUIRE_DIGIT", 1) == 1,
+			RequireSymbol:    getEnvAsInt("PASSWORD_REQUIRE_SYMBOL", 0) == 1,
+			HistorySize:      getEnvAsInt("PASSWORD_HISTORY_SIZE", 5),
+			BreachedListPath: getEnv("PASSWORD_BREACHED_LIST", ""),
+		},
 	}
 }
 
+// loadIdentityProviders membaca IDP_PROVIDERS (dipisah koma). Setiap provider
+// dikonfigurasi lewat IDP_<NAMA>_CLIENT_ID, _CLIENT_SECRET, _AUTH_URL,
+// _TOKEN_URL, _USERINFO_URL, _REDIRECT_URL, _SCOPES, _DISPLAY_NAME dan
+// _TRUST_EMAIL. Provider "google" dan "microsoft" sudah punya nilai bawaan
+// sehingga cukup diisi client ID dan secret.
+func loadIdentityProviders() []IdentityProviderConfig {
+	appURL := getEnv("APP_URL", "http://localhost:8000")
+
+	names := getEnvAsList("IDP_PROVIDERS")
+	if _, exists := os.LookupEnv("IDP_PROVIDERS"); !exists && getEnv("GOOGLE_CLIENT_ID", "") != "" {
+		names = []string{"google"}
+	}
+
+	var providers []IdentityProviderConfig
+	for _, name := range names {
+		name = strings.ToLower(name)
+		prefix := "IDP_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+
+		p := IdentityProviderConfig{
+			Name:        name,
+			DisplayName: name,
+			RedirectURL: appURL + "/api/v1/auth/idp/" + name + "/callback",
+			Scopes:      []string{"openid", "email", "profile"},
+		}
+		switch name {
+		case "google":
+			p.DisplayName = "Google POLIJE"
+			p.ClientID = getEnv("GOOGLE_CLIENT_ID", "")
+			p.ClientSecret = getEnv("GOOGLE_CLIENT_SECRET", "")
+			p.AuthURL = "https://accounts.google.com/o/oauth2/v2/auth"
+			p.TokenURL = "https://oauth2.googleapis.com/token"
+			p.UserInfoURL = "https://openidconnect.googleapis.com/v1/userinfo"
+			// Redirect URI lama yang sudah terdaftar di Google Console
+			p.RedirectURL = appURL + "/api/v1/auth/google/callback"
+			p.TrustEmail = true
+		case "microsoft":
+			tenant := getEnv(prefix+"TENANT", "organizations")
+			p.DisplayName = "Microsoft 365"
+			p.AuthURL = "https://login.microsoftonline.com/" + tenant + "/oauth2/v2.0/authorize"
+			p.TokenURL = "https://login.microsoftonline.com/" + tenant + "/oauth2/v2.0/token"
+			p.UserInfoURL = "https://graph.microsoft.com/oidc/userinfo"
+		}
+
+		p.DisplayName = getEnv(prefix+"DISPLAY_NAME", p.DisplayName)
+		p.ClientID = getEnv(prefix+"CLIENT_ID", p.ClientID)
+		p.ClientSecret = getEnv(prefix+"CLIENT_SECRET", p.ClientSecret)
+		p.AuthURL = getEnv(prefix+"AUTH_URL", p.AuthURL)
+		p.TokenURL = getEnv(prefix+"TOKEN_URL", p.TokenURL)
+		p.UserInfoURL = getEnv(prefix+"USERINFO_URL", p.UserInfoURL)
+		p.RedirectURL = getEnv(prefix+"REDIRECT_URL", p.RedirectURL)
+		if scopes := getEnvAsList(prefix + "SCOPES"); len(scopes) > 0 {
+			p.Scopes = scopes
+		}
+		if value, exists := os.LookupEnv(prefix + "TRUST_EMAIL"); exists {
+			p.TrustEmail = value == "1" || value == "true"
+		}
+
+		if p.ClientID == "" || p.AuthURL == "" || p.TokenURL == "" || p.UserInfoURL == "" {
+			log.Printf("Identity provider %q is incomplete and has been disabled", name)
+			continue
+		}
+		providers = append(providers, p)
+	}
+	return providers
+}
+
+// defaultRateLimitPolicies adalah policy bawaan. Setiap policy bisa diubah lewat
+// env RATE_LIMIT_<NAMA> dengan format "limit/window[/algorithm]", mis.
+// RATE_LIMIT_LOGIN_IP=20/1m atau RATE_LIMIT_OAUTH_CLIENT=300/1m/token_bucket.
+var defaultRateLimitPolicies = map[string]RateLimitPolicyConfig{
+	"login-ip":        {Scope: "ip", Algorithm: "sliding_window", Limit: 10, Window: time.Minute},
+	"login-account":   {Scope: "account", Algorithm: "sliding_window", Limit: 10, Window: 5 * time.Minute},
+	"two-factor-ip":   {Scope: "ip", Algorithm: "sliding_window", Limit: 10, Window: time.Minute},
+	"passkey-ip":      {Scope: "ip", Algorithm: "token_bucket", Limit: 20, Window: time.Minute},
+	"password-ip":     {Scope: "ip", Algorithm: "sliding_window", Limit: 5, Window: 15 * time.Minute},
+	"verification-ip": {Scope: "ip", Algorithm: "sliding_window", Limit: 5, Window: 15 * time.Minute},
+	"oauth-client":    {Scope: "client", Algorithm: "token_bucket", Limit: 300, Window: time.Minute},
+	"oauth-token-ip":  {Scope: "ip", Algorithm: "token_bucket", Limit: 60, Window: time.Minute},
+}
+
+func loadRateLimitPolicies() map[string]RateLimitPolicyConfig {
+	policies := make(map[string]RateLimitPolicyConfig, len(defaultRateLimitPolicies))
+	for name, policy := range defaultRateLimitPolicies {
+		key := "RATE_LIMIT_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+		if value, exists := os.LookupEnv(key); exists {
+			override, err := parseRateLimitPolicy(value, policy)
+			if err != nil {
+				log.Printf("Invalid %s %q, using default: %v", key, value, err)
+			} else {
+				policy = override
+			}
+		}
+		policies[name] = policy
+	}
+	return policies
+}
+
+func parseRateLimitPolicy(value string, policy RateLimitPolicyConfig) (RateLimitPolicyConfig, error) {
+	parts := strings.Split(value, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return policy, strconv.ErrSyntax
+	}
+
+	limit, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || limit <= 0 {
+		return policy, strconv.ErrSyntax
+	}
+	window, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+	if err != nil || window <= 0 {
+		return policy, strconv.ErrSyntax
+	}
+
+	policy.Limit = limit
+	policy.Window = window
+	if len(parts) == 3 {
+		switch algorithm := strings.TrimSpace(parts[2]); algorithm {
+		case "sliding_window", "token_bucket":
+			policy.Algorithm = algorithm
+		default:
+			return policy, strconv.ErrSyntax
+		}
+	}
+	return policy, nil
+}
+
 func getEnv(key, fallback string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
@@ -113,3 +406,13 @@ func getEnvAsInt(key string, fallback int) int {
 	}
 	return fallback
 }
+
+func getEnvAsList(key string) []string {
+	var list []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,60 @@
+package delivery
+
+import (
+	"fmt"
+	"jti-super-app-go/config"
+	"jti-super-app-go/internal/repository"
+	"jti-super-app-go/internal/service"
+	"jti-super-app-go/internal/usecase"
+	"log"
+)
+
+// RunCommand menjalankan perintah maintenance dari CLI, misalnya:
+//
+//	./main keys:rotate
+//	./main permissions:sync [role]
+func RunCommand(args []string) {
+	config.LoadConfig()
+	config.ConnectDatabase()
+
+	switch args[0] {
+	case "keys:rotate":
+		keyService := service.NewSigningKeyService(repository.NewSigningKeyRepository(config.DB))
+		key, err := keyService.Rotate()
+		if err != nil {
+			log.Fatalf("Failed to rotate signing key: %v", err)
+		}
+		fmt.Printf("New active signing key: %s (%s)\n", key.ID, key.Algorithm)
+	case "permissions:sync":
+		syncPermissions(args[1:])
+	default:
+		log.Fatalf("Unknown command %q", args[0])
+	}
+}
+
+// syncPermissions membuat permission yang dipakai routePolicies. Bila nama role
+// diberikan, semua permission tersebut juga diberikan ke role itu sehingga
+// admin tidak terkunci setelah policy baru dipasang.
+func syncPermissions(args []string) {
+	names := routePolicies.Permissions()
+	created, err := repository.NewPermissionRepository(config.DB).CreateMissing(names)
+	if err != nil {
+		log.Fatalf("Failed to sync permissions: %v", err)
+	}
+	for _, permission := range created {
+		fmt.Printf("Created permission %s\n", permission.Name)
+	}
+	fmt.Printf("%d route permission(s), %d created\n", len(names), len(created))
+
+	if len(args) == 0 {
+		return
+	}
+
+	config.ConnectRedis()
+	authorizationUC := usecase.NewAuthorizationUseCase(repository.NewPermissionCacheRepository(config.Rdb), repository.NewUserRepository(config.DB), repository.NewScopedPermissionRepository(config.DB))
+	roleUC := usecase.NewRoleUseCase(repository.NewRoleRepository(config.DB), authorizationUC)
+	if err := roleUC.GrantPermissions(args[0], names); err != nil {
+		log.Fatalf("Failed to grant permissions to role %q: %v", args[0], err)
+	}
+	fmt.Printf("Granted %d permission(s) to role %s\n", len(names), args[0])
+}
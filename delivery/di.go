@@ -11,28 +11,39 @@ import (
 )
 
 type Container struct {
-	AuthHandler           *handler.AuthHandler
-	EmployeeHandler       *handler.EmployeeHandler
-	LabHandler            *handler.LabHandler
-	MajorHandler          *handler.MajorHandler
-	SemesterHandler       *handler.SemesterHandler
-	SessionHandler        *handler.SessionHandler
-	StudentHandler        *handler.StudentHandler
-	StudyProgramHandler   *handler.StudyProgramHandler
-	SubjectHandler        *handler.SubjectHandler
-	GoogleAuthService     service.GoogleAuthService
-	OauthClientHandler    *handler.OauthClientHandler
-	OauthHandler          *handler.OauthHandler
-	PermissionHandler     *handler.PermissionHandler
-	RoleHandler           *handler.RoleHandler
-	SubjectLectureHandler *handler.SubjectLectureHandler
-	UserHandler           *handler.UserHandler
+	AuthHandler                *handler.AuthHandler
+	EmployeeHandler            *handler.EmployeeHandler
+	ExternalIdentityHandler    *handler.ExternalIdentityHandler
+	ImpersonationHandler       *handler.ImpersonationHandler
+	LabHandler                 *handler.LabHandler
+	MajorHandler               *handler.MajorHandler
+	SemesterHandler            *handler.SemesterHandler
+	SessionHandler             *handler.SessionHandler
+	StudentHandler             *handler.StudentHandler
+	StudyProgramHandler        *handler.StudyProgramHandler
+	SubjectHandler             *handler.SubjectHandler
+	OauthClientHandler         *handler.OauthClientHandler
+	OauthHandler               *handler.OauthHandler
+	PasskeyHandler             *handler.PasskeyHandler
+	PermissionHandler          *handler.PermissionHandler
+	PersonalAccessTokenHandler *handler.PersonalAccessTokenHandler
+	RoleHandler                *handler.RoleHandler
+	ScopedPermissionHandler    *handler.ScopedPermissionHandler
+	SubjectLectureHandler      *handler.SubjectLectureHandler
+	TwoFactorHandler           *handler.TwoFactorHandler
+	UserHandler                *handler.UserHandler
+
+	// AuthorizationUseCase dipakai AuthMiddleware untuk permission live
+	AuthorizationUseCase usecase.AuthorizationUseCase
+	// TokenUseCase dipakai AuthMiddleware untuk menolak token dari sesi yang dicabut
+	TokenUseCase usecase.TokenUseCase
+	// PersonalAccessTokenUseCase dipakai AuthMiddleware untuk token script
+	PersonalAccessTokenUseCase usecase.PersonalAccessTokenUseCase
 }
 
-func InitContainer(db *gorm.DB, jwtService service.JWTService) *Container {
+func InitContainer(db *gorm.DB, jwtService service.JWTService, oidcService service.OIDCService) *Container {
 	emailService := service.NewEmailService(config.AppConfig.Email)
 	employeeRepo := repository.NewEmployeeRepository(db)
-	googleAuthService := service.NewGoogleAuthService(config.AppConfig)
 	studentRepo := repository.NewStudentRepository(db)
 	subjectLectureRepo := repository.NewSubjectLectureRepository(db)
 
@@ -40,14 +51,54 @@ func InitContainer(db *gorm.DB, jwtService service.JWTService) *Container {
 	userRepo := repository.NewUserRepository(db)
 	passwordResetRepo := repository.NewPasswordResetRepository(db)
 
-	authUC := usecase.NewAuthUseCase(authRepo, userRepo, employeeRepo, studentRepo, passwordResetRepo, jwtService, emailService)
-	authHandler := handler.NewAuthHandler(authUC, googleAuthService)
+	twoFactorRepo := repository.NewTwoFactorRepository(db)
+	twoFactorChallengeRepo := repository.NewTwoFactorChallengeRepository(config.Rdb)
+	twoFactorUC := usecase.NewTwoFactorUseCase(twoFactorRepo, twoFactorChallengeRepo, userRepo)
+	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorUC)
+
+	webAuthnService := service.NewWebAuthnService(config.AppConfig.WebAuthn)
+	webAuthnCredentialRepo := repository.NewWebAuthnCredentialRepository(db)
+	webAuthnSessionRepo := repository.NewWebAuthnSessionRepository(config.Rdb)
+	webAuthnUC := usecase.NewWebAuthnUseCase(webAuthnCredentialRepo, webAuthnSessionRepo, userRepo, authRepo, twoFactorUC, webAuthnService)
+	passkeyHandler := handler.NewPasskeyHandler(webAuthnUC)
+
+	refreshTokenRepo := repository.NewRefreshTokenRepository(config.Rdb)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(config.Rdb)
+	permissionCacheRepo := repository.NewPermissionCacheRepository(config.Rdb)
+	scopedPermissionRepo := repository.NewScopedPermissionRepository(db)
+	authorizationUC := usecase.NewAuthorizationUseCase(permissionCacheRepo, userRepo, scopedPermissionRepo)
+	personalAccessTokenRepo := repository.NewPersonalAccessTokenRepository(db)
+	personalAccessTokenUC := usecase.NewPersonalAccessTokenUseCase(personalAccessTokenRepo, authorizationUC)
+	personalAccessTokenHandler := handler.NewPersonalAccessTokenHandler(personalAccessTokenUC)
+	tokenUC := usecase.NewTokenUseCase(refreshTokenRepo, userRepo, twoFactorRepo, webAuthnCredentialRepo, jwtService, authorizationUC)
+
+	passwordPolicyService := service.NewPasswordPolicyService(config.AppConfig.PasswordPolicy)
+	passwordHistoryRepo := repository.NewPasswordHistoryRepository(db)
+	passwordUC := usecase.NewPasswordUseCase(userRepo, studentRepo, employeeRepo, passwordHistoryRepo, passwordPolicyService)
+
+	emailVerificationRepo := repository.NewEmailVerificationRepository(config.Rdb)
+	emailVerificationUC := usecase.NewEmailVerificationUseCase(emailVerificationRepo, authRepo, userRepo, studentRepo, emailService)
+
+	externalIdentityRepo := repository.NewExternalIdentityRepository(db)
+	externalAuthStateRepo := repository.NewExternalAuthStateRepository(config.Rdb)
+	identityProviders := service.NewIdentityProviderRegistry(config.AppConfig.IdentityProviders)
+	externalIdentityUC := usecase.NewExternalIdentityUseCase(externalIdentityRepo, externalAuthStateRepo, userRepo, authRepo, identityProviders)
+
+	// Backend dicoba berurutan; password lokal selalu menjadi fallback terakhir
+	var authBackends []usecase.AuthBackend
+	if config.AppConfig.LDAP.URL != "" {
+		ldapService := service.NewLDAPService(config.AppConfig.LDAP)
+		authBackends = append(authBackends, usecase.NewLDAPAuthBackend(ldapService, authRepo, userRepo, config.AppConfig.LDAP.Provision))
+	}
+	authBackends = append(authBackends, usecase.NewLocalAuthBackend(authRepo))
+
+	authUC := usecase.NewAuthUseCase(authRepo, userRepo, employeeRepo, studentRepo, passwordResetRepo, jwtService, emailService, tokenUC, twoFactorUC, webAuthnUC, loginAttemptRepo, passwordUC, emailVerificationUC, authorizationUC, authBackends)
 
-	employeeUC := usecase.NewEmployeeUseCase(db, employeeRepo, userRepo)
+	employeeUC := usecase.NewEmployeeUseCase(db, employeeRepo, userRepo, passwordUC)
 	employeeHandler := handler.NewEmployeeHandler(employeeUC)
 
 	labRepo := repository.NewLabRepository(db)
-	labUC := usecase.NewLabUseCase(labRepo)
+	labUC := usecase.NewLabUseCase(labRepo, authorizationUC)
 	labHandler := handler.NewLabHandler(labUC)
 
 	majorRepo := repository.NewMajorRepository(db)
@@ -55,11 +106,13 @@ func InitContainer(db *gorm.DB, jwtService service.JWTService) *Container {
 	majorHandler := handler.NewMajorHandler(majorUC)
 
 	permissionRepo := repository.NewPermissionRepository(db)
-	permissionUC := usecase.NewPermissionUseCase(permissionRepo)
+	permissionUC := usecase.NewPermissionUseCase(permissionRepo, authorizationUC)
 	permissionHandler := handler.NewPermissionHandler(permissionUC)
+	scopedPermissionUC := usecase.NewScopedPermissionUseCase(scopedPermissionRepo, userRepo, permissionRepo, authorizationUC)
+	scopedPermissionHandler := handler.NewScopedPermissionHandler(scopedPermissionUC)
 
 	roleRepo := repository.NewRoleRepository(db)
-	roleUC := usecase.NewRoleUseCase(roleRepo)
+	roleUC := usecase.NewRoleUseCase(roleRepo, authorizationUC)
 	roleHandler := handler.NewRoleHandler(roleUC)
 
 	semesterRepo := repository.NewSemesterRepository(db)
@@ -71,11 +124,11 @@ func InitContainer(db *gorm.DB, jwtService service.JWTService) *Container {
 	sessionHandler := handler.NewSessionHandler(sessionUC)
 
 	studentSemesterRepo := repository.NewStudentSemesterRepository(db)
-	studentUC := usecase.NewStudentUseCase(db, studentRepo, userRepo, studentSemesterRepo)
-	studentHandler := handler.NewStudentHandler(studentUC)
+	studentUC := usecase.NewStudentUseCase(db, studentRepo, userRepo, studentSemesterRepo, passwordUC)
+	studentHandler := handler.NewStudentHandler(studentUC, emailVerificationUC)
 
 	studyProgramRepo := repository.NewStudyProgramRepository(db)
-	studyProgramUC := usecase.NewStudyProgramUseCase(studyProgramRepo)
+	studyProgramUC := usecase.NewStudyProgramUseCase(studyProgramRepo, authorizationUC)
 	studyProgramHandler := handler.NewStudyProgramHandler(studyProgramUC)
 
 	subjectSemesterRepo := repository.NewSubjectSemesterRepository(db)
@@ -90,27 +143,47 @@ func InitContainer(db *gorm.DB, jwtService service.JWTService) *Container {
 	oauthClientUC := usecase.NewOauthClientUseCase(oauthClientRepo)
 	oauthClientHandler := handler.NewOauthClientHandler(oauthClientUC)
 
-	oauthUsecase := usecase.NewOauthUsecase()
-	oauthHandler := handler.NewOauthHandler(oauthClientUC, oauthUsecase, authUC)
-
-	userUC := usecase.NewUserUseCase(userRepo)
-	userHandler := handler.NewUserHandler(userUC)
+	loginTxRepo := repository.NewLoginTransactionRepository(config.Rdb)
+	loginTxUC := usecase.NewLoginTransactionUseCase(loginTxRepo)
+	ssoSessionRepo := repository.NewSSOSessionRepository(config.Rdb)
+	oauthConsentRepo := repository.NewOauthConsentRepository(db)
+	oauthUsecase := usecase.NewOauthUsecase(userRepo, oauthClientRepo, ssoSessionRepo, oauthConsentRepo, studentRepo, employeeRepo, tokenUC, jwtService, oidcService)
+	userSessionRepo := repository.NewUserSessionRepository(config.Rdb)
+	userSessionUC := usecase.NewUserSessionUseCase(userSessionRepo, tokenUC, oauthUsecase)
+	oauthHandler := handler.NewOauthHandler(oauthClientUC, oauthUsecase, authUC, tokenUC, loginTxUC, userSessionUC, webAuthnUC, externalIdentityUC, oidcService)
+	impersonationLogRepo := repository.NewImpersonationLogRepository(db)
+	impersonationUC := usecase.NewImpersonationUseCase(impersonationLogRepo, userRepo, tokenUC, authorizationUC)
+	impersonationHandler := handler.NewImpersonationHandler(impersonationUC)
+	authHandler := handler.NewAuthHandler(authUC, userSessionUC, webAuthnUC, emailVerificationUC, impersonationUC)
+	externalIdentityHandler := handler.NewExternalIdentityHandler(externalIdentityUC, authUC, userSessionUC, loginTxUC)
+
+	userUC := usecase.NewUserUseCase(userRepo, authorizationUC)
+	userHandler := handler.NewUserHandler(userUC, userSessionUC)
 
 	return &Container{
-		AuthHandler:           authHandler,
-		EmployeeHandler:       employeeHandler,
-		LabHandler:            labHandler,
-		MajorHandler:          majorHandler,
-		SemesterHandler:       semesterHandler,
-		SessionHandler:        sessionHandler,
-		StudentHandler:        studentHandler,
-		StudyProgramHandler:   studyProgramHandler,
-		SubjectHandler:        subjectHandler,
-		OauthClientHandler:    oauthClientHandler,
-		OauthHandler:          oauthHandler,
-		PermissionHandler:     permissionHandler,
-		RoleHandler:           roleHandler,
-		UserHandler:           userHandler,
-		SubjectLectureHandler: subjectLectureHandler,
+		AuthHandler:                authHandler,
+		EmployeeHandler:            employeeHandler,
+		ExternalIdentityHandler:    externalIdentityHandler,
+		ImpersonationHandler:       impersonationHandler,
+		LabHandler:                 labHandler,
+		MajorHandler:               majorHandler,
+		SemesterHandler:            semesterHandler,
+		SessionHandler:             sessionHandler,
+		StudentHandler:             studentHandler,
+		StudyProgramHandler:        studyProgramHandler,
+		SubjectHandler:             subjectHandler,
+		OauthClientHandler:         oauthClientHandler,
+		OauthHandler:               oauthHandler,
+		PasskeyHandler:             passkeyHandler,
+		PermissionHandler:          permissionHandler,
+		PersonalAccessTokenHandler: personalAccessTokenHandler,
+		RoleHandler:                roleHandler,
+		ScopedPermissionHandler:    scopedPermissionHandler,
+		TwoFactorHandler:           twoFactorHandler,
+		UserHandler:                userHandler,
+		SubjectLectureHandler:      subjectLectureHandler,
+		AuthorizationUseCase:       authorizationUC,
+		TokenUseCase:               tokenUC,
+		PersonalAccessTokenUseCase: personalAccessTokenUC,
 	}
 }
@@ -2,110 +2,190 @@ package middleware
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"jti-super-app-go/config"
+	"jti-super-app-go/internal/domain"
 	"jti-super-app-go/internal/dto"
 	"jti-super-app-go/internal/service"
+	"jti-super-app-go/pkg/constants"
 	"jti-super-app-go/pkg/helper"
+	"log"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware(jwtService service.JWTService) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			helper.ErrorResponse(c, http.StatusUnauthorized, "Missing Authorization header", errors.New("unauthorized"))
-			c.Abort()
-			return
-		}
+// PermissionResolver menyelesaikan role dan permission user saat request masuk.
+type PermissionResolver interface {
+	Resolve(userID string) (*domain.PermissionSet, error)
+}
 
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			helper.ErrorResponse(c, http.StatusUnauthorized, "Invalid Authorization header format", errors.New("unauthorized"))
-			c.Abort()
-			return
-		}
+// SessionChecker memeriksa sesi (family token) di balik access token: sesi yang
+// sudah dicabut menolak semua access token-nya sebelum kedaluwarsa, dan sesi
+// first-party membedakan login langsung dari token milik OAuth client.
+type SessionChecker interface {
+	IsSessionRevoked(familyID string) (bool, error)
+	IsFirstPartySession(familyID string) bool
+}
+
+// PersonalAccessTokenAuthenticator memvalidasi personal access token milik script.
+type PersonalAccessTokenAuthenticator interface {
+	Authenticate(token string) (*domain.PersonalAccessToken, *domain.PermissionSet, error)
+}
+
+// authenticate memvalidasi bearer token dan menyimpan identitasnya di context.
+// Token user yang membawa versi permission (pv) mendapat role dan permission
+// terkini dari resolver; token client_credentials tetap memakai permission yang
+// tertanam di token. Mengembalikan false setelah menulis response error.
+func authenticate(c *gin.Context, jwtService service.JWTService, resolver PermissionResolver, sessions SessionChecker, tokens PersonalAccessTokenAuthenticator, policy Policy) bool {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		helper.ErrorResponse(c, http.StatusUnauthorized, "Missing Authorization header", errors.New("unauthorized"))
+		return false
+	}
+
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		helper.ErrorResponse(c, http.StatusUnauthorized, "Invalid Authorization header format", errors.New("unauthorized"))
+		return false
+	}
+
+	tokenString := parts[1]
+	if strings.HasPrefix(tokenString, constants.PersonalAccessTokenPrefix) {
+		return authenticatePersonalAccessToken(c, tokens, tokenString, policy)
+	}
+
+	val, err := config.Rdb.Get(context.Background(), tokenString).Result()
+	if err == nil && val == "blacklisted" {
+		helper.ErrorResponse(c, http.StatusUnauthorized, "Token has been invalidated", errors.New("unauthorized"))
+		return false
+	}
+
+	claims, err := jwtService.ValidateToken(tokenString)
+	if err != nil {
+		helper.ErrorResponse(c, http.StatusUnauthorized, "Invalid or expired token", err)
+		return false
+	}
+
+	if len(claims.Audience) > 0 && !policy.clients {
+		helper.ErrorResponse(c, http.StatusForbidden, "Tokens issued to OAuth clients cannot be used for this endpoint", errors.New("client audience"))
+		return false
+	}
+	if policy.firstParty && !isFirstPartyToken(claims, sessions) {
+		helper.ErrorResponse(c, http.StatusForbidden, "This endpoint requires a token from a direct login session", errors.New("not a first-party session"))
+		return false
+	}
 
-		tokenString := parts[1]
-		val, err := config.Rdb.Get(context.Background(), tokenString).Result()
-		if err == nil && val == "blacklisted" {
+	if claims.SessionID != "" {
+		// Sama seperti blacklist, Redis yang tidak tersedia tidak memblokir request
+		revoked, err := sessions.IsSessionRevoked(claims.SessionID)
+		if err != nil {
+			log.Printf("failed to check session revocation for %s: %v", claims.SessionID, err)
+		}
+		if revoked {
 			helper.ErrorResponse(c, http.StatusUnauthorized, "Token has been invalidated", errors.New("unauthorized"))
-			c.Abort()
-			return
+			return false
 		}
+	}
 
-		claims, err := jwtService.ValidateToken(tokenString)
-		if err != nil {
-			helper.ErrorResponse(c, http.StatusUnauthorized, "Invalid or expired token", err)
-			c.Abort()
-			return
+	for _, r := range claims.Restrictions {
+		if !slices.Contains(policy.restrictions, r) {
+			helper.ErrorResponse(c, http.StatusForbidden, "Token is restricted until required account actions are completed", errors.New("restricted token"))
+			return false
 		}
+	}
 
+	// Token client_credentials tidak mewakili user mana pun
+	if claims.UserID != "" {
 		c.Set("user_id", claims.UserID)
-		c.Set("roles", claims.Roles)
-		c.Set("permissions", claims.Permissions)
-		c.Set("token", tokenString)
-		c.Next()
 	}
+	if claims.ClientID != "" {
+		c.Set("client_id", claims.ClientID)
+	}
+	c.Set("session_id", claims.SessionID)
+	roles, permissions := claims.Roles, claims.Permissions
+	if claims.UserID != "" {
+		roles, permissions = resolvePermissions(c, resolver, claims)
+	}
+	c.Set("roles", roles)
+	c.Set("permissions", permissions)
+	c.Set("scope", claims.Scope)
+	c.Set("restrictions", claims.Restrictions)
+	c.Set("token", tokenString)
+	if claims.Actor != nil {
+		c.Set("impersonator_id", claims.Actor.Subject)
+	}
+	return true
 }
 
-// auth middleware for web routes, checks session cookie instead of Bearer token
-func AuthMiddlewareWeb(jwtService service.JWTService) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		userId, ok := helper.GetSSO(c) // baca cookie sso_session
-		if !ok {
-			helper.ClearSSO(c)
-			config.Rdb.Set(c, "return_to", c.Request.URL.RequestURI(), 0)
-			c.Redirect(http.StatusSeeOther, "/login")
-			c.Abort()
-			return
-		}
+// isFirstPartyToken hanya menerima token user dari sesi login langsung ke
+// aplikasi ini; token client_credentials dan token milik OAuth client ditolak.
+func isFirstPartyToken(claims *service.JWTClaims, sessions SessionChecker) bool {
+	if claims.UserID == "" || claims.ClientID != "" || len(claims.Audience) > 0 || claims.SessionID == "" {
+		return false
+	}
+	return sessions.IsFirstPartySession(claims.SessionID)
+}
 
-		dataCookie, err := config.Rdb.Get(c, "sso:"+userId).Result()
-		if err != nil {
-			helper.ClearSSO(c)
-			config.Rdb.Set(c, "return_to", c.Request.URL.RequestURI(), 0)
-			c.Redirect(http.StatusSeeOther, "/login")
-			c.Abort()
-			return
-		}
+// authenticatePersonalAccessToken hanya menerima personal access token pada
+// route yang mewajibkan permission sehingga token script tidak bisa dipakai
+// untuk endpoint akun seperti ganti password atau membuat token baru.
+func authenticatePersonalAccessToken(c *gin.Context, tokens PersonalAccessTokenAuthenticator, tokenString string, policy Policy) bool {
+	if policy.requirement == "" || policy.firstParty {
+		helper.ErrorResponse(c, http.StatusForbidden, "Personal access tokens cannot be used for this endpoint", errors.New("personal access token"))
+		return false
+	}
 
-		var user dto.LoginResponseDTO
+	token, set, err := tokens.Authenticate(tokenString)
+	if err != nil {
+		helper.ErrorResponse(c, http.StatusUnauthorized, "Invalid or expired token", err)
+		return false
+	}
 
-		err = json.Unmarshal([]byte(dataCookie), &user)
-		if err != nil {
-			helper.ClearSSO(c)
-			helper.RedirectBackToLogin(c, "/login", c.Request.URL.RequestURI(), "",
-				"Failed to parse user data")
-			return
-		}
+	c.Set("user_id", token.UserID)
+	c.Set("personal_access_token_id", token.ID)
+	c.Set("roles", set.Roles)
+	c.Set("permissions", set.Permissions)
+	if len(set.Scopes) > 0 {
+		c.Set("permission_scopes", set.Scopes)
+	}
+	return true
+}
 
-		val, err := config.Rdb.Get(context.Background(), user.Token).Result()
-		if err == nil && val == "blacklisted" {
-			helper.ClearSSO(c)
-			helper.RedirectBackToLogin(c, "/login", c.Request.URL.RequestURI(), "",
-				"Session has been invalidated, please log in again")
-			c.Abort()
-			return
-		}
+// resolvePermissions gagal tertutup: token tanpa pv atau user yang tidak bisa
+// dimuat tidak mendapat role dan permission apa pun. Token lama yang masih
+// membawa role ikut diselesaikan live agar role yang dicabut langsung berlaku.
+func resolvePermissions(c *gin.Context, resolver PermissionResolver, claims *service.JWTClaims) ([]string, []string) {
+	if claims.PermissionVersion == nil && len(claims.Roles) == 0 {
+		return []string{}, []string{}
+	}
 
-		_, err = jwtService.ValidateToken(user.Token)
-		if err != nil {
-			helper.RedirectBackToLogin(c, "/login", c.Request.URL.RequestURI(), "",
-				"Session expired, please log in again")
+	set, err := resolver.Resolve(claims.UserID)
+	if err != nil {
+		log.Printf("failed to resolve permissions for user %s: %v", claims.UserID, err)
+		return []string{}, []string{}
+	}
+
+	c.Header("X-Permission-Version", strconv.FormatInt(set.Version, 10))
+	if len(set.Scopes) > 0 {
+		c.Set("permission_scopes", set.Scopes)
+	}
+	return set.Roles, set.Permissions
+}
+
+// DenyImpersonation menolak token impersonation pada tindakan sensitif seperti
+// ganti password atau mengelola 2FA. Hanya bermakna pada route yang policy-nya
+// mewajibkan autentikasi.
+func DenyImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("impersonator_id") != "" {
+			helper.ErrorResponse(c, http.StatusForbidden, "This action is not allowed while impersonating a user", errors.New("impersonation token"))
 			c.Abort()
 			return
 		}
-
-		c.Set("user_id", user.User.ID)
-		c.Set("email", user.User.Email)
-		c.Set("roles", user.User.Roles)
-		c.Set("permissions", user.User.Permissions)
-
 		c.Next()
 	}
 }
@@ -119,54 +199,48 @@ func sliceContains(slice []string, item string) bool {
 	return false
 }
 
-// Authorize checks if the user has the required roles or permissions.
+// authorize memeriksa role atau permission user terhadap requirement.
 // `requirements` is a pipe-separated string like "role:admin|super-admin" or "permission:edit-major"
-func Authorize(requirements string) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		userRoles, _ := c.Get("roles")
-		userPermissions, _ := c.Get("permissions")
+//
+// Permission ber-scope juga memenuhi requirement; gabungan scope-nya disimpan
+// sebagai "access_scope" agar handler dan repository membatasi data.
+func authorize(c *gin.Context, requirements string) bool {
+	roles, okRoles := c.Get("roles")
+	permissions, okPermissions := c.Get("permissions")
+	if !okRoles || !okPermissions {
+		helper.ErrorResponse(c, http.StatusForbidden, "You are not authorized to perform this action", errors.New("unauthorized"))
+		return false
+	}
 
-		roles, okRoles := userRoles.([]string)
-		if !okRoles {
-			helper.ErrorResponse(c, http.StatusForbidden, "You are not authorized to perform this action", errors.New("unauthorized"))
-			c.Abort()
-			return
+	value, _ := c.Get("permission_scopes")
+	permissionScopes, _ := value.(map[string]dto.AccessScope)
+	var scoped *dto.AccessScope
+	for _, item := range strings.Split(requirements, "|") {
+		reqType, reqValue, ok := strings.Cut(item, ":")
+		if !ok {
+			continue
 		}
 
-		permissions, okPermissions := userPermissions.([]string)
-		if !okPermissions {
-			helper.ErrorResponse(c, http.StatusForbidden, "You are not authorized to perform this action", errors.New("unauthorized"))
-			c.Abort()
-			return
+		if reqType == "role" && sliceContains(roles.([]string), reqValue) {
+			return true
 		}
-
-		requiredItems := strings.Split(requirements, "|")
-
-		isAuthorized := false
-		for _, item := range requiredItems {
-			parts := strings.SplitN(item, ":", 2)
-			if len(parts) != 2 {
-				continue
-			}
-			reqType := parts[0]
-			reqValue := parts[1]
-
-			if reqType == "role" && sliceContains(roles, reqValue) {
-				isAuthorized = true
-				break
-			}
-			if reqType == "permission" && sliceContains(permissions, reqValue) {
-				isAuthorized = true
-				break
-			}
+		if reqType == "permission" && sliceContains(permissions.([]string), reqValue) {
+			return true
 		}
-
-		if !isAuthorized {
-			helper.ErrorResponse(c, http.StatusForbidden, "You do not have the required permissions", errors.New("forbidden"))
-			c.Abort()
-			return
+		// Hanya permission yang handler-nya membatasi data boleh dipenuhi grant ber-scope
+		if scope, ok := permissionScopes[reqValue]; ok && reqType == "permission" && slices.Contains(constants.ScopablePermissions, reqValue) {
+			if scoped == nil {
+				scoped = &dto.AccessScope{}
+			}
+			scoped.Merge(scope)
 		}
+	}
 
-		c.Next()
+	if scoped != nil {
+		c.Set("access_scope", scoped)
+		return true
 	}
+
+	helper.ErrorResponse(c, http.StatusForbidden, "You do not have the required permissions", errors.New("forbidden"))
+	return false
 }
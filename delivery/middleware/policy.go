@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"jti-super-app-go/internal/service"
+	"jti-super-app-go/pkg/helper"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Policy menentukan siapa yang boleh mengakses sebuah route.
+type Policy struct {
+	public       bool
+	requirement  string
+	restrictions []string
+	clients      bool
+	firstParty   bool
+}
+
+// Public mengizinkan route diakses tanpa token, mis. login atau endpoint OAuth
+// yang mengautentikasi client di dalam handler.
+func Public() Policy {
+	return Policy{public: true}
+}
+
+// Authenticated cukup mewajibkan token yang valid. Token dengan restriction
+// hanya diterima bila restriction-nya disebutkan di sini.
+func Authenticated(allowRestricted ...string) Policy {
+	return Policy{restrictions: allowRestricted}
+}
+
+// AllowClients ikut menerima token user yang diterbitkan untuk OAuth client
+// (claim aud), mis. endpoint userinfo. Tanpa ini hanya token first-party yang
+// diterima.
+func (p Policy) AllowClients() Policy {
+	p.clients = true
+	return p
+}
+
+// FirstPartyOnly membatasi route ke token sesi login langsung, mis. endpoint
+// yang menerbitkan kredensial baru. Personal access token, token
+// client_credentials dan token milik OAuth client ditolak.
+func (p Policy) FirstPartyOnly() Policy {
+	p.firstParty = true
+	return p
+}
+
+// Require mewajibkan role atau permission dengan format "role:admin|permission:edit-major".
+func Require(requirement string) Policy {
+	return Policy{requirement: requirement}
+}
+
+// Permission adalah singkatan Require("permission:" + name).
+func Permission(name string) Policy {
+	return Require("permission:" + name)
+}
+
+// PolicyRegistry memetakan "METHOD /full/path" route gin ke policy-nya.
+type PolicyRegistry map[string]Policy
+
+func policyKey(method, path string) string {
+	return method + " " + path
+}
+
+// Check memastikan setiap route terdaftar punya policy dan setiap policy
+// menunjuk route yang ada, sehingga route baru tidak terbuka tanpa sengaja.
+func (r PolicyRegistry) Check(routes gin.RoutesInfo) error {
+	registered := make(map[string]struct{}, len(routes))
+	var problems []string
+	for _, route := range routes {
+		key := policyKey(route.Method, route.Path)
+		registered[key] = struct{}{}
+		if _, ok := r[key]; !ok {
+			problems = append(problems, "missing policy for "+key)
+		}
+	}
+	for key := range r {
+		if _, ok := registered[key]; !ok {
+			problems = append(problems, "policy for unknown route "+key)
+		}
+	}
+
+	if len(problems) > 0 {
+		slices.Sort(problems)
+		return fmt.Errorf("route policy check failed:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// Permissions mengembalikan semua nama permission yang dipakai registry.
+func (r PolicyRegistry) Permissions() []string {
+	var names []string
+	for _, policy := range r {
+		for _, item := range strings.Split(policy.requirement, "|") {
+			if reqType, name, ok := strings.Cut(item, ":"); ok && reqType == "permission" && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Enforce menerapkan policy registry pada setiap request. Harus dipasang dengan
+// router.Use sebelum route didaftarkan. Route tanpa policy selalu ditolak.
+func Enforce(registry PolicyRegistry, jwtService service.JWTService, resolver PermissionResolver, sessions SessionChecker, tokens PersonalAccessTokenAuthenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Request yang tidak cocok dengan route mana pun dijawab 404 oleh gin
+		if c.FullPath() == "" {
+			c.Next()
+			return
+		}
+
+		policy, ok := registry[policyKey(c.Request.Method, c.FullPath())]
+		if !ok {
+			helper.ErrorResponse(c, http.StatusForbidden, "You are not authorized to perform this action", errors.New("route has no policy"))
+			c.Abort()
+			return
+		}
+		if policy.public {
+			c.Next()
+			return
+		}
+
+		if !authenticate(c, jwtService, resolver, sessions, tokens, policy) {
+			c.Abort()
+			return
+		}
+		if policy.requirement != "" && !authorize(c, policy.requirement) {
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
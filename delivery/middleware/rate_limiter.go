@@ -1,54 +1,157 @@
 package middleware
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"jti-super-app-go/config"
 	"jti-super-app-go/pkg/helper"
+	"log"
+	"math"
 	"net/http"
+	"net/netip"
+	"net/url"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
 )
 
-var (
-	rateLimit       = 10          // requests
-	rateLimitWindow = time.Minute // per minute
+const (
+	RateLimitScopeIP      = "ip"
+	RateLimitScopeAccount = "account"
+	RateLimitScopeClient  = "client"
+
+	RateLimitSlidingWindow = "sliding_window"
+	RateLimitTokenBucket   = "token_bucket"
 )
 
-func RateLimiter() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Terapkan hanya untuk endpoint login POST
-		if c.Request.Method != http.MethodPost || c.FullPath() != "/api/v1/auth/login" {
-			c.Next()
-			return
-		}
+// rateLimitBodyLimit membatasi body yang dibaca untuk mencari identitas akun.
+const rateLimitBodyLimit = 64 << 10
 
-		ip := c.ClientIP()
+// slidingWindowScript mencatat timestamp setiap request dalam sorted set dan
+// menolak request bila jumlahnya di dalam window sudah mencapai limit.
+// Mengembalikan {allowed, remaining, reset_ms, retry_ms}.
+var slidingWindowScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', KEYS[1], window)
+local reset = window
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+local retry = 0
+if allowed == 0 then
+	retry = reset
+end
+return {allowed, limit - count, reset, retry}
+`)
 
-		// Window dalam detik sebagai int64 (hindari campur float64)
-		windowSec := int64(rateLimitWindow / time.Second) // mis. 60
-		nowSec := time.Now().Unix()
-		slot := nowSec / windowSec
+// tokenBucketScript mengisi ulang bucket secara linear sebanyak limit token per
+// window sehingga burst dibatasi kapasitas bucket.
+// Mengembalikan {allowed, remaining, reset_ms, retry_ms}.
+var tokenBucketScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local capacity = tonumber(ARGV[3])
+local rate = capacity / window
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1]) or capacity
+local ts = tonumber(bucket[2]) or now
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) / rate)
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], window)
+return {allowed, math.floor(tokens), math.ceil((capacity - tokens) / rate), retry}
+`)
 
-		key := fmt.Sprintf("rl:login:%s:%d", ip, slot)
+type rateLimitPolicy struct {
+	name string
+	config.RateLimitPolicyConfig
+}
 
+type rateLimitResult struct {
+	policy     rateLimitPolicy
+	allowed    bool
+	remaining  int
+	reset      time.Duration
+	retryAfter time.Duration
+}
+
+var (
+	allowlistOnce sync.Once
+	allowlist     []netip.Prefix
+)
+
+// RateLimit membatasi request dengan satu atau lebih policy bernama dari
+// config.AppConfig.RateLimit. Semua policy dievaluasi dan request ditolak bila
+// salah satunya habis. Header RateLimit-* mencerminkan policy yang paling ketat.
+// Nama policy yang tidak dikenal membuat aplikasi panic saat route didaftarkan.
+//
+// Bila Redis tidak tersedia request tetap diteruskan agar login tidak ikut mati.
+func RateLimit(names ...string) gin.HandlerFunc {
+	policies := make([]rateLimitPolicy, 0, len(names))
+	for _, name := range names {
+		cfg, ok := config.AppConfig.RateLimit.Policies[name]
+		if !ok {
+			panic(fmt.Sprintf("rate limit policy %q is not configured", name))
+		}
+		policies = append(policies, rateLimitPolicy{name: name, RateLimitPolicyConfig: cfg})
+	}
+
+	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(c.Request.Context(), time.Second)
 		defer cancel()
 
-		n, _ := config.Rdb.Incr(ctx, key).Result()
-		// TTL sedikit > window untuk safety
-		_ = config.Rdb.Expire(ctx, key, rateLimitWindow+2*time.Second).Err()
+		var tightest *rateLimitResult
+		for _, policy := range policies {
+			identity := rateLimitIdentity(c, policy.Scope)
+			if identity == "" {
+				continue
+			}
+
+			result, err := evaluateRateLimit(ctx, policy, identity)
+			if err != nil {
+				log.Printf("rate limit %s: %v", policy.name, err)
+				continue
+			}
 
-		if int(n) > rateLimit {
-			// Hitung sisa detik hingga window berikutnya
-			elapsed := nowSec % windowSec
-			reset := int(windowSec - elapsed)
-			if reset <= 0 {
-				reset = int(windowSec)
+			if tightest == nil || result.stricterThan(tightest) {
+				tightest = result
 			}
-			c.Header("Retry-After", strconv.Itoa(reset))
+		}
+
+		if tightest == nil {
+			c.Next()
+			return
+		}
+
+		setRateLimitHeaders(c, tightest)
+		if !tightest.allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(tightest.retryAfter)))
 			helper.ErrorResponse(c, http.StatusTooManyRequests, "Rate limit exceeded", fmt.Errorf("too many requests"))
 			c.Abort()
 			return
@@ -57,3 +160,136 @@ func RateLimiter() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+func evaluateRateLimit(ctx context.Context, policy rateLimitPolicy, identity string) (*rateLimitResult, error) {
+	key := fmt.Sprintf("rl:%s:%s", policy.name, identity)
+	now := time.Now().UnixMilli()
+	window := policy.Window.Milliseconds()
+
+	var values []int64
+	var err error
+	switch policy.Algorithm {
+	case RateLimitTokenBucket:
+		values, err = tokenBucketScript.Run(ctx, config.Rdb, []string{key}, now, window, policy.Limit).Int64Slice()
+	default:
+		values, err = slidingWindowScript.Run(ctx, config.Rdb, []string{key}, now, window, policy.Limit, uuid.NewString()).Int64Slice()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 4 {
+		return nil, fmt.Errorf("unexpected script result %v", values)
+	}
+
+	return &rateLimitResult{
+		policy:     policy,
+		allowed:    values[0] == 1,
+		remaining:  int(max(values[1], 0)),
+		reset:      time.Duration(values[2]) * time.Millisecond,
+		retryAfter: time.Duration(values[3]) * time.Millisecond,
+	}, nil
+}
+
+// stricterThan mengutamakan policy yang menolak request, lalu yang paling lama
+// menahan request, lalu yang sisa kuotanya paling sedikit.
+func (r *rateLimitResult) stricterThan(other *rateLimitResult) bool {
+	if r.allowed != other.allowed {
+		return !r.allowed
+	}
+	if !r.allowed {
+		return r.retryAfter > other.retryAfter
+	}
+	return r.remaining < other.remaining
+}
+
+func setRateLimitHeaders(c *gin.Context, result *rateLimitResult) {
+	c.Header("RateLimit-Limit", strconv.Itoa(result.policy.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(result.remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.reset)))
+	c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", result.policy.Limit, ceilSeconds(result.policy.Window)))
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// rateLimitIdentity mengembalikan kunci pembatas untuk scope policy. String
+// kosong berarti policy tidak berlaku untuk request ini.
+func rateLimitIdentity(c *gin.Context, scope string) string {
+	switch scope {
+	case RateLimitScopeIP:
+		ip := c.ClientIP()
+		if isAllowlisted(ip) {
+			return ""
+		}
+		return ip
+	case RateLimitScopeAccount:
+		return strings.ToLower(strings.TrimSpace(requestField(c, "email")))
+	case RateLimitScopeClient:
+		if id, _, ok := c.Request.BasicAuth(); ok {
+			return id
+		}
+		return requestField(c, "client_id")
+	}
+	return ""
+}
+
+func isAllowlisted(ip string) bool {
+	allowlistOnce.Do(func() {
+		for _, entry := range config.AppConfig.RateLimit.Allowlist {
+			if prefix, err := netip.ParsePrefix(entry); err == nil {
+				allowlist = append(allowlist, prefix.Masked())
+				continue
+			}
+			if addr, err := netip.ParseAddr(entry); err == nil {
+				allowlist = append(allowlist, netip.PrefixFrom(addr, addr.BitLen()))
+				continue
+			}
+			log.Printf("rate limit: ignoring invalid allowlist entry %q", entry)
+		}
+	})
+
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range allowlist {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// requestField membaca field dari query, body JSON, atau body form tanpa
+// mengonsumsi body sehingga handler tetap bisa melakukan binding.
+func requestField(c *gin.Context, field string) string {
+	if value := c.Query(field); value != "" {
+		return value
+	}
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return ""
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, rateLimitBodyLimit))
+	if err != nil {
+		return ""
+	}
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+
+	switch c.ContentType() {
+	case gin.MIMEJSON:
+		var payload map[string]any
+		if json.Unmarshal(body, &payload) == nil {
+			if value, ok := payload[field].(string); ok {
+				return value
+			}
+		}
+	case gin.MIMEPOSTForm:
+		if values, err := url.ParseQuery(string(body)); err == nil {
+			return values.Get(field)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,156 @@
+package delivery
+
+import (
+	"jti-super-app-go/delivery/middleware"
+	"jti-super-app-go/pkg/constants"
+)
+
+// Restriction yang tetap boleh mengakses endpoint akun dasar agar user bisa
+// menyelesaikan kewajibannya atau logout.
+var accountRestrictions = []string{constants.RestrictionTwoFactorEnrollment, constants.RestrictionPasswordChange}
+
+// routePolicies adalah satu-satunya tempat aturan akses route. Server gagal
+// start bila ada route tanpa policy; permission yang dipakai di sini dibuat
+// dengan `./main permissions:sync`.
+var routePolicies = middleware.PolicyRegistry{
+	// Auth
+	"GET /api/v1/auth/email/verify/:token":      middleware.Public(),
+	"POST /api/v1/auth/email/resend":            middleware.Public(),
+	"GET /api/v1/auth/google/login":             middleware.Public(),
+	"GET /api/v1/auth/google/callback":          middleware.Public(),
+	"GET /api/v1/auth/idp":                      middleware.Public(),
+	"GET /api/v1/auth/idp/:provider/login":      middleware.Public(),
+	"GET /api/v1/auth/idp/:provider/callback":   middleware.Public(),
+	"POST /api/v1/auth/login":                   middleware.Public(),
+	"POST /api/v1/auth/login/2fa":               middleware.Public(),
+	"POST /api/v1/auth/login/2fa/passkey/begin": middleware.Public(),
+	"POST /api/v1/auth/login/2fa/passkey":       middleware.Public(),
+	"POST /api/v1/auth/passkey/login/begin":     middleware.Public(),
+	"POST /api/v1/auth/passkey/login":           middleware.Public(),
+	"POST /api/v1/auth/refresh":                 middleware.Public(),
+	"POST /api/v1/auth/logout":                  middleware.Authenticated(accountRestrictions...),
+	"GET /api/v1/auth/me":                       middleware.Authenticated(accountRestrictions...),
+	"GET /api/v1/auth/sessions":                 middleware.Authenticated(),
+	"DELETE /api/v1/auth/sessions/:id":          middleware.Authenticated(),
+	"POST /api/v1/auth/password/forgot":         middleware.Public(),
+	"POST /api/v1/auth/password/reset":          middleware.Public(),
+	"POST /api/v1/auth/password/change":         middleware.Authenticated(accountRestrictions...),
+	"POST /api/v1/auth/impersonation/stop":      middleware.Authenticated(),
+	"GET /api/v1/auth/2fa":                      middleware.Authenticated(constants.RestrictionTwoFactorEnrollment),
+	"POST /api/v1/auth/2fa/setup":               middleware.Authenticated(constants.RestrictionTwoFactorEnrollment),
+	"POST /api/v1/auth/2fa/confirm":             middleware.Authenticated(constants.RestrictionTwoFactorEnrollment),
+	"POST /api/v1/auth/2fa/disable":             middleware.Authenticated(constants.RestrictionTwoFactorEnrollment),
+	"POST /api/v1/auth/2fa/recovery-codes":      middleware.Authenticated(constants.RestrictionTwoFactorEnrollment),
+	"GET /api/v1/auth/identities":               middleware.Authenticated(),
+	"POST /api/v1/auth/identities/link/begin":   middleware.Authenticated().FirstPartyOnly(),
+	"POST /api/v1/auth/identities/link":         middleware.Authenticated().FirstPartyOnly(),
+	"DELETE /api/v1/auth/identities/:id":        middleware.Authenticated(),
+	"GET /api/v1/auth/passkeys":                 middleware.Authenticated(constants.RestrictionTwoFactorEnrollment),
+	"POST /api/v1/auth/passkeys/register/begin": middleware.Authenticated(constants.RestrictionTwoFactorEnrollment),
+	"POST /api/v1/auth/passkeys/register":       middleware.Authenticated(constants.RestrictionTwoFactorEnrollment),
+	"DELETE /api/v1/auth/passkeys/:id":          middleware.Authenticated(),
+	"GET /api/v1/auth/tokens":                   middleware.Authenticated().FirstPartyOnly(),
+	"POST /api/v1/auth/tokens":                  middleware.Authenticated().FirstPartyOnly(),
+	"DELETE /api/v1/auth/tokens/:id":            middleware.Authenticated().FirstPartyOnly(),
+
+	// Master data; endpoint options dipakai form sehingga cukup terautentikasi
+	"GET /api/v1/employees":                       middleware.Permission("view-employees"),
+	"GET /api/v1/employees/options":               middleware.Authenticated(),
+	"GET /api/v1/employees/:id":                   middleware.Permission("view-employees"),
+	"POST /api/v1/employees":                      middleware.Permission("create-employees"),
+	"POST /api/v1/employees/:id/update":           middleware.Permission("update-employees"),
+	"DELETE /api/v1/employees/:id":                middleware.Permission("delete-employees"),
+	"GET /api/v1/labs":                            middleware.Permission("view-labs"),
+	"GET /api/v1/labs/:id":                        middleware.Permission("view-labs"),
+	"GET /api/v1/labs/options":                    middleware.Authenticated(),
+	"POST /api/v1/labs":                           middleware.Permission("create-labs"),
+	"PUT /api/v1/labs/:id":                        middleware.Permission("update-labs"),
+	"DELETE /api/v1/labs/:id":                     middleware.Permission("delete-labs"),
+	"GET /api/v1/majors":                          middleware.Permission("view-majors"),
+	"GET /api/v1/majors/:id":                      middleware.Permission("view-majors"),
+	"GET /api/v1/majors/options":                  middleware.Authenticated(),
+	"POST /api/v1/majors":                         middleware.Permission("create-majors"),
+	"PUT /api/v1/majors/:id":                      middleware.Permission("update-majors"),
+	"DELETE /api/v1/majors/:id":                   middleware.Permission("delete-majors"),
+	"GET /api/v1/semesters":                       middleware.Permission("view-semesters"),
+	"GET /api/v1/semesters/options":               middleware.Authenticated(),
+	"POST /api/v1/semesters":                      middleware.Permission("create-semesters"),
+	"PUT /api/v1/semesters/:id":                   middleware.Permission("update-semesters"),
+	"DELETE /api/v1/semesters/:id":                middleware.Permission("delete-semesters"),
+	"POST /api/v1/semesters/:id/setting-subjects": middleware.Permission("update-semesters"),
+	"GET /api/v1/sessions":                        middleware.Permission("view-sessions"),
+	"GET /api/v1/sessions/options":                middleware.Authenticated(),
+	"POST /api/v1/sessions":                       middleware.Permission("create-sessions"),
+	"PUT /api/v1/sessions/:id":                    middleware.Permission("update-sessions"),
+	"DELETE /api/v1/sessions/:id":                 middleware.Permission("delete-sessions"),
+	"GET /api/v1/students":                        middleware.Permission("view-students"),
+	"GET /api/v1/students/:id":                    middleware.Permission("view-students"),
+	"POST /api/v1/students":                       middleware.Permission("create-students"),
+	"POST /api/v1/students/verification-emails":   middleware.Permission("send-verification-emails"),
+	"GET /api/v1/study-programs":                  middleware.Permission("view-study-programs"),
+	"GET /api/v1/study-programs/:id":              middleware.Permission("view-study-programs"),
+	"GET /api/v1/study-programs/options":          middleware.Authenticated(),
+	"POST /api/v1/study-programs":                 middleware.Permission("create-study-programs"),
+	"PUT /api/v1/study-programs/:id":              middleware.Permission("update-study-programs"),
+	"DELETE /api/v1/study-programs/:id":           middleware.Permission("delete-study-programs"),
+	"GET /api/v1/subjects":                        middleware.Permission("view-subjects"),
+	"GET /api/v1/subjects/options":                middleware.Authenticated(),
+	"POST /api/v1/subjects":                       middleware.Permission("create-subjects"),
+	"PUT /api/v1/subjects/:id":                    middleware.Permission("update-subjects"),
+	"DELETE /api/v1/subjects/:id":                 middleware.Permission("delete-subjects"),
+	"GET /api/v1/subjects/lectures":               middleware.Permission("view-subjects"),
+	"POST /api/v1/subjects/lectures":              middleware.Permission("update-subjects"),
+	"GET /api/v1/subject-lectures":                middleware.Permission("view-subjects"),
+
+	// Akses dan administrasi
+	"GET /api/v1/permissions":                              middleware.Permission("view-permissions"),
+	"GET /api/v1/permissions/options":                      middleware.Permission("view-permissions"),
+	"GET /api/v1/permissions/:id":                          middleware.Permission("view-permissions"),
+	"POST /api/v1/permissions":                             middleware.Permission("create-permissions"),
+	"PUT /api/v1/permissions/:id":                          middleware.Permission("update-permissions"),
+	"DELETE /api/v1/permissions/:id":                       middleware.Permission("delete-permissions"),
+	"GET /api/v1/roles":                                    middleware.Permission("view-roles"),
+	"GET /api/v1/roles/options":                            middleware.Permission("view-roles"),
+	"GET /api/v1/roles/:id":                                middleware.Permission("view-roles"),
+	"POST /api/v1/roles":                                   middleware.Permission("create-roles"),
+	"PUT /api/v1/roles/:id":                                middleware.Permission("update-roles"),
+	"DELETE /api/v1/roles/:id":                             middleware.Permission("delete-roles"),
+	"GET /api/v1/users":                                    middleware.Permission("view-users"),
+	"PUT /api/v1/users/:id/roles":                          middleware.Permission("update-user-roles"),
+	"DELETE /api/v1/users/:id/sessions":                    middleware.Permission("manage-user-sessions"),
+	"POST /api/v1/users/:id/impersonate":                   middleware.Permission(constants.PermissionImpersonateUsers),
+	"GET /api/v1/users/:id/scoped-permissions":             middleware.Permission("view-users"),
+	"POST /api/v1/users/:id/scoped-permissions":            middleware.Permission("manage-scoped-permissions"),
+	"DELETE /api/v1/users/:id/scoped-permissions/:grantId": middleware.Permission("manage-scoped-permissions"),
+	"GET /api/v1/impersonation-logs":                       middleware.Permission("view-impersonation-logs"),
+	"GET /api/v1/oauth-clients":                            middleware.Permission("view-oauth-clients"),
+	"GET /api/v1/oauth-clients/:id":                        middleware.Permission("view-oauth-clients"),
+	"POST /api/v1/oauth-clients":                           middleware.Permission("create-oauth-clients"),
+	"PUT /api/v1/oauth-clients/:id":                        middleware.Permission("update-oauth-clients"),
+	"DELETE /api/v1/oauth-clients/:id":                     middleware.Permission("delete-oauth-clients"),
+
+	// OAuth; client diautentikasi di handler dan login memakai cookie SSO
+	"POST /api/v1/oauth/login":                   middleware.Public(),
+	"POST /api/v1/oauth/login/2fa":               middleware.Public(),
+	"POST /api/v1/oauth/login/2fa/passkey/begin": middleware.Public(),
+	"POST /api/v1/oauth/login/2fa/passkey":       middleware.Public(),
+	"POST /api/v1/oauth/passkey/begin":           middleware.Public(),
+	"POST /api/v1/oauth/passkey/login":           middleware.Public(),
+	"POST /api/v1/oauth/token":                   middleware.Public(),
+	"POST /api/v1/oauth/introspect":              middleware.Public(),
+	"POST /api/v1/oauth/revoke":                  middleware.Public(),
+	"GET /api/v1/oauth/authorize":                middleware.Public(),
+	"POST /api/v1/oauth/consent":                 middleware.Public(),
+	"GET /api/v1/oauth/userinfo":                 middleware.Authenticated().AllowClients(),
+	"POST /api/v1/oauth/userinfo":                middleware.Authenticated().AllowClients(),
+	"GET /api/v1/oauth/logout":                   middleware.Public(),
+
+	// Web
+	"GET /":                                 middleware.Public(),
+	"GET /login":                            middleware.Public(),
+	"GET /auth/callback":                    middleware.Public(),
+	"GET /.well-known/openid-configuration": middleware.Public(),
+	"GET /.well-known/jwks.json":            middleware.Public(),
+	"GET /static/*filepath":                 middleware.Public(),
+	"HEAD /static/*filepath":                middleware.Public(),
+}
@@ -8,22 +8,84 @@ import (
 )
 
 func SetupRoutes(router *gin.Engine, c *Container, jwtService service.JWTService) {
+	// Autentikasi dan otorisasi setiap route ditentukan routePolicies
+	router.Use(middleware.Enforce(routePolicies, jwtService, c.AuthorizationUseCase, c.TokenUseCase, c.PersonalAccessTokenUseCase))
+
+	// Policy rate limit per grup route, lihat config.RateLimitConfig
+	loginLimit := middleware.RateLimit("login-ip", "login-account")
+	twoFactorLimit := middleware.RateLimit("two-factor-ip")
+	passkeyLimit := middleware.RateLimit("passkey-ip")
+	passwordLimit := middleware.RateLimit("password-ip", "login-account")
+	verificationLimit := middleware.RateLimit("verification-ip", "login-account")
+	oauthTokenLimit := middleware.RateLimit("oauth-token-ip", "oauth-client")
+	oauthClientLimit := middleware.RateLimit("oauth-client")
+	// Token impersonation tidak boleh dipakai untuk mengubah kredensial user maupun hak akses
+	denyImpersonation := middleware.DenyImpersonation()
+
 	api := router.Group("/api/v1")
 	{
 		auth := api.Group("/auth")
 		{
 			auth.GET("/email/verify/:token", c.AuthHandler.VerifyEmail)
-			auth.POST("/email/resend", middleware.AuthMiddleware(jwtService), c.AuthHandler.ResendVerificationEmail)
-			auth.GET("/google/login", c.AuthHandler.GoogleLogin)
-			auth.GET("/google/callback", c.AuthHandler.GoogleCallback)
-			auth.POST("/login", middleware.RateLimiter(), c.AuthHandler.Login)
-			auth.POST("/logout", middleware.AuthMiddleware(jwtService), c.AuthHandler.Logout)
-			auth.GET("/me", middleware.AuthMiddleware(jwtService), c.AuthHandler.Me)
-			auth.POST("/password/forgot", c.AuthHandler.ForgotPassword)
-			auth.POST("/password/reset", c.AuthHandler.ResetPassword)
+			auth.POST("/email/resend", verificationLimit, c.AuthHandler.ResendVerificationEmail)
+			// Route google lama dipertahankan karena redirect URI sudah terdaftar di Google Console
+			auth.GET("/google/login", c.ExternalIdentityHandler.Login)
+			auth.GET("/google/callback", c.ExternalIdentityHandler.Callback)
+			auth.GET("/idp", c.ExternalIdentityHandler.Providers)
+			auth.GET("/idp/:provider/login", c.ExternalIdentityHandler.Login)
+			auth.GET("/idp/:provider/callback", c.ExternalIdentityHandler.Callback)
+			auth.POST("/login", loginLimit, c.AuthHandler.Login)
+			auth.POST("/login/2fa", twoFactorLimit, c.AuthHandler.VerifyTwoFactor)
+			auth.POST("/login/2fa/passkey/begin", twoFactorLimit, c.AuthHandler.BeginTwoFactorPasskey)
+			auth.POST("/login/2fa/passkey", twoFactorLimit, c.AuthHandler.VerifyTwoFactorPasskey)
+			auth.POST("/passkey/login/begin", passkeyLimit, c.AuthHandler.BeginPasskeyLogin)
+			auth.POST("/passkey/login", passkeyLimit, c.AuthHandler.FinishPasskeyLogin)
+			auth.POST("/refresh", c.AuthHandler.Refresh)
+			auth.POST("/logout", c.AuthHandler.Logout)
+			auth.GET("/me", c.AuthHandler.Me)
+			auth.GET("/sessions", c.AuthHandler.Sessions)
+			auth.DELETE("/sessions/:id", denyImpersonation, c.AuthHandler.RevokeSession)
+			auth.POST("/password/forgot", passwordLimit, c.AuthHandler.ForgotPassword)
+			auth.POST("/password/reset", passwordLimit, c.AuthHandler.ResetPassword)
+			auth.POST("/password/change", passwordLimit, denyImpersonation, c.AuthHandler.ChangePassword)
+			auth.POST("/impersonation/stop", c.ImpersonationHandler.Stop)
+
+			// Token yang dibatasi karena 2FA wajib tetap boleh melakukan enrollment
+			twoFactor := auth.Group("/2fa", denyImpersonation)
+			{
+				twoFactor.GET("", c.TwoFactorHandler.Status)
+				twoFactor.POST("/setup", c.TwoFactorHandler.Setup)
+				twoFactor.POST("/confirm", c.TwoFactorHandler.Confirm)
+				twoFactor.POST("/disable", c.TwoFactorHandler.Disable)
+				twoFactor.POST("/recovery-codes", c.TwoFactorHandler.RegenerateRecoveryCodes)
+			}
+
+			identities := auth.Group("/identities", denyImpersonation)
+			{
+				identities.GET("", c.ExternalIdentityHandler.FindAll)
+				identities.POST("/link/begin", c.ExternalIdentityHandler.BeginLink)
+				identities.POST("/link", c.ExternalIdentityHandler.CompleteLink)
+				identities.DELETE("/:id", c.ExternalIdentityHandler.Unlink)
+			}
+
+			passkeys := auth.Group("/passkeys", denyImpersonation)
+			{
+				passkeys.GET("", c.PasskeyHandler.FindAll)
+				passkeys.POST("/register/begin", c.PasskeyHandler.BeginRegistration)
+				passkeys.POST("/register", c.PasskeyHandler.FinishRegistration)
+				passkeys.DELETE("/:id", c.PasskeyHandler.Delete)
+			}
+
+			// Token impersonation tidak boleh membuat kredensial berumur panjang
+			tokens := auth.Group("/tokens", denyImpersonation)
+			{
+				tokens.GET("", c.PersonalAccessTokenHandler.FindAll)
+				tokens.POST("", c.PersonalAccessTokenHandler.Create)
+				tokens.DELETE("/:id", c.PersonalAccessTokenHandler.Delete)
+			}
 		}
 
-		employees := api.Group("/employees").Use(middleware.AuthMiddleware(jwtService))
+		employees := api.Group("/employees")
 		{
 			employees.GET("", c.EmployeeHandler.FindAll)
 			employees.GET("/options", c.EmployeeHandler.FindAllAsOptions)
@@ -33,7 +95,7 @@ func SetupRoutes(router *gin.Engine, c *Container, jwtService service.JWTService
 			employees.DELETE("/:id", c.EmployeeHandler.Delete)
 		}
 
-		labs := api.Group("/labs").Use(middleware.AuthMiddleware(jwtService))
+		labs := api.Group("/labs")
 		{
 			labs.GET("", c.LabHandler.FindAll)
 			labs.GET("/:id", c.LabHandler.FindByID)
@@ -43,7 +105,7 @@ func SetupRoutes(router *gin.Engine, c *Container, jwtService service.JWTService
 			labs.DELETE("/:id", c.LabHandler.Delete)
 		}
 
-		majors := api.Group("/majors").Use(middleware.AuthMiddleware(jwtService))
+		majors := api.Group("/majors")
 		{
 			majors.GET("", c.MajorHandler.FindAll)
 			majors.GET("/:id", c.MajorHandler.FindByID)
@@ -53,27 +115,27 @@ func SetupRoutes(router *gin.Engine, c *Container, jwtService service.JWTService
 			majors.DELETE("/:id", c.MajorHandler.Delete)
 		}
 
-		permissions := api.Group("/permissions").Use(middleware.AuthMiddleware(jwtService))
+		permissions := api.Group("/permissions")
 		{
 			permissions.GET("", c.PermissionHandler.FindAll)
 			permissions.GET("/options", c.PermissionHandler.FindAllAsOptions)
 			permissions.GET("/:id", c.PermissionHandler.FindByID)
-			permissions.POST("", c.PermissionHandler.Create)
-			permissions.PUT("/:id", c.PermissionHandler.Update)
-			permissions.DELETE("/:id", c.PermissionHandler.Delete)
+			permissions.POST("", denyImpersonation, c.PermissionHandler.Create)
+			permissions.PUT("/:id", denyImpersonation, c.PermissionHandler.Update)
+			permissions.DELETE("/:id", denyImpersonation, c.PermissionHandler.Delete)
 		}
 
-		roles := api.Group("/roles").Use(middleware.AuthMiddleware(jwtService))
+		roles := api.Group("/roles")
 		{
 			roles.GET("", c.RoleHandler.FindAll)
 			roles.GET("/options", c.RoleHandler.FindAllAsOptions)
 			roles.GET("/:id", c.RoleHandler.FindByID)
-			roles.POST("", c.RoleHandler.Create)
-			roles.PUT("/:id", c.RoleHandler.Update)
-			roles.DELETE("/:id", c.RoleHandler.Delete)
+			roles.POST("", denyImpersonation, c.RoleHandler.Create)
+			roles.PUT("/:id", denyImpersonation, c.RoleHandler.Update)
+			roles.DELETE("/:id", denyImpersonation, c.RoleHandler.Delete)
 		}
 
-		semesters := api.Group("/semesters").Use(middleware.AuthMiddleware(jwtService))
+		semesters := api.Group("/semesters")
 		{
 			semesters.GET("", c.SemesterHandler.FindAll)
 			semesters.GET("/options", c.SemesterHandler.FindAllAsOptions)
@@ -83,7 +145,7 @@ func SetupRoutes(router *gin.Engine, c *Container, jwtService service.JWTService
 			semesters.POST("/:id/setting-subjects", c.SemesterHandler.SettingSubjectSemester)
 		}
 
-		sessions := api.Group("/sessions").Use(middleware.AuthMiddleware(jwtService))
+		sessions := api.Group("/sessions")
 		{
 			sessions.GET("", c.SessionHandler.FindAll)
 			sessions.GET("/options", c.SessionHandler.FindAllAsOptions)
@@ -92,14 +154,15 @@ func SetupRoutes(router *gin.Engine, c *Container, jwtService service.JWTService
 			sessions.DELETE("/:id", c.SessionHandler.Delete)
 		}
 
-		students := api.Group("/students").Use(middleware.AuthMiddleware(jwtService))
+		students := api.Group("/students")
 		{
 			students.GET("", c.StudentHandler.FindAll)
 			students.GET("/:id", c.StudentHandler.FindByID)
 			students.POST("", c.StudentHandler.Create)
+			students.POST("/verification-emails", c.StudentHandler.SendVerificationEmails)
 		}
 
-		studyPrograms := api.Group("/study-programs").Use(middleware.AuthMiddleware(jwtService))
+		studyPrograms := api.Group("/study-programs")
 		{
 			studyPrograms.GET("", c.StudyProgramHandler.FindAll)
 			studyPrograms.GET("/:id", c.StudyProgramHandler.FindByID)
@@ -109,7 +172,7 @@ func SetupRoutes(router *gin.Engine, c *Container, jwtService service.JWTService
 			studyPrograms.DELETE("/:id", c.StudyProgramHandler.Delete)
 		}
 
-		subjects := api.Group("/subjects").Use(middleware.AuthMiddleware(jwtService))
+		subjects := api.Group("/subjects")
 		{
 			subjects.GET("", c.SubjectHandler.FindAll)
 			subjects.GET("/options", c.SubjectHandler.FindAllAsOptions)
@@ -120,32 +183,52 @@ func SetupRoutes(router *gin.Engine, c *Container, jwtService service.JWTService
 			subjects.POST("/lectures", c.SubjectHandler.StoreLectureOnSubject)
 		}
 
-		subjectLectures := api.Group("/subject-lectures").Use(middleware.AuthMiddleware(jwtService))
+		subjectLectures := api.Group("/subject-lectures")
 		{
 			subjectLectures.GET("", c.SubjectLectureHandler.FindAll)
 		}
 
+		impersonationLogs := api.Group("/impersonation-logs")
+		{
+			impersonationLogs.GET("", c.ImpersonationHandler.FindAll)
+		}
+
 		oauthClients := api.Group("/oauth-clients")
 		{
 			oauthClients.GET("", c.OauthClientHandler.FindAll)
 			oauthClients.GET("/:id", c.OauthClientHandler.FindByID)
-			oauthClients.POST("", c.OauthClientHandler.Create)
-			oauthClients.PUT("/:id", c.OauthClientHandler.Update)
-			oauthClients.DELETE("/:id", c.OauthClientHandler.Delete)
+			oauthClients.POST("", denyImpersonation, c.OauthClientHandler.Create)
+			oauthClients.PUT("/:id", denyImpersonation, c.OauthClientHandler.Update)
+			oauthClients.DELETE("/:id", denyImpersonation, c.OauthClientHandler.Delete)
 		}
 
 		oauth := api.Group("/oauth")
 		{
-			oauth.POST("/login", middleware.RateLimiter(), c.OauthHandler.LoginPost)
-			oauth.POST("/token", c.OauthHandler.Token)
-			oauth.GET("/authorize", c.OauthHandler.Authorize)
+			oauth.POST("/login", loginLimit, middleware.CSRFTokenMiddleware(), c.OauthHandler.LoginPost)
+			oauth.POST("/login/2fa", twoFactorLimit, middleware.CSRFTokenMiddleware(), c.OauthHandler.TwoFactorPost)
+			oauth.POST("/login/2fa/passkey/begin", twoFactorLimit, c.OauthHandler.TwoFactorPasskeyBegin)
+			oauth.POST("/login/2fa/passkey", twoFactorLimit, c.OauthHandler.TwoFactorPasskey)
+			oauth.POST("/passkey/begin", passkeyLimit, c.OauthHandler.PasskeyBegin)
+			oauth.POST("/passkey/login", passkeyLimit, c.OauthHandler.PasskeyLogin)
+			oauth.POST("/token", oauthTokenLimit, c.OauthHandler.Token)
+			oauth.POST("/introspect", oauthClientLimit, c.OauthHandler.Introspect)
+			oauth.POST("/revoke", oauthClientLimit, c.OauthHandler.Revoke)
+			oauth.GET("/authorize", middleware.CSRFTokenMiddleware(), c.OauthHandler.Authorize)
+			oauth.POST("/consent", c.OauthHandler.ConsentPost)
+			oauth.GET("/userinfo", c.OauthHandler.UserInfo)
+			oauth.POST("/userinfo", c.OauthHandler.UserInfo)
 			oauth.GET("/logout", c.OauthHandler.Logout)
 		}
 
-		users := api.Group("/users").Use(middleware.AuthMiddleware(jwtService))
+		users := api.Group("/users")
 		{
 			users.GET("", c.UserHandler.FindAll)
-			users.PUT("/:id/roles", c.UserHandler.UpdateRoles)
+			users.PUT("/:id/roles", denyImpersonation, c.UserHandler.UpdateRoles)
+			users.DELETE("/:id/sessions", denyImpersonation, c.UserHandler.RevokeSessions)
+			users.POST("/:id/impersonate", denyImpersonation, c.ImpersonationHandler.Start)
+			users.GET("/:id/scoped-permissions", c.ScopedPermissionHandler.FindAll)
+			users.POST("/:id/scoped-permissions", denyImpersonation, c.ScopedPermissionHandler.Create)
+			users.DELETE("/:id/scoped-permissions/:grantId", denyImpersonation, c.ScopedPermissionHandler.Delete)
 			// users.GET("/:id", c.UserHandler.FindByID)
 			// users.POST("", c.UserHandler.Create)
 			// users.PUT("/:id", c.UserHandler.Update)
@@ -157,6 +240,8 @@ func SetupRoutes(router *gin.Engine, c *Container, jwtService service.JWTService
 	{
 		web.GET("", c.OauthHandler.IndexPage)
 		web.GET("/login", middleware.CSRFTokenMiddleware(), c.OauthHandler.LoginPage)
-		web.GET("/auth/callback", c.OauthHandler.LoginCallback)
+		web.GET("/auth/callback", middleware.CSRFTokenMiddleware(), c.OauthHandler.LoginCallback)
+		web.GET("/.well-known/openid-configuration", c.OauthHandler.OpenIDConfiguration)
+		web.GET("/.well-known/jwks.json", c.OauthHandler.JWKS)
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"jti-super-app-go/config"
 	"jti-super-app-go/delivery/middleware"
+	"jti-super-app-go/internal/repository"
 	"jti-super-app-go/internal/service"
 	"log"
 
@@ -25,16 +26,26 @@ func Server() *AppServer {
 	db := config.DB
 
 	router := gin.Default()
+	// Tanpa proxy tepercaya c.ClientIP() memakai alamat koneksi, bukan
+	// X-Forwarded-For yang bisa dipalsukan client untuk mengakali rate limit
+	if err := router.SetTrustedProxies(config.AppConfig.TrustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 	router.Use(sentrygin.New(sentrygin.Options{
 		Repanic: true,
 	}))
 	router.Static("/static", "./static")
 	router.LoadHTMLGlob("templates/**/*")
-	jwtService := service.NewJWTService()
+	signingKeyService := service.NewSigningKeyService(repository.NewSigningKeyRepository(db))
+	jwtService := service.NewJWTService(signingKeyService)
+	oidcService := service.NewOIDCService(signingKeyService)
 
-	container := InitContainer(db, jwtService)
+	container := InitContainer(db, jwtService, oidcService)
 	middleware.CORS(router)
 	SetupRoutes(router, container, jwtService)
+	if err := routePolicies.Check(router.Routes()); err != nil {
+		log.Fatal(err)
+	}
 
 	return &AppServer{router: router}
 }
@@ -31,12 +31,13 @@ type User struct {
 }
 
 type Role struct {
-	ID          string `gorm:"type:char(36);primaryKey;column:uuid"`
-	Name        string `gorm:"type:varchar(255);unique;not null"`
-	GuardName   string `gorm:"type:varchar(255);default:'api'"`
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Permissions []Permission `gorm:"many2many:role_has_permissions;foreignKey:ID;joinForeignKey:role_id;References:ID;joinReferences:permission_id"`
+	ID                string `gorm:"type:char(36);primaryKey;column:uuid"`
+	Name              string `gorm:"type:varchar(255);unique;not null"`
+	GuardName         string `gorm:"type:varchar(255);default:'api'"`
+	RequiresTwoFactor bool   `gorm:"type:tinyint(1);default:0;not null"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	Permissions       []Permission `gorm:"many2many:role_has_permissions;foreignKey:ID;joinForeignKey:role_id;References:ID;joinReferences:permission_id"`
 }
 
 type Permission struct {
@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// EmailVerificationRepository menyimpan token verifikasi email dalam bentuk
+// hash. Setiap user hanya punya satu token aktif; token baru menggantikan
+// token sebelumnya.
+type EmailVerificationRepository interface {
+	Create(userID, tokenHash string, ttl time.Duration) error
+	// Consume mengembalikan pemilik token dan menghapus token sehingga hanya bisa dipakai sekali.
+	Consume(tokenHash string) (string, error)
+	// Throttle mencatat satu pengiriman dan mengembalikan sisa waktu tunggu
+	// jika user masih dalam cooldown atau sudah mencapai batas harian.
+	Throttle(userID string, cooldown time.Duration, dailyLimit int) (time.Duration, error)
+}
@@ -0,0 +1,68 @@
+package domain
+
+import "time"
+
+// ExternalIdentity menautkan subject (`sub`) dari IdP upstream ke seorang user.
+// Email hanya disimpan sebagai informasi; pencocokan login selalu memakai
+// pasangan provider dan subject.
+type ExternalIdentity struct {
+	ID          string     `gorm:"type:char(36);primaryKey"`
+	UserID      string     `gorm:"column:m_user_id;type:char(36);not null"`
+	Provider    string     `gorm:"type:varchar(50);not null"`
+	Subject     string     `gorm:"type:varchar(255);not null"`
+	Email       *string    `gorm:"type:varchar(255)"`
+	LastLoginAt *time.Time `gorm:"type:timestamp"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (ExternalIdentity) TableName() string {
+	return "external_identities"
+}
+
+type ExternalIdentityRepository interface {
+	FindByProviderSubject(provider, subject string) (*ExternalIdentity, error)
+	FindByUserID(userID string) ([]ExternalIdentity, error)
+	Create(identity *ExternalIdentity) error
+	Touch(id string, email *string, at time.Time) error
+	Delete(userID, id string) error
+}
+
+const (
+	ExternalAuthPurposeLogin = "login"
+	ExternalAuthPurposeLink  = "link"
+)
+
+// ExternalAuthState menyimpan state satu redirect ke IdP upstream sampai
+// callback diterima. BrowserID mengikat state ke browser yang memulai alur.
+type ExternalAuthState struct {
+	State        string    `json:"state"`
+	Provider     string    `json:"provider"`
+	Purpose      string    `json:"purpose"`
+	UserID       string    `json:"user_id,omitempty"`
+	BrowserID    string    `json:"browser_id,omitempty"`
+	CodeVerifier string    `json:"code_verifier"`
+	ClientState  string    `json:"client_state,omitempty"`
+	ReturnHost   string    `json:"return_host,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// ExternalLinkTicket adalah hasil callback penautan yang belum dikonfirmasi.
+// Ticket hanya bisa diselesaikan oleh user dan browser yang memulai penautan
+// sehingga identitas milik orang lain tidak bisa disisipkan ke akun korban.
+type ExternalLinkTicket struct {
+	Ticket    string `json:"ticket"`
+	UserID    string `json:"user_id"`
+	BrowserID string `json:"browser_id"`
+	Provider  string `json:"provider"`
+	Subject   string `json:"subject"`
+	Email     string `json:"email,omitempty"`
+}
+
+type ExternalAuthStateRepository interface {
+	Create(state *ExternalAuthState, ttl time.Duration) error
+	// Consume mengambil sekaligus menghapus state sehingga hanya bisa dipakai sekali.
+	Consume(state string) (*ExternalAuthState, error)
+	CreateTicket(ticket *ExternalLinkTicket, ttl time.Duration) error
+	ConsumeTicket(ticket string) (*ExternalLinkTicket, error)
+}
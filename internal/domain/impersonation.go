@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"jti-super-app-go/internal/dto"
+	"time"
+)
+
+const (
+	ImpersonationEndStopped = "stopped"
+	ImpersonationEndLogout  = "logout"
+	ImpersonationEndExpired = "expired"
+)
+
+// ImpersonationLog adalah jejak audit satu sesi impersonation. Baris dibuat
+// saat admin mulai bertindak sebagai user dan EndedAt diisi saat sesi
+// dihentikan. Sesi yang dibiarkan habis tetap tercatat lewat ExpiresAt.
+type ImpersonationLog struct {
+	ID             string     `gorm:"type:char(36);primaryKey"`
+	ImpersonatorID string     `gorm:"type:char(36);not null"`
+	UserID         string     `gorm:"column:m_user_id;type:char(36);not null"`
+	Reason         string     `gorm:"type:varchar(500);not null"`
+	IPAddress      *string    `gorm:"type:varchar(45)"`
+	UserAgent      *string    `gorm:"type:varchar(500)"`
+	StartedAt      time.Time  `gorm:"type:timestamp;not null"`
+	ExpiresAt      time.Time  `gorm:"type:timestamp;not null"`
+	EndedAt        *time.Time `gorm:"type:timestamp"`
+	EndReason      *string    `gorm:"type:varchar(20)"`
+	Impersonator   User       `gorm:"foreignKey:ImpersonatorID"`
+	User           User       `gorm:"foreignKey:UserID"`
+}
+
+func (ImpersonationLog) TableName() string {
+	return "impersonation_logs"
+}
+
+type ImpersonationLogRepository interface {
+	FindAll(params dto.QueryParams, userID string) (*[]ImpersonationLog, int64, error)
+	FindByID(id string) (*ImpersonationLog, error)
+	Create(log *ImpersonationLog) error
+	// End mengisi EndedAt hanya jika sesi belum pernah diakhiri.
+	End(id, reason string, at time.Time) error
+}
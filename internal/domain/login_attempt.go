@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// LoginAttemptRepository mencatat password salah per akun untuk penguncian
+// bertahap. Akun diidentifikasi dengan email yang sudah dinormalisasi, termasuk
+// email yang tidak terdaftar agar penguncian tidak membocorkan keberadaan akun.
+type LoginAttemptRepository interface {
+	// LockedFor mengembalikan sisa waktu kunci, atau 0 jika akun tidak terkunci.
+	LockedFor(account string) (time.Duration, error)
+	// RecordFailure menambah hitungan kegagalan dan mengembalikan totalnya.
+	// Hitungan kedaluwarsa setelah resetAfter tanpa kegagalan baru.
+	RecordFailure(account string, resetAfter time.Duration) (int, error)
+	Lock(account string, duration time.Duration) error
+	Reset(account string) error
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"jti-super-app-go/internal/dto"
+	"time"
+)
+
+// LoginTransaction menyimpan konteks satu alur login web (tujuan kembali dan
+// parameter authorize) sampai login selesai. Kuncinya adalah state acak dan
+// hanya bisa dipakai oleh browser yang memulainya.
+type LoginTransaction struct {
+	State               string    `json:"state"`
+	BrowserID           string    `json:"browser_id"`
+	ReturnTo            string    `json:"return_to"`
+	ClientID            string    `json:"client_id,omitempty"`
+	RedirectURI         string    `json:"redirect_uri,omitempty"`
+	Scope               string    `json:"scope,omitempty"`
+	Nonce               string    `json:"nonce,omitempty"`
+	CodeChallenge       string    `json:"code_challenge,omitempty"`
+	CodeChallengeMethod string    `json:"code_challenge_method,omitempty"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+// LoginResult menyimpan hasil login IdP eksternal sampai /auth/callback di
+// browser yang sama menukarnya dengan sesi SSO, sehingga token tidak pernah
+// dikirim lewat URL. Kuncinya adalah code acak sekali pakai.
+type LoginResult struct {
+	Code      string               `json:"code"`
+	BrowserID string               `json:"browser_id"`
+	SessionID string               `json:"session_id"`
+	Login     dto.LoginResponseDTO `json:"login"`
+}
+
+type LoginTransactionRepository interface {
+	Create(tx *LoginTransaction, ttl time.Duration) error
+	// Consume mengambil sekaligus menghapus transaksi sehingga hanya bisa dipakai sekali.
+	Consume(state string) (*LoginTransaction, error)
+	CreateResult(result *LoginResult, ttl time.Duration) error
+	// ConsumeResult mengambil sekaligus menghapus hasil login sehingga code hanya bisa dipakai sekali.
+	ConsumeResult(code string) (*LoginResult, error)
+}
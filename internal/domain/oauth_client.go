@@ -2,19 +2,51 @@ package domain
 
 import (
 	"jti-super-app-go/internal/dto"
+	"slices"
 	"time"
 
 	"gorm.io/gorm"
 )
 
 type OauthClient struct {
-	ID        string `gorm:"type:char(36);primaryKey"`
-	Name      string `gorm:"type:varchar(100);not null"`
-	Secret    string `gorm:"type:varchar(100);not null"`
-	Redirect  string `gorm:"type:text;not null"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	DeletedAt gorm.DeletedAt `gorm:"index"`
+	ID           string   `gorm:"type:char(36);primaryKey"`
+	Name         string   `gorm:"type:varchar(100);not null"`
+	Secret       string   `gorm:"type:varchar(100);not null"`
+	RedirectURIs []string `gorm:"column:redirect_uris;type:json;serializer:json;not null"`
+	IsPublic     bool     `gorm:"type:tinyint(1);default:0;not null"`
+	Scopes       []string `gorm:"type:json;serializer:json"`
+	// URI yang dipanggil saat user logout dari SSO (OIDC front/back-channel logout)
+	FrontchannelLogoutURI *string `gorm:"type:varchar(255)"`
+	BackchannelLogoutURI  *string `gorm:"type:varchar(255)"`
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+	DeletedAt             gorm.DeletedAt `gorm:"index"`
+
+	// Permission milik client sendiri, dipakai pada grant client_credentials
+	Permissions []Permission `gorm:"many2many:oauth_client_has_permissions;foreignKey:ID;joinForeignKey:m_oauth_client_id;References:ID;joinReferences:permission_id"`
+}
+
+type OauthClientHasPermission struct {
+	ClientID     string `gorm:"type:char(36);column:m_oauth_client_id;primaryKey"`
+	PermissionID string `gorm:"type:char(36);column:permission_id;primaryKey"`
+}
+
+func (OauthClientHasPermission) TableName() string {
+	return "oauth_client_has_permissions"
+}
+
+// PermissionNames mengembalikan nama permission yang diberikan ke client.
+func (c *OauthClient) PermissionNames() []string {
+	names := make([]string, 0, len(c.Permissions))
+	for _, permission := range c.Permissions {
+		names = append(names, permission.Name)
+	}
+	return names
+}
+
+// HasRedirectURI mencocokkan redirect_uri secara persis (RFC 6749 section 3.1.2.3).
+func (c *OauthClient) HasRedirectURI(uri string) bool {
+	return slices.Contains(c.RedirectURIs, uri)
 }
 
 func (OauthClient) TableName() string {
@@ -26,5 +58,8 @@ type OauthClientRepository interface {
 	FindByID(id string) (*OauthClient, error)
 	Create(client *OauthClient) error
 	Update(id string, client *OauthClient) error
+	Save(id string, client *OauthClient, columns ...string) error
+	FindAllRedirectURIs() ([]string, error)
+	UpdatePermissions(id string, permissionIDs []string) error
 	Delete(id string) error
 }
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+type OauthConsent struct {
+	ID        string   `gorm:"type:char(36);primaryKey"`
+	UserID    string   `gorm:"column:m_user_id;type:char(36);not null"`
+	ClientID  string   `gorm:"column:m_oauth_client_id;type:char(36);not null"`
+	Scopes    []string `gorm:"type:json;serializer:json;not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (OauthConsent) TableName() string {
+	return "oauth_consents"
+}
+
+type OauthConsentRepository interface {
+	FindByUserAndClient(userID, clientID string) (*OauthConsent, error)
+	Save(consent *OauthConsent) error
+}
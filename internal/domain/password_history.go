@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// PasswordHistory menyimpan hash password lama agar tidak dipakai ulang.
+type PasswordHistory struct {
+	ID        string    `gorm:"type:char(36);primaryKey"`
+	UserID    string    `gorm:"type:char(36);column:m_user_id;not null"`
+	Password  string    `gorm:"type:varchar(255);not null"`
+	CreatedAt time.Time `gorm:"type:timestamp"`
+}
+
+func (PasswordHistory) TableName() string {
+	return "password_histories"
+}
+
+type PasswordHistoryRepository interface {
+	// FindRecent mengembalikan maksimal limit hash terbaru milik user.
+	FindRecent(userID string, limit int) ([]PasswordHistory, error)
+	// Push menyimpan hash baru lalu membuang riwayat di luar keep entri terbaru.
+	Push(history *PasswordHistory, keep int) error
+}
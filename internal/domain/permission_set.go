@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"jti-super-app-go/internal/dto"
+	"time"
+)
+
+// PermissionSet adalah role dan permission efektif seorang user pada Version
+// tertentu. Version naik setiap kali role user, permission role, atau role itu
+// sendiri berubah sehingga set lama di cache otomatis tidak berlaku.
+type PermissionSet struct {
+	Version     int64    `json:"version"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+	// Scopes berisi permission yang hanya berlaku di sebagian data, dikunci
+	// dengan nama permission. Permission di sini tidak ada di Permissions.
+	Scopes map[string]dto.AccessScope `json:"scopes,omitempty"`
+}
+
+type PermissionCacheRepository interface {
+	Get(userID string) (*PermissionSet, error)
+	Set(userID string, set *PermissionSet, ttl time.Duration) error
+	// Version mengembalikan versi permission user saat ini, yaitu gabungan
+	// versi milik user dan versi global.
+	Version(userID string) (int64, error)
+	// BumpUsers menaikkan versi user tertentu, mis. saat role-nya diubah.
+	BumpUsers(userIDs ...string) error
+	// BumpAll menaikkan versi global, mis. saat permission sebuah role diubah.
+	BumpAll() error
+}
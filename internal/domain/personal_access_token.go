@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+// PersonalAccessToken adalah token berumur panjang untuk script milik user.
+// Hanya hash SHA-256 token yang disimpan; Permissions adalah subset permission
+// user yang dipilih saat token dibuat.
+type PersonalAccessToken struct {
+	ID          string     `gorm:"type:char(36);primaryKey"`
+	UserID      string     `gorm:"column:m_user_id;type:char(36);not null"`
+	Name        string     `gorm:"type:varchar(255);not null"`
+	TokenHash   string     `gorm:"type:char(64);not null"`
+	Permissions []string   `gorm:"type:json;serializer:json;not null"`
+	LastUsedAt  *time.Time `gorm:"type:timestamp"`
+	ExpiresAt   *time.Time `gorm:"type:timestamp"`
+	CreatedAt   time.Time
+}
+
+func (PersonalAccessToken) TableName() string {
+	return "personal_access_tokens"
+}
+
+// Expired bernilai true bila token punya masa berlaku dan sudah lewat.
+func (t *PersonalAccessToken) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+type PersonalAccessTokenRepository interface {
+	FindByUserID(userID string) ([]PersonalAccessToken, error)
+	FindByHash(tokenHash string) (*PersonalAccessToken, error)
+	Create(token *PersonalAccessToken) error
+	UpdateLastUsed(id string, usedAt time.Time) error
+	Delete(userID, id string) error
+}
@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// RefreshToken adalah opaque refresh token yang disimpan di Redis berdasarkan hash-nya.
+// Setiap token hanya boleh dipakai sekali; token yang dipakai ulang menandakan
+// kebocoran sehingga seluruh family-nya dicabut.
+type RefreshToken struct {
+	FamilyID  string    `json:"family_id"`
+	UserID    string    `json:"user_id"`
+	ClientID  string    `json:"client_id"`
+	Scope     string    `json:"scope"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type RefreshTokenFamily struct {
+	ID              string    `json:"id"`
+	UserID          string    `json:"user_id"`
+	ClientID        string    `json:"client_id"`
+	LastAccessToken string    `json:"last_access_token"`
+	Revoked         bool      `json:"revoked"`
+	CreatedAt       time.Time `json:"created_at"`
+	ExpiresAt       time.Time `json:"expires_at"`
+}
+
+type RefreshTokenRepository interface {
+	CreateFamily(family *RefreshTokenFamily) error
+	FindFamily(id string) (*RefreshTokenFamily, error)
+	SetLastAccessToken(familyID, accessToken string) error
+	// RevokeFamily menandai family dicabut dan menyimpan penanda selama
+	// tokenTTL agar access token family ini yang masih berlaku ikut ditolak.
+	RevokeFamily(id string, tokenTTL time.Duration) error
+	IsFamilyRevoked(id string) (bool, error)
+	Store(tokenHash string, token *RefreshToken) error
+	// Find membaca token tanpa menandainya terpakai, dipakai untuk introspeksi.
+	Find(tokenHash string) (token *RefreshToken, used bool, err error)
+	// Consume menandai token sebagai terpakai. firstUse bernilai false bila token
+	// sudah pernah dipakai sebelumnya.
+	Consume(tokenHash string) (token *RefreshToken, firstUse bool, err error)
+}
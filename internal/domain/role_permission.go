@@ -9,6 +9,8 @@ type RoleRepository interface {
 	Create(role *Role) (*Role, error)
 	Update(id string, role *Role) (*Role, error)
 	Delete(id string) error
+	// AttachPermissions menambahkan permission ke role tanpa melepas yang sudah ada.
+	AttachPermissions(roleName string, permissionNames []string) error
 }
 
 type PermissionRepository interface {
@@ -18,4 +20,6 @@ type PermissionRepository interface {
 	Create(permission *Permission) (*Permission, error)
 	Update(id string, permission *Permission) (*Permission, error)
 	Delete(id string) error
+	// CreateMissing membuat permission yang namanya belum ada dan mengembalikannya.
+	CreateMissing(names []string) ([]Permission, error)
 }
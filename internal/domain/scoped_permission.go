@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// ScopedPermission memberikan permission kepada user hanya untuk data di dalam
+// satu jurusan, program studi, atau lab, mis. update-students pada program
+// studi tertentu untuk kepala program studi.
+type ScopedPermission struct {
+	ID           string `gorm:"type:char(36);primaryKey"`
+	UserID       string `gorm:"column:m_user_id;type:char(36);not null"`
+	PermissionID string `gorm:"type:char(36);not null"`
+	ScopeType    string `gorm:"type:enum('major','study_program','lab');not null"`
+	ScopeID      string `gorm:"type:char(36);not null"`
+	CreatedAt    time.Time
+	Permission   Permission `gorm:"foreignKey:PermissionID"`
+}
+
+func (ScopedPermission) TableName() string {
+	return "user_scoped_permissions"
+}
+
+type ScopedPermissionRepository interface {
+	FindByUserID(userID string) ([]ScopedPermission, error)
+	Create(grant *ScopedPermission) error
+	Delete(userID, id string) error
+	// ScopeExists memastikan jurusan, program studi, atau lab yang dituju ada.
+	ScopeExists(scopeType, scopeID string) (bool, error)
+	// ExpandMajors mengembalikan program studi dan lab milik jurusan-jurusan tersebut.
+	ExpandMajors(majorIDs []string) (studyProgramIDs, labIDs []string, err error)
+}
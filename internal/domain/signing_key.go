@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+const (
+	SigningKeyStatusActive   = "ACTIVE"
+	SigningKeyStatusRetiring = "RETIRING"
+	SigningKeyStatusRetired  = "RETIRED"
+)
+
+type SigningKey struct {
+	ID         string     `gorm:"type:varchar(64);primaryKey"` // dipakai sebagai `kid`
+	Algorithm  string     `gorm:"type:varchar(10);not null"`
+	PrivateKey string     `gorm:"type:text;not null"`
+	Status     string     `gorm:"type:enum('ACTIVE','RETIRING','RETIRED');default:'ACTIVE';not null"`
+	RetiringAt *time.Time `gorm:"type:timestamp"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (SigningKey) TableName() string {
+	return "oauth_signing_keys"
+}
+
+type SigningKeyRepository interface {
+	FindUsable() (*[]SigningKey, error)
+	Rotate(newKey *SigningKey, retireBefore time.Time) error
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// SSOSessionClient mencatat client yang pernah login lewat satu sesi SSO
+// beserta token family yang diterbitkan untuknya.
+type SSOSessionClient struct {
+	ClientID string `json:"client_id"`
+	UserID   string `json:"user_id"`
+	FamilyID string `json:"family_id"`
+}
+
+type SSOSessionRepository interface {
+	AddClient(sessionID string, client SSOSessionClient, ttl time.Duration) error
+	FindClients(sessionID string) ([]SSOSessionClient, error)
+	// Delete menghapus record SSO di server beserta daftar client-nya.
+	Delete(sessionID string) error
+}
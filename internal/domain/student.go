@@ -41,6 +41,9 @@ type StudentRepository interface {
 	FindAll(params dto.QueryParams) (*[]Student, int64, error)
 	FindByID(id string) (*Student, error)
 	FindByUserID(userID string) (*Student, error)
+	// FindUnverifiedUsers mengembalikan user aktif satu angkatan yang belum
+	// memverifikasi email. studyProgramID kosong berarti semua program studi.
+	FindUnverifiedUsers(generation int, studyProgramID string) ([]User, error)
 	Create(student *Student) (*Student, error)
 	Update(id string, student *Student) (*Student, error)
 	Delete(id string) error
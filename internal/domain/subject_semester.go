@@ -35,4 +35,6 @@ func (ss *SubjectSemester) BeforeCreate(tx *gorm.DB) (err error) {
 type SubjectSemesterRepository interface {
 	GetLectureOnSubject(studyProgramID, semesterID string) (*[]SubjectSemester, error)
 	StoreLectureOnSubject(data []dto.LectureMappingDTO) error
+	// StudyProgramIDs mengembalikan program studi dari mata kuliah pada subject semester tersebut.
+	StudyProgramIDs(subjectSemesterIDs []string) ([]string, error)
 }
@@ -0,0 +1,59 @@
+package domain
+
+import "time"
+
+// UserTwoFactor menyimpan secret TOTP milik user. Enrollment baru aktif setelah
+// ConfirmedAt terisi, yaitu saat user berhasil memasukkan kode pertamanya.
+type UserTwoFactor struct {
+	UserID        string     `gorm:"column:m_user_id;type:char(36);primaryKey"`
+	Secret        string     `gorm:"type:varchar(64);not null"`
+	RecoveryCodes []string   `gorm:"type:json;serializer:json"` // hash SHA-256 dari recovery code
+	ConfirmedAt   *time.Time `gorm:"type:timestamp"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (UserTwoFactor) TableName() string {
+	return "user_two_factors"
+}
+
+func (t *UserTwoFactor) Enabled() bool {
+	return t != nil && t.ConfirmedAt != nil
+}
+
+// RequiresTwoFactor bernilai true jika salah satu role user mewajibkan 2FA.
+func (u *User) RequiresTwoFactor() bool {
+	for _, role := range u.Roles {
+		if role.RequiresTwoFactor {
+			return true
+		}
+	}
+	return false
+}
+
+type TwoFactorRepository interface {
+	// FindByUserID mengembalikan nil tanpa error jika user belum pernah enroll.
+	FindByUserID(userID string) (*UserTwoFactor, error)
+	Save(twoFactor *UserTwoFactor) error
+	Delete(userID string) error
+	// ConsumeRecoveryCode menghapus satu recovery code secara atomik dan
+	// mengembalikan false jika kode tidak ditemukan.
+	ConsumeRecoveryCode(userID, codeHash string) (bool, error)
+}
+
+// TwoFactorChallenge adalah langkah kedua login yang menunggu kode TOTP.
+type TwoFactorChallenge struct {
+	Token    string `json:"token"`
+	UserID   string `json:"user_id"`
+	Attempts int    `json:"attempts"`
+}
+
+type TwoFactorChallengeRepository interface {
+	Create(challenge *TwoFactorChallenge, ttl time.Duration) error
+	Find(token string) (*TwoFactorChallenge, error)
+	// IncrementAttempts menambah hitungan percobaan gagal dan mengembalikan nilai barunya.
+	IncrementAttempts(token string) (int, error)
+	Delete(token string) error
+	// MarkCodeUsed mencegah kode TOTP yang sama dipakai dua kali di time step-nya.
+	MarkCodeUsed(userID string, step int64, ttl time.Duration) (bool, error)
+}
@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+const (
+	UserSessionTypeWeb = "WEB" // sesi SSO browser (cookie sso_session)
+	UserSessionTypeAPI = "API" // login langsung lewat /auth/login, ID sama dengan token family
+)
+
+// UserSession adalah satu perangkat tempat user sedang login.
+type UserSession struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"user_id"`
+	Type       string    `json:"type"`
+	FamilyID   string    `json:"family_id"`
+	Device     string    `json:"device"`
+	IPAddress  string    `json:"ip_address"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+type UserSessionRepository interface {
+	Create(session *UserSession) error
+	FindByID(id string) (*UserSession, error)
+	FindByUserID(userID string) ([]UserSession, error)
+	Touch(id string, at time.Time) error
+	Delete(session *UserSession) error
+}
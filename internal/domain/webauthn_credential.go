@@ -0,0 +1,58 @@
+package domain
+
+import "time"
+
+// WebAuthnCredential adalah passkey yang terdaftar untuk seorang user.
+// CredentialID dan PublicKey (COSE key) disimpan dalam base64url.
+type WebAuthnCredential struct {
+	ID           string     `gorm:"type:char(36);primaryKey"`
+	UserID       string     `gorm:"column:m_user_id;type:char(36);not null"`
+	Name         string     `gorm:"type:varchar(255);not null"`
+	CredentialID string     `gorm:"type:varchar(1024);not null"`
+	PublicKey    string     `gorm:"type:text;not null"`
+	Algorithm    int        `gorm:"not null"`
+	SignCount    uint32     `gorm:"not null;default:0"`
+	AAGUID       *string    `gorm:"column:aaguid;type:char(36)"`
+	Transports   []string   `gorm:"type:json;serializer:json"`
+	LastUsedAt   *time.Time `gorm:"type:timestamp"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (WebAuthnCredential) TableName() string {
+	return "webauthn_credentials"
+}
+
+type WebAuthnCredentialRepository interface {
+	FindByUserID(userID string) ([]WebAuthnCredential, error)
+	FindByCredentialID(credentialID string) (*WebAuthnCredential, error)
+	Create(credential *WebAuthnCredential) error
+	UpdateUsage(id string, signCount uint32, usedAt time.Time) error
+	Delete(userID, id string) error
+}
+
+const (
+	WebAuthnCeremonyRegistration = "registration"
+	WebAuthnCeremonyLogin        = "login"
+	WebAuthnCeremonySecondFactor = "second_factor"
+)
+
+// WebAuthnSession menyimpan challenge satu ceremony WebAuthn sampai browser
+// mengirimkan hasilnya. UserID kosong untuk login passwordless tanpa email.
+// BrowserID mengikat ceremony login web ke browser yang memulainya sehingga
+// hasil ceremony milik orang lain tidak bisa disisipkan (login CSRF).
+type WebAuthnSession struct {
+	ID             string    `json:"id"`
+	Ceremony       string    `json:"ceremony"`
+	Challenge      string    `json:"challenge"`
+	UserID         string    `json:"user_id,omitempty"`
+	ChallengeToken string    `json:"challenge_token,omitempty"`
+	BrowserID      string    `json:"browser_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type WebAuthnSessionRepository interface {
+	Create(session *WebAuthnSession, ttl time.Duration) error
+	// Consume mengambil sekaligus menghapus session sehingga challenge hanya bisa dipakai sekali.
+	Consume(id string) (*WebAuthnSession, error)
+}
@@ -0,0 +1,60 @@
+package dto
+
+import "slices"
+
+// AccessScope membatasi data yang boleh diakses pemanggil lewat permission
+// ber-scope. Nil berarti permission berlaku tanpa batas. MajorIDs sudah
+// dijabarkan ke StudyProgramIDs dan LabIDs saat permission dimuat sehingga
+// pemeriksaan cukup melihat satu daftar.
+type AccessScope struct {
+	MajorIDs        []string `json:"major_ids"`
+	StudyProgramIDs []string `json:"study_program_ids"`
+	LabIDs          []string `json:"lab_ids"`
+}
+
+func (s *AccessScope) AllowsMajor(id string) bool {
+	return s == nil || slices.Contains(s.MajorIDs, id)
+}
+
+func (s *AccessScope) AllowsStudyProgram(id string) bool {
+	return s == nil || slices.Contains(s.StudyProgramIDs, id)
+}
+
+func (s *AccessScope) AllowsLab(id string) bool {
+	return s == nil || slices.Contains(s.LabIDs, id)
+}
+
+// Covers bernilai true bila semua data dalam other juga berada dalam scope ini.
+func (s *AccessScope) Covers(other AccessScope) bool {
+	if s == nil {
+		return true
+	}
+	return containsAll(s.MajorIDs, other.MajorIDs) &&
+		containsAll(s.StudyProgramIDs, other.StudyProgramIDs) &&
+		containsAll(s.LabIDs, other.LabIDs)
+}
+
+// Merge menggabungkan scope lain, mis. saat route menerima beberapa permission.
+func (s *AccessScope) Merge(other AccessScope) {
+	s.MajorIDs = mergeIDs(s.MajorIDs, other.MajorIDs)
+	s.StudyProgramIDs = mergeIDs(s.StudyProgramIDs, other.StudyProgramIDs)
+	s.LabIDs = mergeIDs(s.LabIDs, other.LabIDs)
+}
+
+func containsAll(ids, subset []string) bool {
+	for _, id := range subset {
+		if !slices.Contains(ids, id) {
+			return false
+		}
+	}
+	return true
+}
+
+func mergeIDs(dst, src []string) []string {
+	for _, id := range src {
+		if !slices.Contains(dst, id) {
+			dst = append(dst, id)
+		}
+	}
+	return dst
+}
@@ -8,8 +8,27 @@ type LoginRequestDTO struct {
 }
 
 type LoginResponseDTO struct {
-	Token string        `json:"token"`
-	User  UserLoginInfo `json:"user"`
+	Token        string        `json:"token"`
+	RefreshToken string        `json:"refresh_token,omitempty"`
+	ExpiresIn    int           `json:"expires_in,omitempty"`
+	User         UserLoginInfo `json:"user"`
+	Restrictions []string      `json:"restrictions,omitempty"`
+	SessionID    string        `json:"-"`
+	// ChallengeToken terisi jika login masih menunggu kode 2FA; token belum diterbitkan.
+	ChallengeToken   string   `json:"-"`
+	ChallengeMethods []string `json:"-"`
+}
+
+type RefreshTokenRequestDTO struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type TokenPairDTO struct {
+	AccessToken  string   `json:"token"`
+	RefreshToken string   `json:"refresh_token"`
+	ExpiresIn    int      `json:"expires_in"`
+	Restrictions []string `json:"restrictions,omitempty"`
+	SessionID    string   `json:"-"`
 }
 
 type UserLoginInfo struct {
@@ -28,14 +47,36 @@ type ForgotPasswordRequestDTO struct {
 type ResetPasswordRequestDTO struct {
 	Token           string `json:"token" binding:"required"`
 	Email           string `json:"email" binding:"required,email"`
-	Password        string `json:"password" binding:"required,min=8"`
+	Password        string `json:"password" binding:"required"`
+	ConfirmPassword string `json:"password_confirmation" binding:"required,eqfield=Password"`
+}
+
+type ChangePasswordRequestDTO struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	Password        string `json:"password" binding:"required"`
 	ConfirmPassword string `json:"password_confirmation" binding:"required,eqfield=Password"`
 }
 
 type EmailTemplateAuthDataDto struct {
-	Name    string
-	Link    string
-	LogoURL string
+	Name      string
+	Link      string
+	LogoURL   string
+	ExpiresIn string
+}
+
+type ResendVerificationEmailRequestDTO struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// CohortVerificationRequestDTO memilih mahasiswa satu angkatan, opsional
+// dipersempit ke satu program studi.
+type CohortVerificationRequestDTO struct {
+	Generation     int    `json:"generation" binding:"required,gte=1900"`
+	StudyProgramID string `json:"study_program_id" binding:"omitempty,uuid"`
+}
+
+type CohortVerificationResource struct {
+	Queued int `json:"queued"`
 }
 
 type UserDetailInfoDTO struct {
@@ -61,4 +102,6 @@ type UserDetailInfoDTO struct {
 	DeletedAt        *time.Time             `json:"deleted_at,omitempty"`
 	EmployeeDetail   *EmployeeDetailInfoDTO `json:"employee_detail,omitempty"`
 	StudentDetail    *StudentDetailInfoDTO  `json:"student_detail,omitempty"`
+	// Impersonation hanya terisi bila token dipakai admin atas nama user ini.
+	Impersonation *ImpersonationBannerDTO `json:"impersonation,omitempty"`
 }
@@ -14,19 +14,21 @@ const (
 // StoreEmployeeDTO combines user and employee data for creation
 // Based on StoreEmployeeRequest.php
 type StoreEmployeeDTO struct {
-	MajorID     *string               `form:"m_major_id" binding:"omitempty,uuid"`
-	NIP         string                `form:"nip" binding:"required,max=255"`
-	Position    string                `form:"position" binding:"required,oneof=DOSEN TEKNISI ADMINISTRASI"`
-	Name        string                `form:"name" binding:"required,max=255"`
-	Email       string                `form:"email" binding:"required,email,max=255"`
-	Gender      *string               `form:"gender" binding:"omitempty,oneof=MALE FEMALE"`
-	Religion    *string               `form:"religion" binding:"omitempty,oneof=ISLAM CHRISTIANITY CATHOLIC HINDUISM BUDDHISM CONFUCIANISM OTHER"`
-	BirthDate   *string               `form:"birth_date" binding:"omitempty,datetime=2006-01-02"`
-	BirthPlace  *string               `form:"birth_place" binding:"omitempty,max=255"`
-	Address     *string               `form:"address" binding:"omitempty,max=255"`
-	PhoneNumber *string               `form:"phone_number" binding:"omitempty,max=20"`
-	Nationality *string               `form:"nationality" binding:"omitempty,max=255"`
-	Avatar      *multipart.FileHeader `form:"avatar" binding:"-"`
+	MajorID     *string `form:"m_major_id" binding:"omitempty,uuid"`
+	NIP         string  `form:"nip" binding:"required,max=255"`
+	Position    string  `form:"position" binding:"required,oneof=DOSEN TEKNISI ADMINISTRASI"`
+	Name        string  `form:"name" binding:"required,max=255"`
+	Email       string  `form:"email" binding:"required,email,max=255"`
+	Gender      *string `form:"gender" binding:"omitempty,oneof=MALE FEMALE"`
+	Religion    *string `form:"religion" binding:"omitempty,oneof=ISLAM CHRISTIANITY CATHOLIC HINDUISM BUDDHISM CONFUCIANISM OTHER"`
+	BirthDate   *string `form:"birth_date" binding:"omitempty,datetime=2006-01-02"`
+	BirthPlace  *string `form:"birth_place" binding:"omitempty,max=255"`
+	Address     *string `form:"address" binding:"omitempty,max=255"`
+	PhoneNumber *string `form:"phone_number" binding:"omitempty,max=20"`
+	Nationality *string `form:"nationality" binding:"omitempty,max=255"`
+	// Password opsional, tanpa password NIP dipakai sebagai password awal
+	Password *string               `form:"password" binding:"omitempty"`
+	Avatar   *multipart.FileHeader `form:"avatar" binding:"-"`
 }
 
 // UpdateEmployeeDTO for updates
@@ -0,0 +1,35 @@
+package dto
+
+import "time"
+
+type IdentityProviderResource struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+type ExternalIdentityResource struct {
+	ID          string     `json:"id"`
+	Provider    string     `json:"provider"`
+	DisplayName string     `json:"display_name"`
+	Email       *string    `json:"email"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// BeginExternalLinkRequestDTO mewajibkan password saat ini agar token yang
+// bocor saja tidak cukup untuk menautkan identitas baru.
+type BeginExternalLinkRequestDTO struct {
+	Provider        string `json:"provider" binding:"required"`
+	CurrentPassword string `json:"current_password" binding:"required"`
+}
+
+// BeginExternalLinkResponseDTO berisi URL IdP yang harus dibuka browser.
+// Setelah callback, browser diarahkan ke halaman profil frontend dengan
+// query link_ticket yang diselesaikan lewat CompleteExternalLinkRequestDTO.
+type BeginExternalLinkResponseDTO struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+type CompleteExternalLinkRequestDTO struct {
+	Ticket string `json:"ticket" binding:"required"`
+}
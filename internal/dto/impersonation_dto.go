@@ -0,0 +1,44 @@
+package dto
+
+import "time"
+
+type StartImpersonationRequestDTO struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+type ImpersonationResponseDTO struct {
+	ImpersonationID string        `json:"impersonation_id"`
+	Token           string        `json:"token"`
+	ExpiresIn       int           `json:"expires_in"`
+	ExpiresAt       time.Time     `json:"expires_at"`
+	User            UserLoginInfo `json:"user"`
+}
+
+// ImpersonationBannerDTO ditampilkan frontend sebagai banner selama admin
+// melihat aplikasi sebagai user lain.
+type ImpersonationBannerDTO struct {
+	Active           bool      `json:"active"`
+	ImpersonationID  string    `json:"impersonation_id"`
+	ImpersonatorID   string    `json:"impersonator_id"`
+	ImpersonatorName string    `json:"impersonator_name"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+type ImpersonationUserResource struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type ImpersonationLogResource struct {
+	ID           string                    `json:"id"`
+	Impersonator ImpersonationUserResource `json:"impersonator"`
+	User         ImpersonationUserResource `json:"user"`
+	Reason       string                    `json:"reason"`
+	IPAddress    *string                   `json:"ip_address"`
+	UserAgent    *string                   `json:"user_agent"`
+	StartedAt    time.Time                 `json:"started_at"`
+	ExpiresAt    time.Time                 `json:"expires_at"`
+	EndedAt      *time.Time                `json:"ended_at"`
+	EndReason    *string                   `json:"end_reason"`
+}
@@ -1,19 +1,34 @@
 package dto
 
 type StoreOauthClientDTO struct {
-	Name     string `json:"name" binding:"required"`
-	Secret   string `json:"secret" binding:"required"`
-	Redirect string `json:"redirect" binding:"required,url"`
+	Name                  string   `json:"name" binding:"required"`
+	Secret                string   `json:"secret" binding:"required_unless=IsPublic true"`
+	RedirectURIs          []string `json:"redirect_uris" binding:"required,min=1,dive,url"`
+	IsPublic              bool     `json:"is_public"`
+	Scopes                []string `json:"scopes" binding:"required,min=1,dive,oneof=openid profile email academic roles"`
+	PermissionIDs         []string `json:"permission_ids" binding:"omitempty,dive,required"`
+	FrontchannelLogoutURI *string  `json:"frontchannel_logout_uri" binding:"omitempty,url"`
+	BackchannelLogoutURI  *string  `json:"backchannel_logout_uri" binding:"omitempty,url"`
 }
 
 type UpdateOauthClientDTO struct {
-	Name     string `json:"name" binding:"required"`
-	Secret   string `json:"secret" binding:"required"`
-	Redirect string `json:"redirect" binding:"required,url"`
+	Name                  string   `json:"name" binding:"required"`
+	Secret                string   `json:"secret"`
+	RedirectURIs          []string `json:"redirect_uris" binding:"required,min=1,dive,url"`
+	IsPublic              bool     `json:"is_public"`
+	Scopes                []string `json:"scopes" binding:"required,min=1,dive,oneof=openid profile email academic roles"`
+	PermissionIDs         []string `json:"permission_ids" binding:"omitempty,dive,required"`
+	FrontchannelLogoutURI *string  `json:"frontchannel_logout_uri" binding:"omitempty,url"`
+	BackchannelLogoutURI  *string  `json:"backchannel_logout_uri" binding:"omitempty,url"`
 }
 
 type OauthClientResource struct {
-	ID       string `json:"id"`
-	Name     string `json:"name"`
-	Redirect string `json:"redirect"`
+	ID                    string   `json:"id"`
+	Name                  string   `json:"name"`
+	RedirectURIs          []string `json:"redirect_uris"`
+	IsPublic              bool     `json:"is_public"`
+	Scopes                []string `json:"scopes"`
+	Permissions           []string `json:"permissions"`
+	FrontchannelLogoutURI *string  `json:"frontchannel_logout_uri"`
+	BackchannelLogoutURI  *string  `json:"backchannel_logout_uri"`
 }
@@ -3,15 +3,141 @@ package dto
 import "time"
 
 type StoreOauthCodeDTO struct {
-	Code        string
-	ClientID    string
-	UserSub     LoginResponseDTO
-	RedirectURI string
-	ExpiresAt   time.Time
+	Code                string
+	ClientID            string
+	UserID              string
+	SessionID           string
+	RedirectURI         string
+	Scope               string
+	Nonce               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	AuthTime            time.Time
+	ExpiresAt           time.Time
 }
 
 type LoginRequestFormDTO struct {
 	Email    string `form:"email" binding:"required,email"`
 	Password string `form:"password" binding:"required"`
-	ReturnTo string `form:"return_to"`
+	State    string `form:"state"`
+}
+
+type OauthAuthorizeRequestDTO struct {
+	ClientID            string `form:"client_id"`
+	RedirectURI         string `form:"redirect_uri"`
+	ResponseType        string `form:"response_type"`
+	Scope               string `form:"scope"`
+	State               string `form:"state"`
+	Nonce               string `form:"nonce"`
+	CodeChallenge       string `form:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method"`
+}
+
+type OauthTokenRequestDTO struct {
+	GrantType    string `form:"grant_type"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	RefreshToken string `form:"refresh_token"`
+	CodeVerifier string `form:"code_verifier"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
+
+type OauthTokenIntrospectRequestDTO struct {
+	Token         string `form:"token" binding:"required"`
+	TokenTypeHint string `form:"token_type_hint"`
+	ClientID      string `form:"client_id"`
+	ClientSecret  string `form:"client_secret"`
+}
+
+// OauthIntrospectionResponseDTO mengikuti RFC 7662 section 2.2. Token yang tidak
+// aktif hanya mengembalikan `active: false`.
+type OauthIntrospectionResponseDTO struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+	Iss       string `json:"iss,omitempty"`
+	Jti       string `json:"jti,omitempty"`
+}
+
+type OauthTokenResponseDTO struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+type OauthErrorResponseDTO struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+type OidcUserInfoDTO struct {
+	Sub           string               `json:"sub"`
+	Name          string               `json:"name,omitempty"`
+	Picture       string               `json:"picture,omitempty"`
+	Email         string               `json:"email,omitempty"`
+	EmailVerified *bool                `json:"email_verified,omitempty"`
+	Roles         []string             `json:"roles,omitempty"`
+	Permissions   []string             `json:"permissions,omitempty"`
+	Academic      *OidcAcademicInfoDTO `json:"academic,omitempty"`
+}
+
+type OidcAcademicInfoDTO struct {
+	NIM              string `json:"nim,omitempty"`
+	NIP              string `json:"nip,omitempty"`
+	Position         string `json:"position,omitempty"`
+	Generation       *int   `json:"generation,omitempty"`
+	StudyProgramID   string `json:"study_program_id,omitempty"`
+	StudyProgramName string `json:"study_program_name,omitempty"`
+	MajorID          string `json:"major_id,omitempty"`
+	MajorName        string `json:"major_name,omitempty"`
+}
+
+type OauthConsentRequestDTO struct {
+	OauthAuthorizeRequestDTO
+	Decision string `form:"decision" binding:"required,oneof=approve deny"`
+}
+
+type OpenIDConfigurationDTO struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserinfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JwksURI                           string   `json:"jwks_uri"`
+	EndSessionEndpoint                string   `json:"end_session_endpoint,omitempty"`
+	IntrospectionEndpoint             string   `json:"introspection_endpoint,omitempty"`
+	RevocationEndpoint                string   `json:"revocation_endpoint,omitempty"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	FrontchannelLogoutSupported       bool     `json:"frontchannel_logout_supported"`
+	BackchannelLogoutSupported        bool     `json:"backchannel_logout_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+type JWKDTO struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JWKSetDTO struct {
+	Keys []JWKDTO `json:"keys"`
 }
@@ -0,0 +1,15 @@
+package dto
+
+// PasswordViolationDTO adalah satu aturan password policy yang dilanggar.
+type PasswordViolationDTO struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// PasswordPolicyErrorResponse memakai bentuk yang sama dengan ErrorResponse
+// ditambah daftar pelanggaran yang bisa dibaca mesin.
+type PasswordPolicyErrorResponse struct {
+	Message    string                 `json:"message"`
+	Errors     map[string]string      `json:"errors,omitempty"`
+	Violations []PasswordViolationDTO `json:"violations"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+import "time"
+
+type PersonalAccessTokenResource struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Permissions []string   `json:"permissions"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// CreatedPersonalAccessTokenResponseDTO membawa token mentah yang hanya
+// ditampilkan sekali saat dibuat.
+type CreatedPersonalAccessTokenResponseDTO struct {
+	PersonalAccessTokenResource
+	Token string `json:"token"`
+}
+
+type StorePersonalAccessTokenDTO struct {
+	Name        string     `json:"name" binding:"required,max=255"`
+	Permissions []string   `json:"permissions" binding:"required,min=1,dive,required"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
@@ -7,4 +7,7 @@ type QueryParams struct {
 	Sort    string
 	Order   string
 	Filter  map[string]interface{}
+	// Scope diisi dari permission ber-scope pemanggil; repository wajib
+	// membatasi hasil FindAll ke scope ini bila tidak nil.
+	Scope *AccessScope
 }
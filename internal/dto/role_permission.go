@@ -1,9 +1,10 @@
 package dto
 
 type RoleResource struct {
-	ID          string                `json:"id"`
-	Name        string                `json:"name"`
-	Permissions *[]PermissionResource `json:"permissions"`
+	ID                string                `json:"id"`
+	Name              string                `json:"name"`
+	RequiresTwoFactor bool                  `json:"requires_two_factor"`
+	Permissions       *[]PermissionResource `json:"permissions"`
 }
 type PermissionResource struct {
 	ID   string `json:"id"`
@@ -11,13 +12,15 @@ type PermissionResource struct {
 }
 
 type StoreRoleDTO struct {
-	Name        string   `json:"name" binding:"required,max=100"`
-	Permissions []string `json:"permissions" binding:"omitempty,dive,required"`
+	Name              string   `json:"name" binding:"required,max=100"`
+	Permissions       []string `json:"permissions" binding:"omitempty,dive,required"`
+	RequiresTwoFactor bool     `json:"requires_two_factor"`
 }
 
 type UpdateRoleDTO struct {
-	Name        string   `json:"name" binding:"required,max=100"`
-	Permissions []string `json:"permissions" binding:"omitempty,dive,required"`
+	Name              string   `json:"name" binding:"required,max=100"`
+	Permissions       []string `json:"permissions" binding:"omitempty,dive,required"`
+	RequiresTwoFactor bool     `json:"requires_two_factor"`
 }
 
 type StorePermissionDTO struct {
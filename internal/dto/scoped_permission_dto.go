@@ -0,0 +1,18 @@
+package dto
+
+import "time"
+
+type ScopedPermissionResource struct {
+	ID             string    `json:"id"`
+	PermissionID   string    `json:"permission_id"`
+	PermissionName string    `json:"permission_name"`
+	ScopeType      string    `json:"scope_type"`
+	ScopeID        string    `json:"scope_id"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type StoreScopedPermissionDTO struct {
+	PermissionID string `json:"permission_id" binding:"required,uuid"`
+	ScopeType    string `json:"scope_type" binding:"required,oneof=major study_program lab"`
+	ScopeID      string `json:"scope_id" binding:"required,uuid"`
+}
@@ -22,6 +22,8 @@ type StoreStudentDTO struct {
 	SemesterId     string  `form:"semester_id" binding:"required,uuid"`
 	StudyProgramID string  `form:"study_program_id" binding:"required,uuid"`
 	Class          string  `form:"class" binding:"required,max=255"`
+	// Password opsional, tanpa password NIM dipakai sebagai password awal
+	Password *string `form:"password" binding:"omitempty"`
 
 	Avatar *multipart.FileHeader `form:"avatar" binding:"-"`
 }
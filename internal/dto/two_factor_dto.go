@@ -0,0 +1,40 @@
+package dto
+
+type TwoFactorCodeRequestDTO struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type TwoFactorLoginRequestDTO struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+type TwoFactorLoginFormDTO struct {
+	ChallengeToken string `form:"challenge_token" binding:"required"`
+	Code           string `form:"code" binding:"required"`
+	State          string `form:"state"`
+	Methods        string `form:"methods"`
+}
+
+type TwoFactorStatusResource struct {
+	Enabled                bool `json:"enabled"`
+	Required               bool `json:"required"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+type TwoFactorSetupResource struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type TwoFactorRecoveryCodesResource struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TwoFactorChallengeResource dikembalikan oleh login jika user harus memasukkan kode 2FA.
+type TwoFactorChallengeResource struct {
+	TwoFactorRequired bool     `json:"two_factor_required"`
+	ChallengeToken    string   `json:"challenge_token"`
+	Methods           []string `json:"methods"`
+	ExpiresIn         int      `json:"expires_in"`
+}
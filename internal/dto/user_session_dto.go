@@ -0,0 +1,19 @@
+package dto
+
+import "time"
+
+type SessionMetaDTO struct {
+	IPAddress string
+	UserAgent string
+}
+
+type UserSessionResource struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	Device     string    `json:"device"`
+	IPAddress  string    `json:"ip_address"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"`
+}
@@ -0,0 +1,113 @@
+package dto
+
+import "time"
+
+// Semua nilai biner pada DTO WebAuthn dikodekan base64url tanpa padding;
+// halaman login mengubahnya ke ArrayBuffer sebelum memanggil navigator.credentials.
+
+type WebAuthnRelyingPartyDTO struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type WebAuthnUserEntityDTO struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+type WebAuthnCredentialParameterDTO struct {
+	Type string `json:"type"`
+	Alg  int    `json:"alg"`
+}
+
+type WebAuthnCredentialDescriptorDTO struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+type WebAuthnAuthenticatorSelectionDTO struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+type WebAuthnCreationOptionsDTO struct {
+	Challenge              string                            `json:"challenge"`
+	RP                     WebAuthnRelyingPartyDTO           `json:"rp"`
+	User                   WebAuthnUserEntityDTO             `json:"user"`
+	PubKeyCredParams       []WebAuthnCredentialParameterDTO  `json:"pubKeyCredParams"`
+	Timeout                int                               `json:"timeout"`
+	ExcludeCredentials     []WebAuthnCredentialDescriptorDTO `json:"excludeCredentials"`
+	AuthenticatorSelection WebAuthnAuthenticatorSelectionDTO `json:"authenticatorSelection"`
+	Attestation            string                            `json:"attestation"`
+}
+
+type WebAuthnRequestOptionsDTO struct {
+	Challenge        string                            `json:"challenge"`
+	RPID             string                            `json:"rpId"`
+	Timeout          int                               `json:"timeout"`
+	AllowCredentials []WebAuthnCredentialDescriptorDTO `json:"allowCredentials"`
+	UserVerification string                            `json:"userVerification"`
+}
+
+// WebAuthnBeginResponseDTO dikembalikan saat ceremony dimulai. SessionID harus
+// dikirim kembali bersama hasil dari browser.
+type WebAuthnBeginResponseDTO struct {
+	SessionID string `json:"session_id"`
+	PublicKey any    `json:"public_key"`
+}
+
+type WebAuthnAuthenticatorResponseDTO struct {
+	ClientDataJSON    string   `json:"clientDataJSON" binding:"required"`
+	AttestationObject string   `json:"attestationObject,omitempty"`
+	AuthenticatorData string   `json:"authenticatorData,omitempty"`
+	Signature         string   `json:"signature,omitempty"`
+	UserHandle        string   `json:"userHandle,omitempty"`
+	Transports        []string `json:"transports,omitempty"`
+}
+
+// WebAuthnCredentialDTO adalah hasil navigator.credentials.create/get yang diserialisasi.
+type WebAuthnCredentialDTO struct {
+	ID       string                           `json:"id" binding:"required"`
+	RawID    string                           `json:"rawId" binding:"required"`
+	Type     string                           `json:"type" binding:"required,eq=public-key"`
+	Response WebAuthnAuthenticatorResponseDTO `json:"response" binding:"required"`
+}
+
+type WebAuthnRegisterFinishRequestDTO struct {
+	SessionID  string                `json:"session_id" binding:"required"`
+	Name       string                `json:"name" binding:"required,max=100"`
+	Credential WebAuthnCredentialDTO `json:"credential" binding:"required"`
+}
+
+type WebAuthnLoginBeginRequestDTO struct {
+	Email string `json:"email" binding:"omitempty,email"`
+}
+
+type WebAuthnLoginFinishRequestDTO struct {
+	SessionID  string                `json:"session_id" binding:"required"`
+	Credential WebAuthnCredentialDTO `json:"credential" binding:"required"`
+	State      string                `json:"state"`
+	BrowserID  string                `json:"-"`
+}
+
+type WebAuthnSecondFactorBeginRequestDTO struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+}
+
+type WebAuthnSecondFactorFinishRequestDTO struct {
+	ChallengeToken string                `json:"challenge_token" binding:"required"`
+	SessionID      string                `json:"session_id" binding:"required"`
+	Credential     WebAuthnCredentialDTO `json:"credential" binding:"required"`
+	State          string                `json:"state"`
+	BrowserID      string                `json:"-"`
+}
+
+type WebAuthnCredentialResource struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Transports []string   `json:"transports"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
@@ -1,30 +1,29 @@
 package handler
 
 import (
-	"encoding/base64"
-	"encoding/json"
 	"errors"
-	"fmt"
+	"jti-super-app-go/config"
+	"jti-super-app-go/internal/domain"
 	"jti-super-app-go/internal/dto"
-	"jti-super-app-go/internal/service"
 	"jti-super-app-go/internal/usecase"
-	"jti-super-app-go/pkg/constants"
 	"jti-super-app-go/pkg/helper"
+	"math"
 	"net/http"
-	"net/url"
-
-	"github.com/google/uuid"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
 type AuthHandler struct {
-	useCase           usecase.AuthUseCase
-	googleAuthService service.GoogleAuthService
+	useCase              usecase.AuthUseCase
+	userSessionUseCase   usecase.UserSessionUseCase
+	webAuthnUseCase      usecase.WebAuthnUseCase
+	verificationUseCase  usecase.EmailVerificationUseCase
+	impersonationUseCase usecase.ImpersonationUseCase
 }
 
-func NewAuthHandler(uc usecase.AuthUseCase, gas service.GoogleAuthService) *AuthHandler {
-	return &AuthHandler{useCase: uc, googleAuthService: gas}
+func NewAuthHandler(uc usecase.AuthUseCase, sc usecase.UserSessionUseCase, wc usecase.WebAuthnUseCase, ev usecase.EmailVerificationUseCase, ic usecase.ImpersonationUseCase) *AuthHandler {
+	return &AuthHandler{useCase: uc, userSessionUseCase: sc, webAuthnUseCase: wc, verificationUseCase: ev, impersonationUseCase: ic}
 }
 
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -35,67 +34,141 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	res, err := h.useCase.Login(req)
+	var locked *usecase.AccountLockedError
+	if errors.As(err, &locked) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+		helper.ErrorResponse(c, http.StatusTooManyRequests, err.Error(), err)
+		return
+	}
 	if err != nil {
 		helper.ErrorResponse(c, http.StatusBadRequest, err.Error(), err)
 		return
 	}
 
-	helper.SuccessResponse(c, http.StatusOK, "Login successful", res)
+	if res.ChallengeToken != "" {
+		helper.SuccessResponse(c, http.StatusOK, "Two-factor authentication required", dto.TwoFactorChallengeResource{
+			TwoFactorRequired: true,
+			ChallengeToken:    res.ChallengeToken,
+			Methods:           res.ChallengeMethods,
+			ExpiresIn:         int(usecase.TwoFactorChallengeTTL.Seconds()),
+		})
+		return
+	}
+
+	h.respondLogin(c, res)
 }
 
-func (h *AuthHandler) GoogleLogin(c *gin.Context) {
-	state := uuid.NewString()
-	host := c.Request.Header.Get("Referer")
-	fmt.Print("request header: ", c.Request.Header)
-	fmt.Println("request host: ", host)
+func (h *AuthHandler) VerifyTwoFactor(c *gin.Context) {
+	var req dto.TwoFactorLoginRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
 
-	c.SetCookie("oauth_state", state, 3600, "/api/v1/auth/google/callback", c.Request.URL.Hostname(), false, true)
-	c.SetCookie("host", host, 3600, "/api/v1/auth/google/callback", c.Request.URL.Hostname(), false, true)
+	res, err := h.useCase.VerifyTwoFactor(req)
+	if errors.Is(err, usecase.ErrInvalidTwoFactorCode) || errors.Is(err, usecase.ErrInvalidTwoFactorChallenge) {
+		helper.ErrorResponse(c, http.StatusUnauthorized, err.Error(), err)
+		return
+	}
+	if err != nil {
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to verify two-factor code", err)
+		return
+	}
 
-	url := h.googleAuthService.GenerateAuthURL(state)
-	c.Redirect(http.StatusTemporaryRedirect, url)
+	h.respondLogin(c, res)
 }
 
-func (h *AuthHandler) GoogleCallback(c *gin.Context) {
-	stateFromCookie, err := c.Cookie("oauth_state")
-	hostFromCookie, _ := c.Cookie("host")
+// BeginPasskeyLogin memulai login passwordless dengan passkey.
+func (h *AuthHandler) BeginPasskeyLogin(c *gin.Context) {
+	var req dto.WebAuthnLoginBeginRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
 
-	parsedHost := hostFromCookie
-	if len(hostFromCookie) > 0 {
-		u, err := url.Parse(hostFromCookie)
-		if err == nil {
-			parsedHost = u.Scheme + "://" + u.Host
-		}
+	res, err := h.webAuthnUseCase.BeginLogin(req.Email, "")
+	if err != nil {
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to start passkey login", err)
+		return
+	}
+
+	helper.SuccessResponse(c, http.StatusOK, "Passkey login started", res)
+}
+
+func (h *AuthHandler) FinishPasskeyLogin(c *gin.Context) {
+	var req dto.WebAuthnLoginFinishRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err)
+		return
 	}
-	hostFromCookie = parsedHost
 
+	res, err := h.useCase.LoginWithPasskey(req)
 	if err != nil {
-		c.Redirect(http.StatusTemporaryRedirect, hostFromCookie+constants.CALLBACK_FRONTEND+"?error="+base64.URLEncoding.EncodeToString([]byte("State cookie not found")))
+		helper.ErrorResponse(c, http.StatusUnauthorized, err.Error(), err)
 		return
 	}
-	if c.Query("state") != stateFromCookie {
-		c.Redirect(http.StatusTemporaryRedirect, hostFromCookie+constants.CALLBACK_FRONTEND+"?error="+base64.URLEncoding.EncodeToString([]byte("Invalid state parameter")))
+
+	h.respondLogin(c, res)
+}
+
+// BeginTwoFactorPasskey memulai ceremony passkey untuk menjawab challenge 2FA.
+func (h *AuthHandler) BeginTwoFactorPasskey(c *gin.Context) {
+	var req dto.WebAuthnSecondFactorBeginRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err)
 		return
 	}
 
-	code := c.Query("code")
-	userInfo, err := h.googleAuthService.GetUserInfo(code)
+	res, err := h.webAuthnUseCase.BeginSecondFactor(req.ChallengeToken, "")
 	if err != nil {
-		encodeError := base64.URLEncoding.EncodeToString([]byte(err.Error()))
-		c.Redirect(http.StatusTemporaryRedirect, hostFromCookie+constants.CALLBACK_FRONTEND+"?error="+encodeError)
+		helper.ErrorResponse(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	helper.SuccessResponse(c, http.StatusOK, "Passkey verification started", res)
+}
+
+func (h *AuthHandler) VerifyTwoFactorPasskey(c *gin.Context) {
+	var req dto.WebAuthnSecondFactorFinishRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	res, err := h.useCase.VerifyTwoFactorPasskey(req)
+	if err != nil {
+		helper.ErrorResponse(c, http.StatusUnauthorized, err.Error(), err)
+		return
+	}
+
+	h.respondLogin(c, res)
+}
+
+func (h *AuthHandler) respondLogin(c *gin.Context, res *dto.LoginResponseDTO) {
+	if _, err := h.userSessionUseCase.Start(res.User.ID, domain.UserSessionTypeAPI, res.SessionID, helper.SessionMeta(c)); err != nil {
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to start session", err)
+		return
+	}
+
+	helper.SuccessResponse(c, http.StatusOK, "Login successful", res)
+}
+
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req dto.RefreshTokenRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ValidationErrorJSON(c, err)
 		return
 	}
 
-	res, err := h.useCase.LoginWithGoogle(userInfo)
+	res, err := h.useCase.Refresh(req.RefreshToken)
 	if err != nil {
-		encodeError := base64.URLEncoding.EncodeToString([]byte(err.Error()))
-		c.Redirect(http.StatusTemporaryRedirect, hostFromCookie+constants.CALLBACK_FRONTEND+"?error="+encodeError)
+		helper.ErrorResponse(c, http.StatusUnauthorized, err.Error(), err)
 		return
 	}
+	h.userSessionUseCase.Touch(res.SessionID)
 
-	userInfoJSON, _ := json.Marshal(res.User)
-	encodedUser := base64.URLEncoding.EncodeToString(userInfoJSON)
-	c.Redirect(http.StatusPermanentRedirect, hostFromCookie+constants.CALLBACK_FRONTEND+"?token="+res.Token+"&user="+encodedUser)
+	c.Header("Cache-Control", "no-store")
+	helper.SuccessResponse(c, http.StatusOK, "Token refreshed successfully", res)
 }
 
 func (h *AuthHandler) Logout(c *gin.Context) {
@@ -111,12 +184,26 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 		return
 	}
 
+	// Logout dengan token impersonation hanya mengakhiri impersonation, bukan sesi user
+	if impersonatorID := c.GetString("impersonator_id"); impersonatorID != "" {
+		if err := h.impersonationUseCase.Stop(c.GetString("session_id"), impersonatorID, domain.ImpersonationEndLogout); err != nil {
+			helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to stop impersonation", err)
+			return
+		}
+		helper.SuccessResponse(c, http.StatusOK, "Successfully logged out", nil)
+		return
+	}
+
 	err := h.useCase.Logout(tokenString)
 	if err != nil {
 		helper.ErrorResponse(c, http.StatusInternalServerError, err.Error(), errors.New("logout failed"))
 		return
 	}
 
+	if sessionID := c.GetString("session_id"); sessionID != "" {
+		_, _ = h.userSessionUseCase.Revoke(c.GetString("user_id"), sessionID)
+	}
+
 	helper.SuccessResponse(c, http.StatusOK, "Successfully logged out", nil)
 }
 
@@ -142,43 +229,93 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 		return
 	}
 
-	if err := h.useCase.ResetPassword(req); err != nil {
+	userID, err := h.useCase.ResetPassword(req)
+	var policyErr *usecase.PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		helper.PasswordPolicyErrorResponse(c, policyErr.Violations)
+		return
+	}
+	if err != nil {
 		helper.ErrorResponse(c, http.StatusUnprocessableEntity, err.Error(), err)
 		return
 	}
 
+	// Password di-reset karena bisa jadi akun sudah disusupi, jadi semua sesi diakhiri
+	if err := h.userSessionUseCase.RevokeAll(userID); err != nil {
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Password reset but existing sessions could not be revoked", err)
+		return
+	}
+
 	helper.SuccessResponse(c, http.StatusOK, "Password has been successfully reset", nil)
 }
 
-func (h *AuthHandler) VerifyEmail(c *gin.Context) {
-	token := c.Param("token")
-	if err := h.useCase.VerifyEmail(token); err != nil {
-		// Arahkan ke halaman error di frontend
-		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+// ChangePassword mengganti password lalu mengakhiri sesi di perangkat lain.
+// Token yang sedang dipakai tetap berlaku; klien perlu refresh untuk mendapat
+// token tanpa restriction password_change.
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	var req dto.ChangePasswordRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ValidationErrorJSON(c, err)
+		return
+	}
+
+	userID := c.GetString("user_id")
+	err := h.useCase.ChangePassword(userID, req)
+	var policyErr *usecase.PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		helper.PasswordPolicyErrorResponse(c, policyErr.Violations)
+		return
+	}
+	if errors.Is(err, usecase.ErrInvalidCurrentPassword) {
+		helper.ErrorResponse(c, http.StatusUnprocessableEntity, err.Error(), err)
+		return
+	}
+	if err != nil {
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to change password", err)
+		return
+	}
+
+	if err := h.userSessionUseCase.RevokeOthers(userID, c.GetString("session_id")); err != nil {
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Password changed but other sessions could not be revoked", err)
 		return
 	}
-	// Arahkan ke halaman sukses di frontend
-	c.JSON(http.StatusOK, gin.H{"message": "Email verified successfully"})
+
+	helper.SuccessResponse(c, http.StatusOK, "Password has been successfully changed", nil)
 }
 
-func (h *AuthHandler) ResendVerificationEmail(c *gin.Context) {
-	// Ambil email dari user yang sedang login (dari JWT)
-	// Ini hanyalah contoh, sesuaikan dengan implementasi Anda
-	// userID, _ := c.Get("user_id")
-	// Anda perlu mengambil detail user dari ID ini untuk mendapatkan emailnya
-	// Misal, Anda memiliki user usecase
-	// user, err := h.userUseCase.FindByID(userID.(string))
+// VerifyEmail dibuka dari link di email sehingga hasilnya berupa redirect ke
+// halaman frontend, bukan JSON.
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	cfg := config.AppConfig.EmailVerification
+	c.Header("Cache-Control", "no-store")
 
-	// Untuk sementara, kita ambil dari body
-	var req struct {
-		Email string `json:"email" binding:"required,email"`
+	if err := h.verificationUseCase.Verify(c.Param("token")); err != nil {
+		reason := "error"
+		if errors.Is(err, usecase.ErrInvalidVerificationToken) {
+			reason = "invalid_or_expired"
+		}
+		c.Redirect(http.StatusFound, cfg.FailureURL+"?reason="+reason)
+		return
 	}
+
+	c.Redirect(http.StatusFound, cfg.SuccessURL)
+}
+
+func (h *AuthHandler) ResendVerificationEmail(c *gin.Context) {
+	var req dto.ResendVerificationEmailRequestDTO
 	if err := c.ShouldBindJSON(&req); err != nil {
 		helper.ValidationErrorJSON(c, err)
 		return
 	}
 
-	if err := h.useCase.ResendVerificationEmail(req.Email); err != nil {
+	err := h.verificationUseCase.Resend(req.Email)
+	var throttled *usecase.VerificationThrottledError
+	if errors.As(err, &throttled) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+		helper.ErrorResponse(c, http.StatusTooManyRequests, err.Error(), err)
+		return
+	}
+	if err != nil {
 		helper.ErrorResponse(c, http.StatusUnprocessableEntity, err.Error(), err)
 		return
 	}
@@ -199,5 +336,38 @@ func (h *AuthHandler) Me(c *gin.Context) {
 		return
 	}
 
+	if c.GetString("impersonator_id") != "" {
+		banner, err := h.impersonationUseCase.Banner(c.GetString("session_id"))
+		if err != nil {
+			helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve impersonation information", err)
+			return
+		}
+		userInfo.Impersonation = banner
+	}
+
 	helper.SuccessResponse(c, http.StatusOK, "User information retrieved successfully", userInfo)
 }
+
+func (h *AuthHandler) Sessions(c *gin.Context) {
+	sessions, err := h.userSessionUseCase.List(c.GetString("user_id"), c.GetString("session_id"))
+	if err != nil {
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch sessions", err)
+		return
+	}
+
+	helper.SuccessResponse(c, http.StatusOK, "Sessions fetched successfully", sessions)
+}
+
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	_, err := h.userSessionUseCase.Revoke(c.GetString("user_id"), c.Param("id"))
+	if errors.Is(err, usecase.ErrUserSessionNotFound) {
+		helper.ErrorResponse(c, http.StatusNotFound, err.Error(), err)
+		return
+	}
+	if err != nil {
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to revoke session", err)
+		return
+	}
+
+	helper.SuccessResponse(c, http.StatusOK, "Session revoked successfully", nil)
+}
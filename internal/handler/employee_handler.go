@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"jti-super-app-go/internal/dto"
 	"jti-super-app-go/internal/usecase"
 	"jti-super-app-go/pkg/helper"
@@ -151,6 +152,11 @@ func (h *EmployeeHandler) Create(c *gin.Context) {
 	}
 
 	employee, err := h.useCase.Create(&payload)
+	var policyErr *usecase.PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		helper.PasswordPolicyErrorResponse(c, policyErr.Violations)
+		return
+	}
 	if err != nil {
 		if strings.Contains(err.Error(), "Duplicate entry") {
 			helper.ErrorResponse(c, http.StatusConflict, "Employee with this email or NIP already exists", err)
@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"jti-super-app-go/config"
+	"jti-super-app-go/internal/domain"
+	"jti-super-app-go/internal/dto"
+	"jti-super-app-go/internal/usecase"
+	"jti-super-app-go/pkg/constants"
+	"jti-super-app-go/pkg/helper"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultIdentityProvider dipakai route lama /auth/google/* yang tidak punya param :provider.
+const defaultIdentityProvider = "google"
+
+type ExternalIdentityHandler struct {
+	useCase            usecase.ExternalIdentityUseCase
+	authUseCase        usecase.AuthUseCase
+	userSessionUseCase usecase.UserSessionUseCase
+	loginTxUseCase     usecase.LoginTransactionUseCase
+}
+
+func NewExternalIdentityHandler(uc usecase.ExternalIdentityUseCase, ac usecase.AuthUseCase, sc usecase.UserSessionUseCase, lc usecase.LoginTransactionUseCase) *ExternalIdentityHandler {
+	return &ExternalIdentityHandler{useCase: uc, authUseCase: ac, userSessionUseCase: sc, loginTxUseCase: lc}
+}
+
+func (h *ExternalIdentityHandler) Providers(c *gin.Context) {
+	helper.SuccessResponse(c, http.StatusOK, "Identity providers fetched successfully", h.useCase.Providers())
+}
+
+// Login mengarahkan browser ke IdP upstream. Query state adalah state transaksi
+// login web yang diteruskan kembali ke /auth/callback.
+func (h *ExternalIdentityHandler) Login(c *gin.Context) {
+	// Token dikirim ke host ini sehingga hanya halaman login sendiri dan frontend yang diterima
+	returnHost := helper.Origin(c.Request.Header.Get("Referer"))
+	if returnHost != helper.Origin(config.AppConfig.AppUrl) {
+		returnHost = helper.Origin(config.AppConfig.FrontendURL)
+	}
+
+	browserID := helper.LoginBrowserID(c, int(usecase.ExternalAuthStateTTL.Seconds()))
+	authURL, err := h.useCase.BeginLogin(identityProviderParam(c), browserID, c.Query("state"), returnHost)
+	if errors.Is(err, usecase.ErrIdentityProviderNotFound) {
+		helper.ErrorResponse(c, http.StatusNotFound, err.Error(), err)
+		return
+	}
+	if err != nil {
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to start external login", err)
+		return
+	}
+
+	c.Redirect(http.StatusTemporaryRedirect, authURL)
+}
+
+func (h *ExternalIdentityHandler) Callback(c *gin.Context) {
+	browserID, _ := helper.GetLoginBrowserID(c)
+	// Saat user membatalkan login di IdP, code kosong dan state tetap dikonsumsi
+	res, err := h.useCase.Callback(c.Request.Context(), identityProviderParam(c), c.Query("state"), c.Query("code"), browserID)
+	if err != nil {
+		if errors.Is(err, usecase.ErrExternalAuthCancelled) && c.Query("error_description") != "" {
+			err = errors.New(c.Query("error_description"))
+		}
+		h.redirectError(c, res, err)
+		return
+	}
+
+	if res.Purpose == domain.ExternalAuthPurposeLink {
+		c.Redirect(http.StatusSeeOther, config.AppConfig.FrontendURL+constants.LINKED_ACCOUNTS_FRONTEND+"?link_ticket="+url.QueryEscape(res.LinkTicket))
+		return
+	}
+
+	login, err := h.authUseCase.LoginWithExternalIdentity(res.User)
+	if err != nil {
+		h.redirectError(c, res, err)
+		return
+	}
+
+	callback := res.ReturnHost + constants.CALLBACK_FRONTEND
+	if login.ChallengeToken != "" {
+		// Frontend melanjutkan ke langkah 2FA dengan challenge token ini
+		c.Redirect(http.StatusTemporaryRedirect, callback+"?challenge_token="+url.QueryEscape(login.ChallengeToken)+"&methods="+url.QueryEscape(strings.Join(login.ChallengeMethods, ","))+"&state="+url.QueryEscape(res.ClientState))
+		return
+	}
+
+	// Halaman login sendiri menukar code sekali pakai yang terikat ke browser
+	// ini; token tidak pernah dipercaya dari query string
+	if res.ReturnHost == helper.Origin(config.AppConfig.AppUrl) {
+		code, err := h.loginTxUseCase.StoreResult(browserID, login)
+		if err != nil {
+			h.redirectError(c, res, err)
+			return
+		}
+		c.Redirect(http.StatusSeeOther, callback+"?code="+url.QueryEscape(code)+"&state="+url.QueryEscape(res.ClientState))
+		return
+	}
+
+	_, _ = h.userSessionUseCase.Start(login.User.ID, domain.UserSessionTypeAPI, login.SessionID, helper.SessionMeta(c))
+
+	userInfoJSON, _ := json.Marshal(login.User)
+	encodedUser := base64.RawURLEncoding.EncodeToString(userInfoJSON)
+	c.Redirect(http.StatusPermanentRedirect, callback+"?token="+login.Token+"&user="+encodedUser+"&state="+url.QueryEscape(res.ClientState))
+}
+
+// redirectError mengembalikan error ke halaman asal alur. Tanpa state yang valid
+// tujuan tidak diketahui sehingga dipakai callback frontend default.
+func (h *ExternalIdentityHandler) redirectError(c *gin.Context, res *usecase.ExternalAuthResult, err error) {
+	encodeError := base64.RawURLEncoding.EncodeToString([]byte(err.Error()))
+	if res != nil && res.Purpose == domain.ExternalAuthPurposeLink {
+		c.Redirect(http.StatusSeeOther, config.AppConfig.FrontendURL+constants.LINKED_ACCOUNTS_FRONTEND+"?error="+encodeError)
+		return
+	}
+
+	returnHost, state := config.AppConfig.FrontendURL, ""
+	if res != nil {
+		returnHost, state = res.ReturnHost, res.ClientState
+	}
+	c.Redirect(http.StatusTemporaryRedirect, returnHost+constants.CALLBACK_FRONTEND+"?error="+encodeError+"&state="+url.QueryEscape(state))
+}
+
+func (h *ExternalIdentityHandler) FindAll(c *gin.Context) {
+	identities, err := h.useCase.List(c.GetString("user_id"))
+	if err != nil {
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch linked identities", err)
+		return
+	}
+
+	helper.SuccessResponse(c, http.StatusOK, "Linked identities fetched successfully", identities)
+}
+
+func (h *ExternalIdentityHandler) BeginLink(c *gin.Context) {
+	var req dto.BeginExternalLinkRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ValidationErrorJSON(c, err)
+		return
+	}
+
+	// Cookie browser mengikat state dan ticket ke browser yang memulai penautan
+	browserID := helper.LoginBrowserID(c, int((usecase.ExternalAuthStateTTL + usecase.ExternalLinkTicketTTL).Seconds()))
+	authURL, err := h.useCase.BeginLink(c.GetString("user_id"), req.Provider, req.CurrentPassword, browserID)
+	if errors.Is(err, usecase.ErrIdentityProviderNotFound) {
+		helper.ErrorResponse(c, http.StatusNotFound, err.Error(), err)
+		return
+	}
+	if errors.Is(err, usecase.ErrInvalidCurrentPassword) {
+		helper.ErrorResponse(c, http.StatusUnprocessableEntity, err.Error(), err)
+		return
+	}
+	if err != nil {
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to start linking", err)
+		return
+	}
+
+	helper.SuccessResponse(c, http.StatusOK, "Linking started", dto.BeginExternalLinkResponseDTO{AuthorizationURL: authURL})
+}
+
+func (h *ExternalIdentityHandler) CompleteLink(c *gin.Context) {
+	var req dto.CompleteExternalLinkRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ValidationErrorJSON(c, err)
+		return
+	}
+
+	browserID, _ := helper.GetLoginBrowserID(c)
+	identity, err := h.useCase.CompleteLink(c.GetString("user_id"), req.Ticket, browserID)
+	switch {
+	case errors.Is(err, usecase.ErrInvalidExternalLinkTicket):
+		helper.ErrorResponse(c, http.StatusUnprocessableEntity, err.Error(), err)
+		return
+	case errors.Is(err, usecase.ErrExternalIdentityInUse), errors.Is(err, usecase.ErrExternalProviderLinked):
+		helper.ErrorResponse(c, http.StatusConflict, err.Error(), err)
+		return
+	case err != nil:
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to link identity", err)
+		return
+	}
+
+	helper.SuccessResponse(c, http.StatusCreated, "Identity linked successfully", identity)
+}
+
+func (h *ExternalIdentityHandler) Unlink(c *gin.Context) {
+	err := h.useCase.Unlink(c.GetString("user_id"), c.Param("id"))
+	if errors.Is(err, usecase.ErrExternalIdentityNotFound) {
+		helper.ErrorResponse(c, http.StatusNotFound, err.Error(), err)
+		return
+	}
+	if err != nil {
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to unlink identity", err)
+		return
+	}
+
+	helper.SuccessResponse(c, http.StatusOK, "Identity unlinked successfully", nil)
+}
+
+func identityProviderParam(c *gin.Context) string {
+	if provider := c.Param("provider"); provider != "" {
+		return provider
+	}
+	return defaultIdentityProvider
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"errors"
+	"jti-super-app-go/internal/domain"
+	"jti-super-app-go/internal/dto"
+	"jti-super-app-go/internal/usecase"
+	"jti-super-app-go/pkg/helper"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ImpersonationHandler struct {
+	useCase usecase.ImpersonationUseCase
+}
+
+func NewImpersonationHandler(uc usecase.ImpersonationUseCase) *ImpersonationHandler {
+	return &ImpersonationHandler{useCase: uc}
+}
+
+func (h *ImpersonationHandler) FindAll(c *gin.Context) {
+	params, ok := helper.ParsePaginationQuery(c)
+	if !ok {
+		return
+	}
+
+	logs, totalRows, err := h.useCase.FindAll(*params, c.Query("user_id"))
+	if err != nil {
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch impersonation logs", err)
+		return
+	}
+
+	meta := &dto.Meta{
+		Page:    params.Page,
+		PerPage: params.PerPage,
+		Total:   totalRows,
+	}
+
+	helper.PaginatedSuccessResponse(c, http.StatusOK, "Impersonation logs fetched successfully", logs, meta)
+}
+
+func (h *ImpersonationHandler) Start(c *gin.Context) {
+	var req dto.StartImpersonationRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ValidationErrorJSON(c, err)
+		return
+	}
+
+	res, err := h.useCase.Start(c.GetString("user_id"), c.Param("id"), req, helper.SessionMeta(c))
+	switch {
+	case errors.Is(err, usecase.ErrImpersonationTargetAbsent):
+		helper.ErrorResponse(c, http.StatusNotFound, err.Error(), err)
+		return
+	case errors.Is(err, usecase.ErrImpersonateSelf), errors.Is(err, usecase.ErrImpersonationNotAllowed):
+		helper.ErrorResponse(c, http.StatusForbidden, err.Error(), err)
+		return
+	case err != nil:
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to start impersonation", err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	helper.SuccessResponse(c, http.StatusCreated, "Impersonation started", res)
+}
+
+// Stop dipanggil dengan token impersonation itu sendiri.
+func (h *ImpersonationHandler) Stop(c *gin.Context) {
+	impersonatorID := c.GetString("impersonator_id")
+	if impersonatorID == "" {
+		helper.ErrorResponse(c, http.StatusBadRequest, "Token is not an impersonation token", errors.New("not impersonating"))
+		return
+	}
+
+	err := h.useCase.Stop(c.GetString("session_id"), impersonatorID, domain.ImpersonationEndStopped)
+	if errors.Is(err, usecase.ErrImpersonationNotFound) {
+		helper.ErrorResponse(c, http.StatusNotFound, err.Error(), err)
+		return
+	}
+	if err != nil {
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to stop impersonation", err)
+		return
+	}
+
+	helper.SuccessResponse(c, http.StatusOK, "Impersonation stopped", nil)
+}
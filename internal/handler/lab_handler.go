@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"jti-super-app-go/internal/dto"
 	"jti-super-app-go/internal/usecase"
 	"jti-super-app-go/pkg/helper"
@@ -64,8 +65,12 @@ func (h *LabHandler) FindAll(c *gin.Context) {
 
 func (h *LabHandler) FindByID(c *gin.Context) {
 	id := c.Param("id")
-	lab, err := h.useCase.FindByID(id)
+	lab, err := h.useCase.FindByID(id, helper.AccessScope(c))
 	if err != nil {
+		if errors.Is(err, usecase.ErrOutsideAccessScope) {
+			helper.ErrorResponse(c, http.StatusForbidden, err.Error(), err)
+			return
+		}
 		helper.ErrorResponse(c, http.StatusNotFound, "Lab not found", err)
 		return
 	}
@@ -86,8 +91,12 @@ func (h *LabHandler) Create(c *gin.Context) {
 		return
 	}
 
-	lab, err := h.useCase.Create(&labDTO)
+	lab, err := h.useCase.Create(&labDTO, helper.AccessScope(c))
 	if err != nil {
+		if errors.Is(err, usecase.ErrOutsideAccessScope) {
+			helper.ErrorResponse(c, http.StatusForbidden, err.Error(), err)
+			return
+		}
 		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to create lab", err)
 		return
 	}
@@ -132,8 +141,12 @@ func (h *LabHandler) Update(c *gin.Context) {
 		return
 	}
 
-	lab, err := h.useCase.Update(id, &labDTO)
+	lab, err := h.useCase.Update(id, &labDTO, helper.AccessScope(c))
 	if err != nil {
+		if errors.Is(err, usecase.ErrOutsideAccessScope) {
+			helper.ErrorResponse(c, http.StatusForbidden, err.Error(), err)
+			return
+		}
 		if err.Error() == "record not found" {
 			helper.ErrorResponse(c, http.StatusNotFound, "Lab not found", err)
 		} else {
@@ -152,8 +165,12 @@ func (h *LabHandler) Update(c *gin.Context) {
 
 func (h *LabHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
-	err := h.useCase.Delete(id)
+	err := h.useCase.Delete(id, helper.AccessScope(c))
 	if err != nil {
+		if errors.Is(err, usecase.ErrOutsideAccessScope) {
+			helper.ErrorResponse(c, http.StatusForbidden, err.Error(), err)
+			return
+		}
 		if err.Error() == "record not found" {
 			helper.ErrorResponse(c, http.StatusNotFound, "Lab not found", err)
 		} else {
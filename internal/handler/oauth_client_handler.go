@@ -32,9 +32,14 @@ func (h *OauthClientHandler) FindAll(c *gin.Context) {
 	clientResources := []dto.OauthClientResource{}
 	for _, client := range *clients {
 		clientResources = append(clientResources, dto.OauthClientResource{
-			ID:       client.ID,
-			Name:     client.Name,
-			Redirect: client.Redirect,
+			ID:                    client.ID,
+			Name:                  client.Name,
+			RedirectURIs:          client.RedirectURIs,
+			IsPublic:              client.IsPublic,
+			Scopes:                client.Scopes,
+			Permissions:           client.PermissionNames(),
+			FrontchannelLogoutURI: client.FrontchannelLogoutURI,
+			BackchannelLogoutURI:  client.BackchannelLogoutURI,
 		})
 	}
 
@@ -56,9 +61,14 @@ func (h *OauthClientHandler) FindByID(c *gin.Context) {
 	}
 
 	clientResource := dto.OauthClientResource{
-		ID:       client.ID,
-		Name:     client.Name,
-		Redirect: client.Redirect,
+		ID:                    client.ID,
+		Name:                  client.Name,
+		RedirectURIs:          client.RedirectURIs,
+		IsPublic:              client.IsPublic,
+		Scopes:                client.Scopes,
+		Permissions:           client.PermissionNames(),
+		FrontchannelLogoutURI: client.FrontchannelLogoutURI,
+		BackchannelLogoutURI:  client.BackchannelLogoutURI,
 	}
 
 	helper.SuccessResponse(c, http.StatusOK, "OAuth client fetched successfully", clientResource)
@@ -78,9 +88,14 @@ func (h *OauthClientHandler) Create(c *gin.Context) {
 	}
 
 	clientResource := dto.OauthClientResource{
-		ID:       client.ID,
-		Name:     client.Name,
-		Redirect: client.Redirect,
+		ID:                    client.ID,
+		Name:                  client.Name,
+		RedirectURIs:          client.RedirectURIs,
+		IsPublic:              client.IsPublic,
+		Scopes:                client.Scopes,
+		Permissions:           client.PermissionNames(),
+		FrontchannelLogoutURI: client.FrontchannelLogoutURI,
+		BackchannelLogoutURI:  client.BackchannelLogoutURI,
 	}
 
 	helper.SuccessResponse(c, http.StatusCreated, "OAuth client created successfully", clientResource)
@@ -101,9 +116,14 @@ func (h *OauthClientHandler) Update(c *gin.Context) {
 	}
 
 	clientResource := dto.OauthClientResource{
-		ID:       client.ID,
-		Name:     client.Name,
-		Redirect: client.Redirect,
+		ID:                    client.ID,
+		Name:                  client.Name,
+		RedirectURIs:          client.RedirectURIs,
+		IsPublic:              client.IsPublic,
+		Scopes:                client.Scopes,
+		Permissions:           client.PermissionNames(),
+		FrontchannelLogoutURI: client.FrontchannelLogoutURI,
+		BackchannelLogoutURI:  client.BackchannelLogoutURI,
 	}
 
 	helper.SuccessResponse(c, http.StatusOK, "OAuth client updated successfully", clientResource)
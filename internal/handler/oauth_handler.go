@@ -2,79 +2,115 @@ package handler
 
 import (
 	"encoding/base64"
-	"encoding/json"
+	"errors"
 	"jti-super-app-go/config"
+	"jti-super-app-go/internal/domain"
 	"jti-super-app-go/internal/dto"
+	"jti-super-app-go/internal/service"
 	"jti-super-app-go/internal/usecase"
+	"jti-super-app-go/pkg/constants"
 	"jti-super-app-go/pkg/helper"
 	"net/http"
 	"net/url"
-	"time"
+	"slices"
+	"strings"
 
 	"github.com/gin-gonic/gin"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type OauthHandler struct {
-	useCase      usecase.OauthClientUseCase
-	oauthUseCase usecase.OauthUsecase
-	authUseCase  usecase.AuthUseCase
+	useCase            usecase.OauthClientUseCase
+	oauthUseCase       usecase.OauthUsecase
+	authUseCase        usecase.AuthUseCase
+	tokenUseCase       usecase.TokenUseCase
+	loginTxUseCase     usecase.LoginTransactionUseCase
+	userSessionUseCase usecase.UserSessionUseCase
+	webAuthnUseCase    usecase.WebAuthnUseCase
+	externalUseCase    usecase.ExternalIdentityUseCase
+	oidcService        service.OIDCService
 }
 
-func NewOauthHandler(uc usecase.OauthClientUseCase, oc usecase.OauthUsecase, ac usecase.AuthUseCase) *OauthHandler {
-	return &OauthHandler{useCase: uc, oauthUseCase: oc, authUseCase: ac}
+func NewOauthHandler(uc usecase.OauthClientUseCase, oc usecase.OauthUsecase, ac usecase.AuthUseCase, tc usecase.TokenUseCase, lc usecase.LoginTransactionUseCase, sc usecase.UserSessionUseCase, wc usecase.WebAuthnUseCase, ec usecase.ExternalIdentityUseCase, oidc service.OIDCService) *OauthHandler {
+	return &OauthHandler{useCase: uc, oauthUseCase: oc, authUseCase: ac, tokenUseCase: tc, loginTxUseCase: lc, userSessionUseCase: sc, webAuthnUseCase: wc, externalUseCase: ec, oidcService: oidc}
 }
 
 func (h *OauthHandler) Authorize(c *gin.Context) {
-	clientID := c.Query("client_id")
-	redirectURI := c.Query("redirect_uri")
-	responseType := c.Query("response_type")
+	var req dto.OauthAuthorizeRequestDTO
+	_ = c.ShouldBindQuery(&req)
 
-	if clientID == "" || redirectURI == "" || responseType != "code" {
+	if req.ClientID == "" || req.RedirectURI == "" || req.ResponseType != "code" {
 		helper.ClearSSO(c)
 		helper.RedirectBackToLogin(c, "/login", c.Request.URL.RequestURI(), "",
 			"Invalid request parameters")
 		return
 	}
 
-	client, err := h.useCase.FindByID(clientID)
-	if err != nil || client.Redirect != redirectURI {
+	client, err := h.useCase.FindByID(req.ClientID)
+	if err != nil || !client.HasRedirectURI(req.RedirectURI) {
 		helper.ClearSSO(c)
 		helper.RedirectBackToLogin(c, "/login", c.Request.URL.RequestURI(), "",
 			"Invalid client or redirect URI")
 		return
 	}
 
-	userId, ok := helper.GetSSO(c) // baca cookie sso_session
-	if !ok {
-		config.Rdb.Set(c, "return_to", c.Request.URL.RequestURI(), 0)
-
-		u := url.URL{Path: "/login"}
-		q := u.Query()
-		q.Set("return_to", c.Request.URL.RequestURI())
-		u.RawQuery = q.Encode()
-		c.Redirect(http.StatusSeeOther, u.String())
+	if _, ok := helper.GetSSO(c); !ok { // baca cookie sso_session
+		state, err := h.beginLogin(c, c.Request.URL.RequestURI(), &req)
+		if err != nil {
+			helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to start login", err)
+			return
+		}
+		helper.RedirectToLogin(c, state, "")
 		return
 	}
 
-	dataCookie, err := config.Rdb.Get(c, "sso:"+userId).Result()
-	if err != nil {
+	sessionID, user, ok := helper.GetSSOUser(c)
+	if !ok {
 		helper.ClearSSO(c)
 		helper.RedirectBackToLogin(c, "/login", c.Request.URL.RequestURI(), "",
 			"SSO session expired or invalid")
 		return
 	}
+	h.userSessionUseCase.Touch(sessionID)
 
-	var user dto.LoginResponseDTO
-	err = json.Unmarshal([]byte(dataCookie), &user)
+	var oauthErr *usecase.OauthError
+	if err := h.oauthUseCase.ValidateAuthorizeRequest(&req, client); err != nil {
+		if errors.As(err, &oauthErr) {
+			redirectOauthError(c, req.RedirectURI, req.State, oauthErr)
+			return
+		}
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to validate authorization request", err)
+		return
+	}
+
+	granted, err := h.oauthUseCase.HasConsent(user.User.ID, client.ID, req.Scope)
 	if err != nil {
-		helper.ClearSSO(c)
-		helper.RedirectBackToLogin(c, "/login", c.Request.URL.RequestURI(), "",
-			"Failed to parse user data")
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to check consent", err)
 		return
 	}
 
-	data, err := h.oauthUseCase.Authorize(clientID, redirectURI, responseType, &user)
+	if !granted {
+		token, _ := c.Get("csrf_token")
+		scopes := make([]string, 0)
+		for _, scope := range strings.Fields(req.Scope) {
+			scopes = append(scopes, constants.OauthScopeDescriptions[scope])
+		}
+
+		c.Header("Cache-Control", "no-store")
+		c.HTML(http.StatusOK, "auth/consent.tmpl", gin.H{
+			"client_name": client.Name,
+			"user_email":  user.User.Email,
+			"scopes":      scopes,
+			"csrf_token":  token,
+			"request":     req,
+		})
+		return
+	}
+
+	data, err := h.oauthUseCase.Authorize(req, client, user, sessionID)
+	if errors.As(err, &oauthErr) {
+		redirectOauthError(c, req.RedirectURI, req.State, oauthErr)
+		return
+	}
 	if err != nil {
 		helper.ClearSSO(c)
 		helper.RedirectBackToLogin(c, "/login", c.Request.URL.RequestURI(), "",
@@ -82,7 +118,7 @@ func (h *OauthHandler) Authorize(c *gin.Context) {
 		return
 	}
 
-	u, err := url.Parse(redirectURI)
+	u, err := url.Parse(req.RedirectURI)
 	if err != nil {
 		helper.ClearSSO(c)
 		helper.RedirectBackToLogin(c, "/login", c.Request.URL.RequestURI(), "",
@@ -92,70 +128,182 @@ func (h *OauthHandler) Authorize(c *gin.Context) {
 
 	q := u.Query()
 	q.Set("code", data.Code)
+	if req.State != "" {
+		q.Set("state", req.State)
+	}
 	u.RawQuery = q.Encode()
 	c.Redirect(http.StatusSeeOther, u.String())
 }
 
+// ConsentPost menyimpan keputusan user di halaman consent lalu melanjutkan
+// alur authorize dengan parameter yang sama.
+func (h *OauthHandler) ConsentPost(c *gin.Context) {
+	var form dto.OauthConsentRequestDTO
+	if err := c.ShouldBind(&form); err != nil {
+		helper.ErrorResponse(c, http.StatusBadRequest, "Invalid consent request", err)
+		return
+	}
+
+	if !helper.ValidateCSRF(c) {
+		helper.ErrorResponse(c, http.StatusForbidden, "Invalid CSRF token", nil)
+		return
+	}
+
+	client, err := h.useCase.FindByID(form.ClientID)
+	if err != nil || !client.HasRedirectURI(form.RedirectURI) {
+		helper.ErrorResponse(c, http.StatusBadRequest, "Invalid client or redirect URI", nil)
+		return
+	}
+
+	_, user, ok := helper.GetSSOUser(c)
+	if !ok {
+		helper.ErrorResponse(c, http.StatusUnauthorized, "SSO session expired or invalid", nil)
+		return
+	}
+
+	req := form.OauthAuthorizeRequestDTO
+	if form.Decision == "deny" {
+		redirectOauthError(c, req.RedirectURI, req.State, &usecase.OauthError{
+			Code:        "access_denied",
+			Description: "The user denied the request",
+		})
+		return
+	}
+
+	var oauthErr *usecase.OauthError
+	if err := h.oauthUseCase.ValidateAuthorizeRequest(&req, client); err != nil {
+		if errors.As(err, &oauthErr) {
+			redirectOauthError(c, req.RedirectURI, req.State, oauthErr)
+			return
+		}
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to validate authorization request", err)
+		return
+	}
+
+	if err := h.oauthUseCase.GrantConsent(user.User.ID, client.ID, req.Scope); err != nil {
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to save consent", err)
+		return
+	}
+
+	q := url.Values{}
+	q.Set("client_id", req.ClientID)
+	q.Set("redirect_uri", req.RedirectURI)
+	q.Set("response_type", req.ResponseType)
+	q.Set("scope", req.Scope)
+	for key, value := range map[string]string{
+		"state":                 req.State,
+		"nonce":                 req.Nonce,
+		"code_challenge":        req.CodeChallenge,
+		"code_challenge_method": req.CodeChallengeMethod,
+	} {
+		if value != "" {
+			q.Set(key, value)
+		}
+	}
+
+	c.Redirect(http.StatusSeeOther, "/api/v1/oauth/authorize?"+q.Encode())
+}
+
 func (h *OauthHandler) Token(c *gin.Context) {
-	clientID := c.PostForm("client_id")
-	clientSecret := c.PostForm("client_secret")
-	redirectURI := c.PostForm("redirect_uri")
-	code := c.PostForm("code")
+	var req dto.OauthTokenRequestDTO
+	if err := c.ShouldBind(&req); err != nil {
+		helper.OauthErrorResponse(c, http.StatusBadRequest, "invalid_request", "Invalid request parameters")
+		return
+	}
 
-	if clientID == "" || clientSecret == "" || redirectURI == "" || code == "" {
-		helper.ErrorResponse(c, http.StatusBadRequest, "Invalid request parameters", nil)
+	client, ok := h.authenticateClient(c, req.ClientID, req.ClientSecret)
+	if !ok {
 		return
 	}
 
-	client, err := h.useCase.FindByID(clientID)
+	res, err := h.oauthUseCase.Exchange(req, client)
 	if err != nil {
-		helper.ErrorResponse(c, http.StatusBadRequest, "Invalid client or client secret", err)
+		oauthErrorFromUseCase(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+	c.JSON(http.StatusOK, res)
+}
+
+// Introspect mengimplementasikan RFC 7662 untuk resource server milik tim lain.
+func (h *OauthHandler) Introspect(c *gin.Context) {
+	var req dto.OauthTokenIntrospectRequestDTO
+	if err := c.ShouldBind(&req); err != nil {
+		helper.OauthErrorResponse(c, http.StatusBadRequest, "invalid_request", "token is required")
 		return
 	}
 
-	if client.Redirect != redirectURI {
-		helper.ErrorResponse(c, http.StatusBadRequest, "Invalid redirect URI", nil)
+	client, ok := h.authenticateClient(c, req.ClientID, req.ClientSecret)
+	if !ok {
 		return
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(client.Secret), []byte(clientSecret))
-	if err != nil {
-		helper.ErrorResponse(c, http.StatusUnauthorized, "Invalid client", err)
+	// Public client tidak punya secret sehingga tidak boleh memeriksa token
+	if client.IsPublic {
+		helper.OauthErrorResponse(c, http.StatusUnauthorized, "invalid_client", "Public clients cannot introspect tokens")
 		return
 	}
 
-	val, err := config.Rdb.Get(c, code).Result()
-	if err != nil {
-		helper.ErrorResponse(c, http.StatusBadRequest, "Invalid authorization code", err)
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, h.tokenUseCase.Introspect(req.Token, req.TokenTypeHint))
+}
+
+// Revoke mengimplementasikan RFC 7009. Respons selalu 200 meskipun token tidak dikenal.
+func (h *OauthHandler) Revoke(c *gin.Context) {
+	var req dto.OauthTokenIntrospectRequestDTO
+	if err := c.ShouldBind(&req); err != nil {
+		helper.OauthErrorResponse(c, http.StatusBadRequest, "invalid_request", "token is required")
 		return
 	}
 
-	var ac dto.StoreOauthCodeDTO
-	if err := json.Unmarshal([]byte(val), &ac); err != nil {
-		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to parse authorization code data", err)
+	client, ok := h.authenticateClient(c, req.ClientID, req.ClientSecret)
+	if !ok {
 		return
 	}
 
-	if time.Now().After(ac.ExpiresAt) || ac.ClientID != client.ID {
-		helper.ErrorResponse(c, http.StatusBadRequest, "Invalid grant", nil)
+	if req.TokenTypeHint != "" && req.TokenTypeHint != "access_token" && req.TokenTypeHint != "refresh_token" {
+		helper.OauthErrorResponse(c, http.StatusBadRequest, "unsupported_token_type", "token_type_hint is not supported")
 		return
 	}
 
-	if ac.RedirectURI != redirectURI {
-		helper.ErrorResponse(c, http.StatusBadRequest, "Invalid redirect URI", nil)
+	if err := h.tokenUseCase.Revoke(req.Token, req.TokenTypeHint, client.ID); err != nil {
+		helper.OauthErrorResponse(c, http.StatusServiceUnavailable, "temporarily_unavailable", "Failed to revoke the token")
 		return
 	}
 
-	if ac.Code != code {
-		helper.ErrorResponse(c, http.StatusBadRequest, "Invalid authorization code", nil)
+	c.Status(http.StatusOK)
+}
+
+func (h *OauthHandler) UserInfo(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		helper.OauthErrorResponse(c, http.StatusUnauthorized, "invalid_token", "Access token is missing the subject")
 		return
 	}
 
-	helper.SuccessResponse(c, http.StatusOK, "Token generated successfully", ac.UserSub)
+	scope := c.GetString("scope")
+	info, err := h.oauthUseCase.UserInfo(userID.(string), c.GetString("session_id"), scope)
+	if err != nil {
+		helper.OauthErrorResponse(c, http.StatusUnauthorized, "invalid_token", "User not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, info)
+}
+
+func (h *OauthHandler) OpenIDConfiguration(c *gin.Context) {
+	c.JSON(http.StatusOK, h.oidcService.Discovery())
+}
+
+func (h *OauthHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.oidcService.JWKS())
 }
 
 func (h *OauthHandler) LoginPage(c *gin.Context) {
-	returnTo := c.Query("return_to")
+	state := c.Query("state")
 	token, _ := c.Get("csrf_token")
 	var errMsg string
 	if enc := c.Query("error"); enc != "" {
@@ -164,29 +312,39 @@ func (h *OauthHandler) LoginPage(c *gin.Context) {
 		}
 	}
 
+	// Login yang datang dengan return_to (bukan state) dibungkus dulu ke transaksi baru
+	if state == "" {
+		newState, err := h.beginLogin(c, h.safeReturnTo(c.Query("return_to")), nil)
+		if err != nil {
+			helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to start login", err)
+			return
+		}
+		helper.RedirectToLogin(c, newState, errMsg)
+		return
+	}
+
 	if _, err := c.Cookie(helper.CookieName); err == nil {
-		c.Redirect(http.StatusSeeOther, returnTo)
+		c.Redirect(http.StatusSeeOther, h.completeLogin(c, state))
 		return
 	}
 
 	c.HTML(http.StatusOK, "auth/login.tmpl", gin.H{
-		"return_to":  returnTo,
+		"state":      state,
 		"csrf_token": token,
 		"error":      errMsg,
+		"providers":  h.externalUseCase.Providers(),
 	})
 }
 
 func (h *OauthHandler) LoginPost(c *gin.Context) {
 	var form dto.LoginRequestFormDTO
 	if err := c.ShouldBind(&form); err != nil {
-		helper.RedirectBackToLogin(c, "/login", form.ReturnTo, "",
-			"Invalid form data")
+		helper.RedirectToLogin(c, form.State, "Invalid form data")
 		return
 	}
 
 	if !helper.ValidateCSRF(c) {
-		helper.RedirectBackToLogin(c, "/login", form.ReturnTo, "",
-			"Invalid CSRF token")
+		helper.RedirectToLogin(c, form.State, "Invalid CSRF token")
 		return
 	}
 
@@ -197,60 +355,78 @@ func (h *OauthHandler) LoginPost(c *gin.Context) {
 
 	user, err := h.authUseCase.Login(jsonReq)
 	if err != nil {
-		helper.RedirectBackToLogin(c, "/login", form.ReturnTo, "",
-			"Login failed: "+err.Error())
+		helper.RedirectToLogin(c, form.State, "Login failed: "+err.Error())
 		return
 	}
 
-	c.Header("Cache-Control", "no-store")
-	c.Header("Pragma", "no-cache")
-	helper.SetSSO(c, user, config.AppConfig.JWTExpirationHours*3600) // simpan cookie SSO selama JWTExpirationHours
+	if user.ChallengeToken != "" {
+		h.renderTwoFactor(c, user.ChallengeToken, form.State, user.ChallengeMethods, "")
+		return
+	}
 
-	c.Redirect(http.StatusSeeOther, form.ReturnTo)
+	h.finishWebLogin(c, user, form.State)
 }
 
-func (h *OauthHandler) LoginCallback(c *gin.Context) {
-	token := c.Query("token")
-	userData := c.Query("user")
-	errorMsg := c.Query("error")
-
-	if token == "" || userData == "" {
-		helper.ClearSSO(c)
-		helper.RedirectBackToLogin(c, "/login", c.Request.URL.RequestURI(), "",
-			"Missing token or user data")
+// TwoFactorPost menyelesaikan login web yang menunggu kode 2FA.
+func (h *OauthHandler) TwoFactorPost(c *gin.Context) {
+	var form dto.TwoFactorLoginFormDTO
+	if err := c.ShouldBind(&form); err != nil {
+		helper.RedirectToLogin(c, form.State, "Invalid form data")
 		return
 	}
 
-	if errorMsg != "" {
-		helper.ClearSSO(c)
-		helper.RedirectBackToLogin(c, "/login", c.Request.URL.RequestURI(), "",
-			errorMsg)
+	if !helper.ValidateCSRF(c) {
+		helper.RedirectToLogin(c, form.State, "Invalid CSRF token")
 		return
 	}
 
-	var user dto.LoginResponseDTO
-	decodedUserData, err := base64.RawURLEncoding.DecodeString(userData)
+	user, err := h.authUseCase.VerifyTwoFactor(dto.TwoFactorLoginRequestDTO{
+		ChallengeToken: form.ChallengeToken,
+		Code:           form.Code,
+	})
+	if errors.Is(err, usecase.ErrInvalidTwoFactorCode) {
+		h.renderTwoFactor(c, form.ChallengeToken, form.State, strings.Split(form.Methods, ","), err.Error())
+		return
+	}
 	if err != nil {
+		helper.RedirectToLogin(c, form.State, "Login failed: "+err.Error())
+		return
+	}
+
+	h.finishWebLogin(c, user, form.State)
+}
+
+// LoginCallback menyelesaikan login IdP eksternal di halaman login web. Hasil
+// login diambil dari code sekali pakai yang terikat ke cookie browser login,
+// lalu melewati pemeriksaan restriction yang sama dengan login password.
+func (h *OauthHandler) LoginCallback(c *gin.Context) {
+	state := c.Query("state")
+
+	// Challenge token diverifikasi ulang oleh server saat kode 2FA dikirim
+	if challengeToken := c.Query("challenge_token"); challengeToken != "" {
+		h.renderTwoFactor(c, challengeToken, state, strings.Split(c.Query("methods"), ","), "")
+		return
+	}
+
+	if enc := c.Query("error"); enc != "" {
+		errMsg := "Login failed"
+		if b, err := base64.RawURLEncoding.DecodeString(enc); err == nil {
+			errMsg = string(b)
+		}
 		helper.ClearSSO(c)
-		helper.RedirectBackToLogin(c, "/login", c.Request.URL.RequestURI(), "",
-			"Failed to decode user data")
+		helper.RedirectToLogin(c, state, errMsg)
 		return
 	}
 
-	err = json.Unmarshal(decodedUserData, &user.User)
+	browserID, _ := helper.GetLoginBrowserID(c)
+	user, err := h.loginTxUseCase.ConsumeResult(c.Query("code"), browserID)
 	if err != nil {
 		helper.ClearSSO(c)
-		helper.RedirectBackToLogin(c, "/login", c.Request.URL.RequestURI(), "",
-			"Failed to parse user data")
+		helper.RedirectToLogin(c, state, err.Error())
 		return
 	}
 
-	user.Token = token
-
-	helper.SetSSO(c, &user, config.AppConfig.JWTExpirationHours*3600) // simpan cookie SSO selama JWTExpirationHours
-	return_to, _ := config.Rdb.Get(c, "return_to").Result()
-	config.Rdb.Del(c, "return_to")
-	c.Redirect(http.StatusSeeOther, return_to)
+	h.finishWebLogin(c, user, state)
 }
 
 func (h *OauthHandler) IndexPage(c *gin.Context) {
@@ -258,7 +434,258 @@ func (h *OauthHandler) IndexPage(c *gin.Context) {
 }
 
 func (h *OauthHandler) Logout(c *gin.Context) {
-	helper.ClearSSO(c)
 	redirectTo := c.Query("redirect")
-	c.Redirect(http.StatusSeeOther, redirectTo)
+	if !h.useCase.IsAllowedReturnTo(redirectTo) {
+		redirectTo = "/login"
+	}
+
+	var frontchannelURIs []string
+	if sessionID, user, ok := helper.GetSSOUser(c); ok {
+		uris, err := h.userSessionUseCase.Revoke(user.User.ID, sessionID)
+		if errors.Is(err, usecase.ErrUserSessionNotFound) {
+			// Sesi lama yang belum tercatat di registry tetap harus diakhiri
+			uris, err = h.oauthUseCase.Logout(sessionID)
+		}
+		if err != nil {
+			helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to end SSO session", err)
+			return
+		}
+		frontchannelURIs = uris
+	}
+	helper.ClearSSO(c)
+
+	if len(frontchannelURIs) == 0 {
+		c.Redirect(http.StatusSeeOther, redirectTo)
+		return
+	}
+
+	// Halaman ini memuat logout URI setiap client di iframe lalu lanjut ke redirectTo
+	c.Header("Cache-Control", "no-store")
+	c.HTML(http.StatusOK, "auth/logout.tmpl", gin.H{
+		"frontchannel_uris": frontchannelURIs,
+		"redirect_to":       redirectTo,
+	})
+}
+
+// PasskeyBegin memulai login passwordless dari halaman login. Ceremony diikat ke
+// cookie browser login sehingga hasilnya tidak bisa dikirim dari browser lain.
+func (h *OauthHandler) PasskeyBegin(c *gin.Context) {
+	var req dto.WebAuthnLoginBeginRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	browserID := helper.LoginBrowserID(c, int(usecase.LoginTransactionTTL.Seconds()))
+	res, err := h.webAuthnUseCase.BeginLogin(req.Email, browserID)
+	if err != nil {
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to start passkey login", err)
+		return
+	}
+
+	helper.SuccessResponse(c, http.StatusOK, "Passkey login started", res)
+}
+
+func (h *OauthHandler) PasskeyLogin(c *gin.Context) {
+	var req dto.WebAuthnLoginFinishRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	req.BrowserID, _ = helper.GetLoginBrowserID(c)
+	user, err := h.authUseCase.LoginWithPasskey(req)
+	if err != nil {
+		helper.ErrorResponse(c, http.StatusUnauthorized, err.Error(), err)
+		return
+	}
+
+	h.respondWebLogin(c, user, req.State)
+}
+
+// TwoFactorPasskeyBegin memulai ceremony passkey dari halaman verifikasi dua langkah.
+func (h *OauthHandler) TwoFactorPasskeyBegin(c *gin.Context) {
+	var req dto.WebAuthnSecondFactorBeginRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	browserID := helper.LoginBrowserID(c, int(usecase.LoginTransactionTTL.Seconds()))
+	res, err := h.webAuthnUseCase.BeginSecondFactor(req.ChallengeToken, browserID)
+	if err != nil {
+		helper.ErrorResponse(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	helper.SuccessResponse(c, http.StatusOK, "Passkey verification started", res)
+}
+
+func (h *OauthHandler) TwoFactorPasskey(c *gin.Context) {
+	var req dto.WebAuthnSecondFactorFinishRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	req.BrowserID, _ = helper.GetLoginBrowserID(c)
+	user, err := h.authUseCase.VerifyTwoFactorPasskey(req)
+	if err != nil {
+		helper.ErrorResponse(c, http.StatusUnauthorized, err.Error(), err)
+		return
+	}
+
+	h.respondWebLogin(c, user, req.State)
+}
+
+// startWebSession mencatat perangkat di registry sesi lalu memasang cookie SSO.
+func (h *OauthHandler) startWebSession(c *gin.Context, user *dto.LoginResponseDTO) error {
+	session, err := h.userSessionUseCase.Start(user.User.ID, domain.UserSessionTypeWeb, user.SessionID, helper.SessionMeta(c))
+	if err != nil {
+		return err
+	}
+
+	helper.SetSSO(c, session.ID, user, config.AppConfig.JWTExpirationHours*3600) // simpan cookie SSO selama JWTExpirationHours
+	return nil
+}
+
+func (h *OauthHandler) renderTwoFactor(c *gin.Context, challengeToken, state string, methods []string, errMsg string) {
+	token, _ := c.Get("csrf_token")
+	passkey := slices.Contains(methods, constants.TwoFactorMethodPasskey)
+	c.Header("Cache-Control", "no-store")
+	c.HTML(http.StatusOK, "auth/two_factor.tmpl", gin.H{
+		"challenge_token": challengeToken,
+		"state":           state,
+		"csrf_token":      token,
+		"methods":         strings.Join(methods, ","),
+		// Tanpa informasi metode, tampilkan input kode sebagai default
+		"totp":    slices.Contains(methods, constants.TwoFactorMethodTOTP) || !passkey,
+		"passkey": passkey,
+		"error":   errMsg,
+	})
+}
+
+func (h *OauthHandler) finishWebLogin(c *gin.Context, user *dto.LoginResponseDTO, state string) {
+	target, err := h.startWebLogin(c, user, state)
+	if err != nil {
+		helper.RedirectToLogin(c, state, err.Error())
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, target)
+}
+
+// respondWebLogin dipakai endpoint passkey yang dipanggil lewat fetch, sehingga
+// tujuan redirect dikembalikan sebagai JSON.
+func (h *OauthHandler) respondWebLogin(c *gin.Context, user *dto.LoginResponseDTO, state string) {
+	target, err := h.startWebLogin(c, user, state)
+	if err != nil {
+		helper.ErrorResponse(c, http.StatusForbidden, err.Error(), err)
+		return
+	}
+
+	helper.SuccessResponse(c, http.StatusOK, "Login successful", gin.H{"redirect_to": target})
+}
+
+// startWebLogin memulai sesi SSO dan mengembalikan tujuan setelah login.
+func (h *OauthHandler) startWebLogin(c *gin.Context, user *dto.LoginResponseDTO, state string) (string, error) {
+	// Kewajiban akun (enroll 2FA, ganti password default) diselesaikan lewat
+	// aplikasi sebelum akun bisa memakai SSO
+	if len(user.Restrictions) > 0 {
+		_ = h.tokenUseCase.RevokeFamily(user.SessionID)
+		if slices.Contains(user.Restrictions, constants.RestrictionPasswordChange) {
+			return "", errors.New("you must change your default password in the app before using single sign-on")
+		}
+		return "", errors.New("two-factor authentication is required for your account, please enable it in the app first")
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+	// sesi web dipegang oleh cookie SSO, bukan refresh token
+	user.RefreshToken = ""
+	if err := h.startWebSession(c, user); err != nil {
+		return "", errors.New("failed to start session")
+	}
+
+	return h.completeLogin(c, state), nil
+}
+
+// beginLogin membuat transaksi login yang terikat ke cookie browser ini.
+func (h *OauthHandler) beginLogin(c *gin.Context, returnTo string, req *dto.OauthAuthorizeRequestDTO) (string, error) {
+	browserID := helper.LoginBrowserID(c, int(usecase.LoginTransactionTTL.Seconds()))
+	return h.loginTxUseCase.Begin(browserID, returnTo, req)
+}
+
+// completeLogin memakai transaksi login dan mengembalikan tujuan redirect-nya.
+// Transaksi yang tidak valid atau kedaluwarsa diarahkan ke halaman utama.
+func (h *OauthHandler) completeLogin(c *gin.Context, state string) string {
+	browserID, _ := helper.GetLoginBrowserID(c)
+	tx, err := h.loginTxUseCase.Complete(state, browserID)
+	if err != nil {
+		return "/"
+	}
+	return h.safeReturnTo(tx.ReturnTo)
+}
+
+// safeReturnTo mengganti return_to yang tidak terdaftar dengan halaman utama.
+func (h *OauthHandler) safeReturnTo(returnTo string) string {
+	if !h.useCase.IsAllowedReturnTo(returnTo) {
+		return "/"
+	}
+	return returnTo
+}
+
+// authenticateClient mengautentikasi client lewat client_secret_basic atau
+// client_secret_post dan langsung menulis respons error bila gagal.
+func (h *OauthHandler) authenticateClient(c *gin.Context, clientID, clientSecret string) (*domain.OauthClient, bool) {
+	// client_secret_basic lebih diutamakan daripada client_secret_post
+	if id, secret, ok := c.Request.BasicAuth(); ok {
+		clientID, clientSecret = id, secret
+	}
+
+	if clientID == "" {
+		helper.OauthErrorResponse(c, http.StatusUnauthorized, "invalid_client", "Client authentication is required")
+		return nil, false
+	}
+
+	client, err := h.useCase.Authenticate(clientID, clientSecret)
+	if err != nil {
+		helper.OauthErrorResponse(c, http.StatusUnauthorized, "invalid_client", "Invalid client or client secret")
+		return nil, false
+	}
+
+	return client, true
+}
+
+func oauthErrorFromUseCase(c *gin.Context, err error) {
+	var oauthErr *usecase.OauthError
+	if !errors.As(err, &oauthErr) {
+		helper.OauthErrorResponse(c, http.StatusInternalServerError, "server_error", "Failed to process the request")
+		return
+	}
+
+	status := http.StatusBadRequest
+	if oauthErr.Code == "invalid_client" {
+		status = http.StatusUnauthorized
+	}
+	helper.OauthErrorResponse(c, status, oauthErr.Code, oauthErr.Description)
+}
+
+// redirectOauthError mengembalikan error ke redirect_uri client (RFC 6749 section 4.1.2.1).
+// Hanya dipakai setelah client dan redirect_uri tervalidasi.
+func redirectOauthError(c *gin.Context, redirectURI, state string, oauthErr *usecase.OauthError) {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		helper.OauthErrorResponse(c, http.StatusBadRequest, oauthErr.Code, oauthErr.Description)
+		return
+	}
+
+	q := u.Query()
+	q.Set("error", oauthErr.Code)
+	q.Set("error_description", oauthErr.Description)
+	if state != "" {
+		q.Set("state", state)
+	}
+	u.RawQuery = q.Encode()
+	c.Redirect(http.StatusSeeOther, u.String())
 }
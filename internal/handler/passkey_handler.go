@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"errors"
+	"jti-super-app-go/internal/dto"
+	"jti-super-app-go/internal/service"
+	"jti-super-app-go/internal/usecase"
+	"jti-super-app-go/pkg/helper"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PasskeyHandler struct {
+	useCase usecase.WebAuthnUseCase
+}
+
+func NewPasskeyHandler(uc usecase.WebAuthnUseCase) *PasskeyHandler {
+	return &PasskeyHandler{useCase: uc}
+}
+
+func (h *PasskeyHandler) FindAll(c *gin.Context) {
+	passkeys, err := h.useCase.List(c.GetString("user_id"))
+	if err != nil {
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch passkeys", err)
+		return
+	}
+
+	helper.SuccessResponse(c, http.StatusOK, "Passkeys fetched successfully", passkeys)
+}
+
+func (h *PasskeyHandler) BeginRegistration(c *gin.Context) {
+	res, err := h.useCase.BeginRegistration(c.GetString("user_id"))
+	if err != nil {
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to start passkey registration", err)
+		return
+	}
+
+	helper.SuccessResponse(c, http.StatusOK, "Passkey registration started", res)
+}
+
+func (h *PasskeyHandler) FinishRegistration(c *gin.Context) {
+	var req dto.WebAuthnRegisterFinishRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	passkey, err := h.useCase.FinishRegistration(c.GetString("user_id"), req)
+	switch {
+	case errors.Is(err, service.ErrWebAuthnVerification), errors.Is(err, usecase.ErrInvalidWebAuthnSession):
+		helper.ErrorResponse(c, http.StatusUnprocessableEntity, err.Error(), err)
+		return
+	case errors.Is(err, usecase.ErrPasskeyAlreadyExists):
+		helper.ErrorResponse(c, http.StatusConflict, err.Error(), err)
+		return
+	case err != nil:
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to register passkey", err)
+		return
+	}
+
+	helper.SuccessResponse(c, http.StatusCreated, "Passkey registered successfully", passkey)
+}
+
+func (h *PasskeyHandler) Delete(c *gin.Context) {
+	err := h.useCase.Delete(c.GetString("user_id"), c.Param("id"))
+	if errors.Is(err, usecase.ErrPasskeyNotFound) {
+		helper.ErrorResponse(c, http.StatusNotFound, err.Error(), err)
+		return
+	}
+	if err != nil {
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete passkey", err)
+		return
+	}
+
+	helper.SuccessResponse(c, http.StatusOK, "Passkey deleted successfully", nil)
+}
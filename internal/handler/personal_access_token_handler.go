@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"errors"
+	"jti-super-app-go/internal/domain"
+	"jti-super-app-go/internal/dto"
+	"jti-super-app-go/internal/usecase"
+	"jti-super-app-go/pkg/helper"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PersonalAccessTokenHandler struct {
+	useCase usecase.PersonalAccessTokenUseCase
+}
+
+func NewPersonalAccessTokenHandler(uc usecase.PersonalAccessTokenUseCase) *PersonalAccessTokenHandler {
+	return &PersonalAccessTokenHandler{useCase: uc}
+}
+
+func (h *PersonalAccessTokenHandler) FindAll(c *gin.Context) {
+	tokens, err := h.useCase.FindByUserID(c.GetString("user_id"))
+	if err != nil {
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch personal access tokens", err)
+		return
+	}
+
+	helper.SuccessResponse(c, http.StatusOK, "Personal access tokens fetched successfully", tokens)
+}
+
+func (h *PersonalAccessTokenHandler) Create(c *gin.Context) {
+	var req dto.StorePersonalAccessTokenDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ValidationErrorJSON(c, err)
+		return
+	}
+
+	token, err := h.useCase.Create(c.GetString("user_id"), callerPermissions(c), req)
+	switch {
+	case errors.Is(err, usecase.ErrPermissionNotHeld), errors.Is(err, usecase.ErrInvalidTokenExpiry), errors.Is(err, usecase.ErrPermissionNotDelegable):
+		helper.ErrorResponse(c, http.StatusUnprocessableEntity, err.Error(), err)
+		return
+	case err != nil:
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to create personal access token", err)
+		return
+	}
+
+	helper.SuccessResponse(c, http.StatusCreated, "Personal access token created successfully; copy it now, it will not be shown again", token)
+}
+
+// callerPermissions mengambil permission efektif token pemanggil yang sudah
+// diselesaikan middleware.
+func callerPermissions(c *gin.Context) *domain.PermissionSet {
+	set := &domain.PermissionSet{Permissions: c.GetStringSlice("permissions")}
+	if value, ok := c.Get("permission_scopes"); ok {
+		set.Scopes, _ = value.(map[string]dto.AccessScope)
+	}
+	return set
+}
+
+func (h *PersonalAccessTokenHandler) Delete(c *gin.Context) {
+	err := h.useCase.Revoke(c.GetString("user_id"), c.Param("id"))
+	if errors.Is(err, usecase.ErrPersonalAccessTokenNotFound) {
+		helper.ErrorResponse(c, http.StatusNotFound, err.Error(), err)
+		return
+	}
+	if err != nil {
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to revoke personal access token", err)
+		return
+	}
+
+	helper.SuccessResponse(c, http.StatusOK, "Personal access token revoked successfully", nil)
+}
@@ -27,8 +27,9 @@ func (h *RoleHandler) FindByID(c *gin.Context) {
 	}
 
 	roleResource := dto.RoleResource{
-		ID:   role.ID,
-		Name: role.Name,
+		ID:                role.ID,
+		Name:              role.Name,
+		RequiresTwoFactor: role.RequiresTwoFactor,
 	}
 	helper.SuccessResponse(c, http.StatusOK, "Role found", roleResource)
 }
@@ -56,9 +57,10 @@ func (h *RoleHandler) FindAll(c *gin.Context) {
 		}
 
 		roleResources = append(roleResources, dto.RoleResource{
-			ID:          role.ID,
-			Name:        role.Name,
-			Permissions: permissions,
+			ID:                role.ID,
+			Name:              role.Name,
+			RequiresTwoFactor: role.RequiresTwoFactor,
+			Permissions:       permissions,
 		})
 	}
 
@@ -90,8 +92,9 @@ func (h *RoleHandler) Create(c *gin.Context) {
 	}
 
 	roleResource := dto.RoleResource{
-		ID:   role.ID,
-		Name: role.Name,
+		ID:                role.ID,
+		Name:              role.Name,
+		RequiresTwoFactor: role.RequiresTwoFactor,
 	}
 	helper.SuccessResponse(c, http.StatusCreated, "Role created successfully", roleResource)
 }
@@ -119,8 +122,9 @@ func (h *RoleHandler) Update(c *gin.Context) {
 	}
 
 	roleResource := dto.RoleResource{
-		ID:   role.ID,
-		Name: role.Name,
+		ID:                role.ID,
+		Name:              role.Name,
+		RequiresTwoFactor: role.RequiresTwoFactor,
 	}
 	helper.SuccessResponse(c, http.StatusOK, "Role updated successfully", roleResource)
 }
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"errors"
+	"jti-super-app-go/internal/dto"
+	"jti-super-app-go/internal/usecase"
+	"jti-super-app-go/pkg/helper"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ScopedPermissionHandler struct {
+	useCase usecase.ScopedPermissionUseCase
+}
+
+func NewScopedPermissionHandler(uc usecase.ScopedPermissionUseCase) *ScopedPermissionHandler {
+	return &ScopedPermissionHandler{useCase: uc}
+}
+
+func (h *ScopedPermissionHandler) FindAll(c *gin.Context) {
+	grants, err := h.useCase.FindByUserID(c.Param("id"))
+	if err != nil {
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch scoped permissions", err)
+		return
+	}
+
+	helper.SuccessResponse(c, http.StatusOK, "Scoped permissions fetched successfully", grants)
+}
+
+func (h *ScopedPermissionHandler) Create(c *gin.Context) {
+	var req dto.StoreScopedPermissionDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ValidationErrorJSON(c, err)
+		return
+	}
+
+	grant, err := h.useCase.Grant(c.Param("id"), req)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		helper.ErrorResponse(c, http.StatusNotFound, "User or permission not found", err)
+		return
+	case errors.Is(err, usecase.ErrPermissionScopeNotFound), errors.Is(err, usecase.ErrPermissionNotScopable):
+		helper.ErrorResponse(c, http.StatusUnprocessableEntity, err.Error(), err)
+		return
+	case err != nil && strings.Contains(err.Error(), "Duplicate entry"):
+		helper.ErrorResponse(c, http.StatusConflict, "Permission is already granted for this scope", err)
+		return
+	case err != nil:
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to grant scoped permission", err)
+		return
+	}
+
+	helper.SuccessResponse(c, http.StatusCreated, "Scoped permission granted successfully", grant)
+}
+
+func (h *ScopedPermissionHandler) Delete(c *gin.Context) {
+	err := h.useCase.Revoke(c.Param("id"), c.Param("grantId"))
+	if errors.Is(err, usecase.ErrScopedPermissionNotFound) {
+		helper.ErrorResponse(c, http.StatusNotFound, err.Error(), err)
+		return
+	}
+	if err != nil {
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to revoke scoped permission", err)
+		return
+	}
+
+	helper.SuccessResponse(c, http.StatusOK, "Scoped permission revoked successfully", nil)
+}
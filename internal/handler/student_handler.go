@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"jti-super-app-go/internal/dto"
 	"jti-super-app-go/internal/usecase"
 	"jti-super-app-go/pkg/helper"
@@ -11,11 +12,12 @@ import (
 )
 
 type StudentHandler struct {
-	useCase usecase.StudentUseCase
+	useCase             usecase.StudentUseCase
+	verificationUseCase usecase.EmailVerificationUseCase
 }
 
-func NewStudentHandler(uc usecase.StudentUseCase) *StudentHandler {
-	return &StudentHandler{useCase: uc}
+func NewStudentHandler(uc usecase.StudentUseCase, ev usecase.EmailVerificationUseCase) *StudentHandler {
+	return &StudentHandler{useCase: uc, verificationUseCase: ev}
 }
 
 func (h *StudentHandler) FindAll(c *gin.Context) {
@@ -79,7 +81,16 @@ func (h *StudentHandler) Create(c *gin.Context) {
 		return
 	}
 
-	student, err := h.useCase.Create(&payload)
+	student, err := h.useCase.Create(&payload, helper.AccessScope(c))
+	if errors.Is(err, usecase.ErrOutsideAccessScope) {
+		helper.ErrorResponse(c, http.StatusForbidden, err.Error(), err)
+		return
+	}
+	var policyErr *usecase.PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		helper.PasswordPolicyErrorResponse(c, policyErr.Violations)
+		return
+	}
 	if err != nil {
 		if strings.Contains(err.Error(), "Duplicate entry") {
 			helper.ErrorResponse(c, http.StatusConflict, "Student with this NIM already exists", err)
@@ -99,8 +110,12 @@ func (h *StudentHandler) FindByID(c *gin.Context) {
 		return
 	}
 
-	student, err := h.useCase.FindByID(id)
+	student, err := h.useCase.FindByID(id, helper.AccessScope(c))
 	if err != nil {
+		if errors.Is(err, usecase.ErrOutsideAccessScope) {
+			helper.ErrorResponse(c, http.StatusForbidden, err.Error(), err)
+			return
+		}
 		if strings.Contains(err.Error(), "record not found") {
 			helper.ErrorResponse(c, http.StatusNotFound, "Student not found", nil)
 			return
@@ -153,3 +168,25 @@ func (h *StudentHandler) FindByID(c *gin.Context) {
 
 	helper.SuccessResponse(c, http.StatusOK, "Student fetched successfully", resource)
 }
+
+// SendVerificationEmails mengirim ulang email verifikasi ke seluruh mahasiswa
+// satu angkatan yang belum terverifikasi. Pengiriman berjalan di background.
+func (h *StudentHandler) SendVerificationEmails(c *gin.Context) {
+	var req dto.CohortVerificationRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ValidationErrorJSON(c, err)
+		return
+	}
+
+	queued, err := h.verificationUseCase.SendToCohort(req, helper.AccessScope(c))
+	if err != nil {
+		if errors.Is(err, usecase.ErrOutsideAccessScope) {
+			helper.ErrorResponse(c, http.StatusForbidden, err.Error(), err)
+			return
+		}
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to queue verification emails", err)
+		return
+	}
+
+	helper.SuccessResponse(c, http.StatusAccepted, "Verification emails are being sent", dto.CohortVerificationResource{Queued: queued})
+}
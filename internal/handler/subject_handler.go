@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"jti-super-app-go/internal/dto"
 	"jti-super-app-go/internal/usecase"
@@ -61,8 +62,12 @@ func (h *SubjectHandler) Create(c *gin.Context) {
 		return
 	}
 
-	subject, err := h.useCase.Create(&payload)
+	subject, err := h.useCase.Create(&payload, helper.AccessScope(c))
 	if err != nil {
+		if errors.Is(err, usecase.ErrOutsideAccessScope) {
+			helper.ErrorResponse(c, http.StatusForbidden, err.Error(), err)
+			return
+		}
 		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to create subject", err)
 		return
 	}
@@ -82,8 +87,12 @@ func (h *SubjectHandler) Update(c *gin.Context) {
 		return
 	}
 
-	subject, err := h.useCase.Update(subjectID, &payload)
+	subject, err := h.useCase.Update(subjectID, &payload, helper.AccessScope(c))
 	if err != nil {
+		if errors.Is(err, usecase.ErrOutsideAccessScope) {
+			helper.ErrorResponse(c, http.StatusForbidden, err.Error(), err)
+			return
+		}
 		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to update subject", err)
 		return
 	}
@@ -97,8 +106,12 @@ func (h *SubjectHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	err := h.useCase.Delete(subjectID)
+	err := h.useCase.Delete(subjectID, helper.AccessScope(c))
 	if err != nil {
+		if errors.Is(err, usecase.ErrOutsideAccessScope) {
+			helper.ErrorResponse(c, http.StatusForbidden, err.Error(), err)
+			return
+		}
 		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete subject", err)
 		return
 	}
@@ -114,8 +127,12 @@ func (h *SubjectHandler) GetLectureOnSubject(c *gin.Context) {
 		return
 	}
 
-	data, err := h.useCase.GetLectureOnSubject(studyProgramID, semesterID)
+	data, err := h.useCase.GetLectureOnSubject(studyProgramID, semesterID, helper.AccessScope(c))
 	if err != nil {
+		if errors.Is(err, usecase.ErrOutsideAccessScope) {
+			helper.ErrorResponse(c, http.StatusForbidden, err.Error(), err)
+			return
+		}
 		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch lecture on subject data", err)
 		return
 	}
@@ -170,8 +187,12 @@ func (h *SubjectHandler) StoreLectureOnSubject(c *gin.Context) {
 		helper.ValidationErrorJSON(c, err)
 		return
 	}
-	err := h.useCase.StoreLectureOnSubject(payload.Data)
+	err := h.useCase.StoreLectureOnSubject(payload.Data, helper.AccessScope(c))
 	if err != nil {
+		if errors.Is(err, usecase.ErrOutsideAccessScope) {
+			helper.ErrorResponse(c, http.StatusForbidden, err.Error(), err)
+			return
+		}
 		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to store lecture on subject", err)
 		return
 	}
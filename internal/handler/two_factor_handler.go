@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"errors"
+	"jti-super-app-go/internal/dto"
+	"jti-super-app-go/internal/usecase"
+	"jti-super-app-go/pkg/helper"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TwoFactorHandler struct {
+	useCase usecase.TwoFactorUseCase
+}
+
+func NewTwoFactorHandler(uc usecase.TwoFactorUseCase) *TwoFactorHandler {
+	return &TwoFactorHandler{useCase: uc}
+}
+
+func (h *TwoFactorHandler) Status(c *gin.Context) {
+	status, err := h.useCase.Status(c.GetString("user_id"))
+	if err != nil {
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch two-factor status", err)
+		return
+	}
+
+	helper.SuccessResponse(c, http.StatusOK, "Two-factor status fetched successfully", status)
+}
+
+// Setup mengembalikan secret dan URI otpauth:// untuk dipindai sebagai QR code.
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	res, err := h.useCase.Setup(c.GetString("user_id"))
+	if err != nil {
+		h.handleError(c, "Failed to start two-factor setup", err)
+		return
+	}
+
+	helper.SuccessResponse(c, http.StatusOK, "Scan the QR code with your authenticator app, then confirm with a code", res)
+}
+
+func (h *TwoFactorHandler) Confirm(c *gin.Context) {
+	var req dto.TwoFactorCodeRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	codes, err := h.useCase.Confirm(c.GetString("user_id"), req.Code)
+	if err != nil {
+		h.handleError(c, "Failed to enable two-factor authentication", err)
+		return
+	}
+
+	helper.SuccessResponse(c, http.StatusOK, "Two-factor authentication enabled, store these recovery codes safely", dto.TwoFactorRecoveryCodesResource{RecoveryCodes: codes})
+}
+
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	var req dto.TwoFactorCodeRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if err := h.useCase.Disable(c.GetString("user_id"), req.Code); err != nil {
+		h.handleError(c, "Failed to disable two-factor authentication", err)
+		return
+	}
+
+	helper.SuccessResponse(c, http.StatusOK, "Two-factor authentication disabled", nil)
+}
+
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req dto.TwoFactorCodeRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	codes, err := h.useCase.RegenerateRecoveryCodes(c.GetString("user_id"), req.Code)
+	if err != nil {
+		h.handleError(c, "Failed to regenerate recovery codes", err)
+		return
+	}
+
+	helper.SuccessResponse(c, http.StatusOK, "Recovery codes regenerated successfully", dto.TwoFactorRecoveryCodesResource{RecoveryCodes: codes})
+}
+
+func (h *TwoFactorHandler) handleError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidTwoFactorCode):
+		helper.ErrorResponse(c, http.StatusUnprocessableEntity, err.Error(), err)
+	case errors.Is(err, usecase.ErrTwoFactorAlreadyEnabled),
+		errors.Is(err, usecase.ErrTwoFactorNotEnabled),
+		errors.Is(err, usecase.ErrTwoFactorSetupNotStarted):
+		helper.ErrorResponse(c, http.StatusConflict, err.Error(), err)
+	case errors.Is(err, usecase.ErrTwoFactorRequiredByRole):
+		helper.ErrorResponse(c, http.StatusForbidden, err.Error(), err)
+	default:
+		helper.ErrorResponse(c, http.StatusInternalServerError, message, err)
+	}
+}
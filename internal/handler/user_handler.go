@@ -10,11 +10,12 @@ import (
 )
 
 type UserHandler struct {
-	useCase usecase.UserUseCase
+	useCase            usecase.UserUseCase
+	userSessionUseCase usecase.UserSessionUseCase
 }
 
-func NewUserHandler(uc usecase.UserUseCase) *UserHandler {
-	return &UserHandler{useCase: uc}
+func NewUserHandler(uc usecase.UserUseCase, sc usecase.UserSessionUseCase) *UserHandler {
+	return &UserHandler{useCase: uc, userSessionUseCase: sc}
 }
 
 func (h *UserHandler) FindAll(c *gin.Context) {
@@ -72,3 +73,15 @@ func (h *UserHandler) UpdateRoles(c *gin.Context) {
 
 	helper.SuccessResponse(c, http.StatusOK, "User roles updated successfully", nil)
 }
+
+// RevokeSessions mengakhiri semua sesi milik user, misalnya saat akunnya disusupi.
+func (h *UserHandler) RevokeSessions(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.userSessionUseCase.RevokeAll(id); err != nil {
+		helper.ErrorResponse(c, http.StatusInternalServerError, "Failed to revoke user sessions", err)
+		return
+	}
+
+	helper.SuccessResponse(c, http.StatusOK, "All user sessions revoked successfully", nil)
+}
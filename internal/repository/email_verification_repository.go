@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"jti-super-app-go/internal/domain"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	emailVerificationPrefix         = "verify_email:"
+	emailVerificationUserPrefix     = "verify_email_user:"
+	emailVerificationCooldownPrefix = "verify_email_cooldown:"
+	emailVerificationDailyPrefix    = "verify_email_daily:"
+)
+
+type emailVerificationRepository struct {
+	rdb *redis.Client
+}
+
+func NewEmailVerificationRepository(rdb *redis.Client) domain.EmailVerificationRepository {
+	return &emailVerificationRepository{rdb: rdb}
+}
+
+func (r *emailVerificationRepository) Create(userID, tokenHash string, ttl time.Duration) error {
+	ctx := context.Background()
+	userKey := emailVerificationUserPrefix + userID
+
+	previous, err := r.rdb.Get(ctx, userKey).Result()
+	if err != nil && err != redis.Nil {
+		return err
+	}
+
+	_, err = r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if previous != "" {
+			pipe.Del(ctx, emailVerificationPrefix+previous)
+		}
+		pipe.Set(ctx, emailVerificationPrefix+tokenHash, userID, ttl)
+		pipe.Set(ctx, userKey, tokenHash, ttl)
+		return nil
+	})
+	return err
+}
+
+func (r *emailVerificationRepository) Consume(tokenHash string) (string, error) {
+	ctx := context.Background()
+	userID, err := r.rdb.GetDel(ctx, emailVerificationPrefix+tokenHash).Result()
+	if err != nil {
+		return "", err
+	}
+
+	r.rdb.Del(ctx, emailVerificationUserPrefix+userID)
+	return userID, nil
+}
+
+func (r *emailVerificationRepository) Throttle(userID string, cooldown time.Duration, dailyLimit int) (time.Duration, error) {
+	ctx := context.Background()
+	cooldownKey := emailVerificationCooldownPrefix + userID
+	dailyKey := emailVerificationDailyPrefix + userID
+
+	if cooldown > 0 {
+		ok, err := r.rdb.SetNX(ctx, cooldownKey, 1, cooldown).Result()
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			return r.rdb.PTTL(ctx, cooldownKey).Result()
+		}
+	}
+
+	if dailyLimit > 0 {
+		sent, err := r.rdb.Incr(ctx, dailyKey).Result()
+		if err != nil {
+			return 0, err
+		}
+		if sent == 1 {
+			r.rdb.Expire(ctx, dailyKey, 24*time.Hour)
+		}
+		if sent > int64(dailyLimit) {
+			return r.rdb.PTTL(ctx, dailyKey).Result()
+		}
+	}
+
+	return 0, nil
+}
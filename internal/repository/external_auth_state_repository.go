@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"jti-super-app-go/internal/domain"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	externalAuthStatePrefix  = "idp_state:"
+	externalLinkTicketPrefix = "idp_link_ticket:"
+)
+
+type externalAuthStateRepository struct {
+	rdb *redis.Client
+}
+
+func NewExternalAuthStateRepository(rdb *redis.Client) domain.ExternalAuthStateRepository {
+	return &externalAuthStateRepository{rdb: rdb}
+}
+
+func (r *externalAuthStateRepository) Create(state *domain.ExternalAuthState, ttl time.Duration) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	return r.rdb.Set(context.Background(), externalAuthStatePrefix+state.State, data, ttl).Err()
+}
+
+func (r *externalAuthStateRepository) Consume(state string) (*domain.ExternalAuthState, error) {
+	val, err := r.rdb.GetDel(context.Background(), externalAuthStatePrefix+state).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var authState domain.ExternalAuthState
+	if err := json.Unmarshal([]byte(val), &authState); err != nil {
+		return nil, err
+	}
+	return &authState, nil
+}
+
+func (r *externalAuthStateRepository) CreateTicket(ticket *domain.ExternalLinkTicket, ttl time.Duration) error {
+	data, err := json.Marshal(ticket)
+	if err != nil {
+		return err
+	}
+
+	return r.rdb.Set(context.Background(), externalLinkTicketPrefix+ticket.Ticket, data, ttl).Err()
+}
+
+func (r *externalAuthStateRepository) ConsumeTicket(ticket string) (*domain.ExternalLinkTicket, error) {
+	val, err := r.rdb.GetDel(context.Background(), externalLinkTicketPrefix+ticket).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var linkTicket domain.ExternalLinkTicket
+	if err := json.Unmarshal([]byte(val), &linkTicket); err != nil {
+		return nil, err
+	}
+	return &linkTicket, nil
+}
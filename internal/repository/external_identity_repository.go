@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"jti-super-app-go/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type externalIdentityRepository struct {
+	db *gorm.DB
+}
+
+func NewExternalIdentityRepository(db *gorm.DB) domain.ExternalIdentityRepository {
+	return &externalIdentityRepository{db: db}
+}
+
+func (r *externalIdentityRepository) FindByProviderSubject(provider, subject string) (*domain.ExternalIdentity, error) {
+	var identity domain.ExternalIdentity
+	if err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error; err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *externalIdentityRepository) FindByUserID(userID string) ([]domain.ExternalIdentity, error) {
+	var identities []domain.ExternalIdentity
+	if err := r.db.Where("m_user_id = ?", userID).Order("created_at ASC").Find(&identities).Error; err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+func (r *externalIdentityRepository) Create(identity *domain.ExternalIdentity) error {
+	return r.db.Create(identity).Error
+}
+
+func (r *externalIdentityRepository) Touch(id string, email *string, at time.Time) error {
+	return r.db.Model(&domain.ExternalIdentity{}).Where("id = ?", id).Updates(map[string]any{
+		"email":         email,
+		"last_login_at": at,
+		"updated_at":    at,
+	}).Error
+}
+
+func (r *externalIdentityRepository) Delete(userID, id string) error {
+	result := r.db.Where("id = ? AND m_user_id = ?", id, userID).Delete(&domain.ExternalIdentity{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"jti-super-app-go/internal/domain"
+	"jti-super-app-go/internal/dto"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type impersonationLogRepository struct {
+	db *gorm.DB
+}
+
+func NewImpersonationLogRepository(db *gorm.DB) domain.ImpersonationLogRepository {
+	return &impersonationLogRepository{db: db}
+}
+
+func (r *impersonationLogRepository) FindAll(params dto.QueryParams, userID string) (*[]domain.ImpersonationLog, int64, error) {
+	var logs []domain.ImpersonationLog
+	var totalRows int64
+
+	query := r.db.Model(&domain.ImpersonationLog{}).Preload("Impersonator").Preload("User")
+
+	if userID != "" {
+		query = query.Where("impersonation_logs.m_user_id = ? OR impersonation_logs.impersonator_id = ?", userID, userID)
+	}
+
+	if params.Search != "" {
+		searchQuery := "%" + params.Search + "%"
+		query = query.Where("impersonation_logs.reason LIKE ?", searchQuery)
+	}
+
+	if err := query.Count(&totalRows).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.PerPage
+	query = query.Order("impersonation_logs.started_at desc").Offset(offset).Limit(params.PerPage)
+
+	if err := query.Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return &logs, totalRows, nil
+}
+
+func (r *impersonationLogRepository) FindByID(id string) (*domain.ImpersonationLog, error) {
+	var log domain.ImpersonationLog
+	if err := r.db.Preload("Impersonator").First(&log, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &log, nil
+}
+
+func (r *impersonationLogRepository) Create(log *domain.ImpersonationLog) error {
+	return r.db.Omit("Impersonator", "User").Create(log).Error
+}
+
+func (r *impersonationLogRepository) End(id, reason string, at time.Time) error {
+	return r.db.Model(&domain.ImpersonationLog{}).
+		Where("id = ? AND ended_at IS NULL", id).
+		Updates(map[string]any{"ended_at": at, "end_reason": reason}).Error
+}
@@ -40,6 +40,8 @@ func (r *labRepository) FindAll(params dto.QueryParams, majorId string) (*[]doma
 		query = query.Where("m_lab.m_major_id = ?", majorId)
 	}
 
+	query = applyAccessScope(r.db, query, params.Scope, scopeColumns{lab: "m_lab.id"})
+
 	if err := query.Count(&totalRows).Error; err != nil {
 		return nil, 0, err
 	}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"jti-super-app-go/internal/domain"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	loginFailurePrefix = "login_fail:"
+	loginLockPrefix    = "login_lock:"
+)
+
+type loginAttemptRepository struct {
+	rdb *redis.Client
+}
+
+func NewLoginAttemptRepository(rdb *redis.Client) domain.LoginAttemptRepository {
+	return &loginAttemptRepository{rdb: rdb}
+}
+
+func (r *loginAttemptRepository) LockedFor(account string) (time.Duration, error) {
+	ttl, err := r.rdb.PTTL(context.Background(), loginLockPrefix+account).Result()
+	if err != nil {
+		return 0, err
+	}
+	// PTTL bernilai negatif jika key tidak ada
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+func (r *loginAttemptRepository) RecordFailure(account string, resetAfter time.Duration) (int, error) {
+	ctx := context.Background()
+	key := loginFailurePrefix + account
+
+	var incr *redis.IntCmd
+	_, err := r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, key)
+		pipe.Expire(ctx, key, resetAfter)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(incr.Val()), nil
+}
+
+func (r *loginAttemptRepository) Lock(account string, duration time.Duration) error {
+	return r.rdb.Set(context.Background(), loginLockPrefix+account, 1, duration).Err()
+}
+
+func (r *loginAttemptRepository) Reset(account string) error {
+	ctx := context.Background()
+	return r.rdb.Del(ctx, loginFailurePrefix+account, loginLockPrefix+account).Err()
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"jti-super-app-go/internal/domain"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	loginTransactionPrefix = "login_tx:"
+	loginResultPrefix      = "login_result:"
+)
+
+type loginTransactionRepository struct {
+	rdb *redis.Client
+}
+
+func NewLoginTransactionRepository(rdb *redis.Client) domain.LoginTransactionRepository {
+	return &loginTransactionRepository{rdb: rdb}
+}
+
+func (r *loginTransactionRepository) Create(tx *domain.LoginTransaction, ttl time.Duration) error {
+	data, err := json.Marshal(tx)
+	if err != nil {
+		return err
+	}
+
+	return r.rdb.Set(context.Background(), loginTransactionPrefix+tx.State, data, ttl).Err()
+}
+
+func (r *loginTransactionRepository) Consume(state string) (*domain.LoginTransaction, error) {
+	val, err := r.rdb.GetDel(context.Background(), loginTransactionPrefix+state).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var tx domain.LoginTransaction
+	if err := json.Unmarshal([]byte(val), &tx); err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+func (r *loginTransactionRepository) CreateResult(result *domain.LoginResult, ttl time.Duration) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	return r.rdb.Set(context.Background(), loginResultPrefix+result.Code, data, ttl).Err()
+}
+
+func (r *loginTransactionRepository) ConsumeResult(code string) (*domain.LoginResult, error) {
+	val, err := r.rdb.GetDel(context.Background(), loginResultPrefix+code).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var result domain.LoginResult
+	if err := json.Unmarshal([]byte(val), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
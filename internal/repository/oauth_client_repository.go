@@ -21,7 +21,7 @@ func (r *oauthClientRepository) FindAll(params dto.QueryParams) (*[]domain.Oauth
 	var clients []domain.OauthClient
 	var totalRows int64
 
-	query := r.db.Model(&domain.OauthClient{})
+	query := r.db.Model(&domain.OauthClient{}).Preload("Permissions")
 
 	if params.Search != "" {
 		searchQuery := fmt.Sprintf("%%%s%%", strings.ToLower(params.Search))
@@ -51,7 +51,7 @@ func (r *oauthClientRepository) FindAll(params dto.QueryParams) (*[]domain.Oauth
 
 func (r *oauthClientRepository) FindByID(id string) (*domain.OauthClient, error) {
 	var client domain.OauthClient
-	if err := r.db.Where("id = ?", id).First(&client).Error; err != nil {
+	if err := r.db.Preload("Permissions").Where("id = ?", id).First(&client).Error; err != nil {
 		return nil, err
 	}
 	return &client, nil
@@ -65,6 +65,45 @@ func (r *oauthClientRepository) Update(id string, client *domain.OauthClient) er
 	return r.db.Model(&domain.OauthClient{}).Where("id = ?", id).Updates(client).Error
 }
 
+// Save memperbarui kolom yang disebutkan secara eksplisit sehingga nilai kosong/false ikut tersimpan
+func (r *oauthClientRepository) Save(id string, client *domain.OauthClient, columns ...string) error {
+	return r.db.Model(&domain.OauthClient{}).Where("id = ?", id).Select(columns).Updates(client).Error
+}
+
+func (r *oauthClientRepository) FindAllRedirectURIs() ([]string, error) {
+	var clients []domain.OauthClient
+	if err := r.db.Select("redirect_uris").Find(&clients).Error; err != nil {
+		return nil, err
+	}
+
+	var uris []string
+	for _, client := range clients {
+		uris = append(uris, client.RedirectURIs...)
+	}
+	return uris, nil
+}
+
+func (r *oauthClientRepository) UpdatePermissions(id string, permissionIDs []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("m_oauth_client_id = ?", id).Delete(&domain.OauthClientHasPermission{}).Error; err != nil {
+			return err
+		}
+
+		if len(permissionIDs) == 0 {
+			return nil
+		}
+
+		rows := make([]domain.OauthClientHasPermission, 0, len(permissionIDs))
+		for _, permissionID := range permissionIDs {
+			rows = append(rows, domain.OauthClientHasPermission{
+				ClientID:     id,
+				PermissionID: permissionID,
+			})
+		}
+		return tx.Create(&rows).Error
+	})
+}
+
 func (r *oauthClientRepository) Delete(id string) error {
 	return r.db.Delete(&domain.OauthClient{}, "id = ?", id).Error
 }
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"jti-super-app-go/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type oauthConsentRepository struct {
+	db *gorm.DB
+}
+
+func NewOauthConsentRepository(db *gorm.DB) domain.OauthConsentRepository {
+	return &oauthConsentRepository{db: db}
+}
+
+func (r *oauthConsentRepository) FindByUserAndClient(userID, clientID string) (*domain.OauthConsent, error) {
+	var consent domain.OauthConsent
+	result := r.db.Where("m_user_id = ? AND m_oauth_client_id = ?", userID, clientID).Limit(1).Find(&consent)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	// Belum pernah memberi consent bukan error
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &consent, nil
+}
+
+func (r *oauthConsentRepository) Save(consent *domain.OauthConsent) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "m_user_id"}, {Name: "m_oauth_client_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"scopes", "updated_at"}),
+	}).Create(consent).Error
+}
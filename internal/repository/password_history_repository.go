@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"jti-super-app-go/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type passwordHistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordHistoryRepository(db *gorm.DB) domain.PasswordHistoryRepository {
+	return &passwordHistoryRepository{db: db}
+}
+
+func (r *passwordHistoryRepository) FindRecent(userID string, limit int) ([]domain.PasswordHistory, error) {
+	var histories []domain.PasswordHistory
+	if limit <= 0 {
+		return histories, nil
+	}
+	err := r.db.Where("m_user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&histories).Error
+	return histories, err
+}
+
+func (r *passwordHistoryRepository) Push(history *domain.PasswordHistory, keep int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(history).Error; err != nil {
+			return err
+		}
+
+		var stale []string
+		err := tx.Model(&domain.PasswordHistory{}).
+			Where("m_user_id = ?", history.UserID).
+			Order("created_at DESC").
+			Offset(keep).
+			Limit(1000).
+			Pluck("id", &stale).Error
+		if err != nil || len(stale) == 0 {
+			return err
+		}
+		return tx.Where("id IN ?", stale).Delete(&domain.PasswordHistory{}).Error
+	})
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"jti-super-app-go/internal/domain"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	permissionSetPrefix     = "perm_set:"
+	permissionVersionPrefix = "perm_ver:"
+	permissionVersionGlobal = "perm_ver:global"
+)
+
+type permissionCacheRepository struct {
+	rdb *redis.Client
+}
+
+func NewPermissionCacheRepository(rdb *redis.Client) domain.PermissionCacheRepository {
+	return &permissionCacheRepository{rdb: rdb}
+}
+
+func (r *permissionCacheRepository) Get(userID string) (*domain.PermissionSet, error) {
+	val, err := r.rdb.Get(context.Background(), permissionSetPrefix+userID).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var set domain.PermissionSet
+	if err := json.Unmarshal([]byte(val), &set); err != nil {
+		return nil, err
+	}
+	return &set, nil
+}
+
+func (r *permissionCacheRepository) Set(userID string, set *domain.PermissionSet, ttl time.Duration) error {
+	data, err := json.Marshal(set)
+	if err != nil {
+		return err
+	}
+
+	return r.rdb.Set(context.Background(), permissionSetPrefix+userID, data, ttl).Err()
+}
+
+// Version menjumlahkan kedua counter. Keduanya hanya pernah naik sehingga
+// jumlahnya juga selalu naik setiap kali salah satunya berubah.
+func (r *permissionCacheRepository) Version(userID string) (int64, error) {
+	values, err := r.rdb.MGet(context.Background(), permissionVersionPrefix+userID, permissionVersionGlobal).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	var version int64
+	for _, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return 0, errors.New("invalid permission version")
+		}
+		version += n
+	}
+	return version, nil
+}
+
+func (r *permissionCacheRepository) BumpUsers(userIDs ...string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	_, err := r.rdb.TxPipelined(context.Background(), func(pipe redis.Pipeliner) error {
+		for _, id := range userIDs {
+			pipe.Incr(context.Background(), permissionVersionPrefix+id)
+			pipe.Del(context.Background(), permissionSetPrefix+id)
+		}
+		return nil
+	})
+	return err
+}
+
+func (r *permissionCacheRepository) BumpAll() error {
+	return r.rdb.Incr(context.Background(), permissionVersionGlobal).Err()
+}
@@ -3,7 +3,9 @@ package repository
 import (
 	"jti-super-app-go/internal/domain"
 	"jti-super-app-go/internal/dto"
+	"slices"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -83,3 +85,26 @@ func (r *permissionRepository) Delete(id string) error {
 	}
 	return nil
 }
+
+func (r *permissionRepository) CreateMissing(names []string) ([]domain.Permission, error) {
+	var existing []string
+	if err := r.db.Model(&domain.Permission{}).Where("name IN ?", names).Pluck("name", &existing).Error; err != nil {
+		return nil, err
+	}
+
+	var missing []domain.Permission
+	for _, name := range names {
+		if slices.Contains(existing, name) {
+			continue
+		}
+		missing = append(missing, domain.Permission{ID: uuid.NewString(), Name: name, GuardName: "api"})
+	}
+	if len(missing) == 0 {
+		return missing, nil
+	}
+
+	if err := r.db.Create(&missing).Error; err != nil {
+		return nil, err
+	}
+	return missing, nil
+}
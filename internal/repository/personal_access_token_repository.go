@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"jti-super-app-go/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type personalAccessTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewPersonalAccessTokenRepository(db *gorm.DB) domain.PersonalAccessTokenRepository {
+	return &personalAccessTokenRepository{db: db}
+}
+
+func (r *personalAccessTokenRepository) FindByUserID(userID string) ([]domain.PersonalAccessToken, error) {
+	var tokens []domain.PersonalAccessToken
+	if err := r.db.Where("m_user_id = ?", userID).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (r *personalAccessTokenRepository) FindByHash(tokenHash string) (*domain.PersonalAccessToken, error) {
+	var token domain.PersonalAccessToken
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *personalAccessTokenRepository) Create(token *domain.PersonalAccessToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *personalAccessTokenRepository) UpdateLastUsed(id string, usedAt time.Time) error {
+	return r.db.Model(&domain.PersonalAccessToken{}).Where("id = ?", id).Update("last_used_at", usedAt).Error
+}
+
+func (r *personalAccessTokenRepository) Delete(userID, id string) error {
+	result := r.db.Where("id = ? AND m_user_id = ?", id, userID).Delete(&domain.PersonalAccessToken{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"jti-super-app-go/internal/domain"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	refreshTokenPrefix  = "refresh_token:"
+	refreshFamilyPrefix = "refresh_family:"
+	revokedFamilyPrefix = "revoked_family:"
+	// maxFamilyUpdateRetries membatasi percobaan ulang saat family diubah
+	// bersamaan oleh request lain (WATCH gagal)
+	maxFamilyUpdateRetries = 10
+)
+
+type refreshTokenRepository struct {
+	rdb *redis.Client
+}
+
+func NewRefreshTokenRepository(rdb *redis.Client) domain.RefreshTokenRepository {
+	return &refreshTokenRepository{rdb: rdb}
+}
+
+func (r *refreshTokenRepository) CreateFamily(family *domain.RefreshTokenFamily) error {
+	ctx := context.Background()
+	key := refreshFamilyPrefix + family.ID
+
+	data, err := json.Marshal(family)
+	if err != nil {
+		return err
+	}
+
+	return r.rdb.Set(ctx, key, data, time.Until(family.ExpiresAt)).Err()
+}
+
+func (r *refreshTokenRepository) FindFamily(id string) (*domain.RefreshTokenFamily, error) {
+	val, err := r.rdb.Get(context.Background(), refreshFamilyPrefix+id).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var family domain.RefreshTokenFamily
+	if err := json.Unmarshal([]byte(val), &family); err != nil {
+		return nil, err
+	}
+	return &family, nil
+}
+
+func (r *refreshTokenRepository) SetLastAccessToken(familyID, accessToken string) error {
+	return r.updateFamily(familyID, func(family *domain.RefreshTokenFamily) {
+		family.LastAccessToken = accessToken
+	})
+}
+
+func (r *refreshTokenRepository) RevokeFamily(id string, tokenTTL time.Duration) error {
+	// Penanda ditulis lebih dulu sehingga access token langsung ditolak walau
+	// data family sudah kedaluwarsa atau gagal diperbarui
+	if err := r.rdb.Set(context.Background(), revokedFamilyPrefix+id, 1, tokenTTL).Err(); err != nil {
+		return err
+	}
+
+	err := r.updateFamily(id, func(family *domain.RefreshTokenFamily) {
+		family.Revoked = true
+	})
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	return err
+}
+
+func (r *refreshTokenRepository) IsFamilyRevoked(id string) (bool, error) {
+	n, err := r.rdb.Exists(context.Background(), revokedFamilyPrefix+id).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *refreshTokenRepository) Store(tokenHash string, token *domain.RefreshToken) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	key := refreshTokenPrefix + tokenHash
+
+	_, err = r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, "data", data)
+		pipe.ExpireAt(ctx, key, token.ExpiresAt)
+		return nil
+	})
+	return err
+}
+
+func (r *refreshTokenRepository) Find(tokenHash string) (*domain.RefreshToken, bool, error) {
+	fields, err := r.rdb.HGetAll(context.Background(), refreshTokenPrefix+tokenHash).Result()
+	if err != nil {
+		return nil, false, err
+	}
+	if fields["data"] == "" {
+		return nil, false, redis.Nil
+	}
+
+	var token domain.RefreshToken
+	if err := json.Unmarshal([]byte(fields["data"]), &token); err != nil {
+		return nil, false, err
+	}
+
+	_, used := fields["used_at"]
+	return &token, used, nil
+}
+
+func (r *refreshTokenRepository) Consume(tokenHash string) (*domain.RefreshToken, bool, error) {
+	ctx := context.Background()
+	key := refreshTokenPrefix + tokenHash
+
+	val, err := r.rdb.HGet(ctx, key, "data").Result()
+	if err != nil {
+		return nil, false, err
+	}
+
+	var token domain.RefreshToken
+	if err := json.Unmarshal([]byte(val), &token); err != nil {
+		return nil, false, err
+	}
+
+	// HSETNX bersifat atomik sehingga hanya satu request yang bisa memakai token ini
+	firstUse, err := r.rdb.HSetNX(ctx, key, "used_at", time.Now().Unix()).Result()
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &token, firstUse, nil
+}
+
+func (r *refreshTokenRepository) updateFamily(id string, mutate func(family *domain.RefreshTokenFamily)) error {
+	ctx := context.Background()
+	key := refreshFamilyPrefix + id
+
+	for range maxFamilyUpdateRetries {
+		err := r.rdb.Watch(ctx, func(tx *redis.Tx) error {
+			val, err := tx.Get(ctx, key).Result()
+			if err != nil {
+				return err
+			}
+
+			var family domain.RefreshTokenFamily
+			if err := json.Unmarshal([]byte(val), &family); err != nil {
+				return err
+			}
+			mutate(&family)
+
+			data, err := json.Marshal(family)
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.Set(ctx, key, data, redis.KeepTTL)
+				return nil
+			})
+			return err
+		}, key)
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+	}
+	return redis.TxFailedErr
+}
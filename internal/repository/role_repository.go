@@ -70,7 +70,8 @@ func (r *roleRepository) Update(id string, role *domain.Role) (*domain.Role, err
 	}
 
 	// Update the role fields
-	if err := tx.Model(&domain.Role{}).Where("uuid = ?", id).Updates(role).Error; err != nil {
+	// Select agar requires_two_factor tetap ter-update saat bernilai false
+	if err := tx.Model(&domain.Role{}).Where("uuid = ?", id).Select("Name", "RequiresTwoFactor").Updates(role).Error; err != nil {
 		tx.Rollback()
 		return nil, err
 	}
@@ -110,3 +111,17 @@ func (r *roleRepository) FindAllAsOptions() (*[]domain.Role, error) {
 	}
 	return &roles, nil
 }
+
+func (r *roleRepository) AttachPermissions(roleName string, permissionNames []string) error {
+	var role domain.Role
+	if err := r.db.First(&role, "name = ?", roleName).Error; err != nil {
+		return err
+	}
+
+	var permissions []domain.Permission
+	if err := r.db.Where("name IN ?", permissionNames).Find(&permissions).Error; err != nil {
+		return err
+	}
+
+	return r.db.Model(&role).Association("Permissions").Append(permissions)
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"errors"
+	"jti-super-app-go/internal/domain"
+	"jti-super-app-go/internal/dto"
+	"jti-super-app-go/pkg/constants"
+
+	"gorm.io/gorm"
+)
+
+type scopedPermissionRepository struct {
+	db *gorm.DB
+}
+
+func NewScopedPermissionRepository(db *gorm.DB) domain.ScopedPermissionRepository {
+	return &scopedPermissionRepository{db: db}
+}
+
+func (r *scopedPermissionRepository) FindByUserID(userID string) ([]domain.ScopedPermission, error) {
+	var grants []domain.ScopedPermission
+	if err := r.db.Preload("Permission").Where("m_user_id = ?", userID).Order("created_at ASC").Find(&grants).Error; err != nil {
+		return nil, err
+	}
+	return grants, nil
+}
+
+func (r *scopedPermissionRepository) Create(grant *domain.ScopedPermission) error {
+	return r.db.Create(grant).Error
+}
+
+func (r *scopedPermissionRepository) Delete(userID, id string) error {
+	result := r.db.Where("id = ? AND m_user_id = ?", id, userID).Delete(&domain.ScopedPermission{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *scopedPermissionRepository) ScopeExists(scopeType, scopeID string) (bool, error) {
+	var model any
+	switch scopeType {
+	case constants.AccessScopeMajor:
+		model = &domain.Major{}
+	case constants.AccessScopeStudyProgram:
+		model = &domain.StudyProgram{}
+	case constants.AccessScopeLab:
+		model = &domain.Lab{}
+	default:
+		return false, errors.New("unknown scope type")
+	}
+
+	var count int64
+	if err := r.db.Model(model).Where("id = ?", scopeID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *scopedPermissionRepository) ExpandMajors(majorIDs []string) ([]string, []string, error) {
+	if len(majorIDs) == 0 {
+		return nil, nil, nil
+	}
+
+	var studyProgramIDs, labIDs []string
+	if err := r.db.Model(&domain.StudyProgram{}).Where("m_major_id IN ?", majorIDs).Pluck("id", &studyProgramIDs).Error; err != nil {
+		return nil, nil, err
+	}
+	if err := r.db.Model(&domain.Lab{}).Where("m_major_id IN ?", majorIDs).Pluck("id", &labIDs).Error; err != nil {
+		return nil, nil, err
+	}
+	return studyProgramIDs, labIDs, nil
+}
+
+// scopeColumns menyebut kolom yang dibandingkan dengan AccessScope. Kolom
+// kosong berarti jenis scope tersebut tidak berlaku untuk query itu.
+type scopeColumns struct {
+	studyProgram string
+	lab          string
+}
+
+// applyAccessScope membatasi query ke scope pemanggil. Scope nil tidak
+// membatasi apa pun, sedangkan scope yang tidak cocok dengan kolom mana pun
+// menghasilkan hasil kosong.
+func applyAccessScope(db, query *gorm.DB, scope *dto.AccessScope, columns scopeColumns) *gorm.DB {
+	if scope == nil {
+		return query
+	}
+
+	condition := db.Where("1 = 0")
+	if columns.studyProgram != "" && len(scope.StudyProgramIDs) > 0 {
+		condition = condition.Or(columns.studyProgram+" IN ?", scope.StudyProgramIDs)
+	}
+	if columns.lab != "" && len(scope.LabIDs) > 0 {
+		condition = condition.Or(columns.lab+" IN ?", scope.LabIDs)
+	}
+	return query.Where(condition)
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"jti-super-app-go/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type signingKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewSigningKeyRepository(db *gorm.DB) domain.SigningKeyRepository {
+	return &signingKeyRepository{db: db}
+}
+
+func (r *signingKeyRepository) FindUsable() (*[]domain.SigningKey, error) {
+	var keys []domain.SigningKey
+	err := r.db.
+		Where("status IN ?", []string{domain.SigningKeyStatusActive, domain.SigningKeyStatusRetiring}).
+		Order("created_at desc").
+		Find(&keys).Error
+	if err != nil {
+		return nil, err
+	}
+	return &keys, nil
+}
+
+// Rotate menjadikan newKey satu-satunya key aktif. Key aktif sebelumnya berubah
+// menjadi RETIRING (masih dipublikasikan di JWKS untuk verifikasi), sedangkan
+// key RETIRING yang lebih lama dari retireBefore dipensiunkan.
+func (r *signingKeyRepository) Rotate(newKey *domain.SigningKey, retireBefore time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.SigningKey{}).
+			Where("status = ? AND retiring_at < ?", domain.SigningKeyStatusRetiring, retireBefore).
+			Update("status", domain.SigningKeyStatusRetired).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&domain.SigningKey{}).
+			Where("status = ?", domain.SigningKeyStatusActive).
+			Updates(map[string]interface{}{
+				"status":      domain.SigningKeyStatusRetiring,
+				"retiring_at": time.Now(),
+			}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(newKey).Error
+	})
+}
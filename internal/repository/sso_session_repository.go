@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"jti-super-app-go/internal/domain"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	ssoSessionPrefix        = "sso:"
+	ssoSessionClientsPrefix = "sso_clients:"
+)
+
+type ssoSessionRepository struct {
+	rdb *redis.Client
+}
+
+func NewSSOSessionRepository(rdb *redis.Client) domain.SSOSessionRepository {
+	return &ssoSessionRepository{rdb: rdb}
+}
+
+func (r *ssoSessionRepository) AddClient(sessionID string, client domain.SSOSessionClient, ttl time.Duration) error {
+	ctx := context.Background()
+	key := ssoSessionClientsPrefix + sessionID
+
+	member, err := json.Marshal(client)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SAdd(ctx, key, member)
+		pipe.Expire(ctx, key, ttl)
+		return nil
+	})
+	return err
+}
+
+func (r *ssoSessionRepository) FindClients(sessionID string) ([]domain.SSOSessionClient, error) {
+	members, err := r.rdb.SMembers(context.Background(), ssoSessionClientsPrefix+sessionID).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	clients := make([]domain.SSOSessionClient, 0, len(members))
+	for _, member := range members {
+		var client domain.SSOSessionClient
+		if err := json.Unmarshal([]byte(member), &client); err != nil {
+			continue
+		}
+		clients = append(clients, client)
+	}
+	return clients, nil
+}
+
+func (r *ssoSessionRepository) Delete(sessionID string) error {
+	return r.rdb.Del(context.Background(), ssoSessionPrefix+sessionID, ssoSessionClientsPrefix+sessionID).Err()
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"jti-super-app-go/internal/domain"
 	"jti-super-app-go/internal/dto"
+	"jti-super-app-go/pkg/constants"
 	"sort"
 	"strings"
 
@@ -67,6 +68,8 @@ func (r *studentRepository) FindAll(params dto.QueryParams) (*[]domain.Student,
 		}
 	}
 
+	query = applyAccessScope(r.db, query, params.Scope, scopeColumns{studyProgram: "m_study_program.id"})
+
 	if err := query.Count(&totalRows).Error; err != nil {
 		return nil, 0, err
 	}
@@ -111,6 +114,24 @@ func (r *studentRepository) FindByID(id string) (*domain.Student, error) {
 	return &student, nil
 }
 
+func (r *studentRepository) FindUnverifiedUsers(generation int, studyProgramID string) ([]domain.User, error) {
+	query := r.db.Model(&domain.User{}).
+		Select("m_user.*").
+		Joins("JOIN m_student ON m_student.m_user_id = m_user.id AND m_student.deleted_at IS NULL").
+		Where("m_student.generation = ?", generation).
+		Where("m_user.email_verified_at IS NULL").
+		Where("m_user.deleted_at IS NULL").
+		Where("m_user.status = ?", constants.StatusActive)
+
+	if studyProgramID != "" {
+		query = query.Where("m_student.m_study_program_id = ?", studyProgramID)
+	}
+
+	var users []domain.User
+	err := query.Order("m_user.name ASC").Find(&users).Error
+	return users, err
+}
+
 func (r *studentRepository) FindByUserID(userID string) (*domain.Student, error) {
 	var student domain.Student
 	if err := r.db.Preload("StudyProgram.Major").Preload("StudentSemesters").First(&student, "m_user_id = ?", userID).Error; err != nil {
@@ -52,6 +52,16 @@ func (r *subjectLectureRepository) FindAll(params dto.QueryParams) (*[]domain.Su
 		}
 	}
 
+	// Mapping dosen dibatasi lewat program studi mata kuliahnya. Subquery dipakai
+	// karena filter semester_ids bisa sudah melakukan join ke m_subject_semester
+	if params.Scope != nil {
+		subjectSemesters := r.db.Table("m_subject_semester").
+			Select("m_subject_semester.id").
+			Joins("JOIN m_subject ON m_subject.id = m_subject_semester.m_subject_id")
+		subjectSemesters = applyAccessScope(r.db, subjectSemesters, params.Scope, scopeColumns{studyProgram: "m_subject.m_study_program_id"})
+		query = query.Where("m_subject_lecture.m_subject_semester_id IN (?)", subjectSemesters)
+	}
+
 	if err := query.Count(&totalRows).Error; err != nil {
 		return nil, 0, err
 	}
@@ -43,6 +43,8 @@ func (r *subjectRepository) FindAll(params dto.QueryParams) (*[]domain.Subject,
 		query = query.Where("m_subject.m_study_program_id = ?", spID)
 	}
 
+	query = applyAccessScope(r.db, query, params.Scope, scopeColumns{studyProgram: "m_subject.m_study_program_id"})
+
 	if err := query.Count(&totalRows).Error; err != nil {
 		return nil, 0, err
 	}
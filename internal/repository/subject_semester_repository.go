@@ -58,3 +58,13 @@ func (r *subjectSemesterRepository) StoreLectureOnSubject(data []dto.LectureMapp
 		return nil
 	})
 }
+
+func (r *subjectSemesterRepository) StudyProgramIDs(subjectSemesterIDs []string) ([]string, error) {
+	var ids []string
+	err := r.db.Model(&domain.SubjectSemester{}).
+		Distinct("m_subject.m_study_program_id").
+		Joins("JOIN m_subject ON m_subject.id = m_subject_semester.m_subject_id").
+		Where("m_subject_semester.id IN ?", subjectSemesterIDs).
+		Pluck("m_subject.m_study_program_id", &ids).Error
+	return ids, err
+}
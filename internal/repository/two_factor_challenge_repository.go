@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"jti-super-app-go/internal/domain"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	twoFactorChallengePrefix = "2fa_challenge:"
+	twoFactorUsedCodePrefix  = "2fa_used:"
+)
+
+type twoFactorChallengeRepository struct {
+	rdb *redis.Client
+}
+
+func NewTwoFactorChallengeRepository(rdb *redis.Client) domain.TwoFactorChallengeRepository {
+	return &twoFactorChallengeRepository{rdb: rdb}
+}
+
+func (r *twoFactorChallengeRepository) Create(challenge *domain.TwoFactorChallenge, ttl time.Duration) error {
+	ctx := context.Background()
+	key := twoFactorChallengePrefix + challenge.Token
+
+	_, err := r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, "user_id", challenge.UserID, "attempts", challenge.Attempts)
+		pipe.Expire(ctx, key, ttl)
+		return nil
+	})
+	return err
+}
+
+func (r *twoFactorChallengeRepository) Find(token string) (*domain.TwoFactorChallenge, error) {
+	fields, err := r.rdb.HGetAll(context.Background(), twoFactorChallengePrefix+token).Result()
+	if err != nil {
+		return nil, err
+	}
+	if fields["user_id"] == "" {
+		return nil, redis.Nil
+	}
+
+	attempts, _ := strconv.Atoi(fields["attempts"])
+	return &domain.TwoFactorChallenge{
+		Token:    token,
+		UserID:   fields["user_id"],
+		Attempts: attempts,
+	}, nil
+}
+
+func (r *twoFactorChallengeRepository) IncrementAttempts(token string) (int, error) {
+	n, err := r.rdb.HIncrBy(context.Background(), twoFactorChallengePrefix+token, "attempts", 1).Result()
+	return int(n), err
+}
+
+func (r *twoFactorChallengeRepository) Delete(token string) error {
+	return r.rdb.Del(context.Background(), twoFactorChallengePrefix+token).Err()
+}
+
+func (r *twoFactorChallengeRepository) MarkCodeUsed(userID string, step int64, ttl time.Duration) (bool, error) {
+	key := fmt.Sprintf("%s%s:%d", twoFactorUsedCodePrefix, userID, step)
+	return r.rdb.SetNX(context.Background(), key, 1, ttl).Result()
+}
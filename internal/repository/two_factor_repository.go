@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"jti-super-app-go/internal/domain"
+	"slices"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type twoFactorRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) domain.TwoFactorRepository {
+	return &twoFactorRepository{db: db}
+}
+
+func (r *twoFactorRepository) FindByUserID(userID string) (*domain.UserTwoFactor, error) {
+	var twoFactor domain.UserTwoFactor
+	result := r.db.Where("m_user_id = ?", userID).Limit(1).Find(&twoFactor)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &twoFactor, nil
+}
+
+func (r *twoFactorRepository) Save(twoFactor *domain.UserTwoFactor) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "m_user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"secret", "recovery_codes", "confirmed_at", "updated_at"}),
+	}).Create(twoFactor).Error
+}
+
+func (r *twoFactorRepository) Delete(userID string) error {
+	return r.db.Where("m_user_id = ?", userID).Delete(&domain.UserTwoFactor{}).Error
+}
+
+func (r *twoFactorRepository) ConsumeRecoveryCode(userID, codeHash string) (bool, error) {
+	consumed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var twoFactor domain.UserTwoFactor
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("m_user_id = ?", userID).First(&twoFactor).Error; err != nil {
+			return err
+		}
+
+		idx := slices.Index(twoFactor.RecoveryCodes, codeHash)
+		if idx < 0 {
+			return nil
+		}
+
+		codes := slices.Delete(twoFactor.RecoveryCodes, idx, idx+1)
+		if err := tx.Model(&twoFactor).Update("recovery_codes", codes).Error; err != nil {
+			return err
+		}
+		consumed = true
+		return nil
+	})
+	return consumed, err
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"jti-super-app-go/internal/domain"
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	userSessionPrefix      = "user_session:"
+	userSessionIndexPrefix = "user_sessions:"
+)
+
+type userSessionRepository struct {
+	rdb *redis.Client
+}
+
+func NewUserSessionRepository(rdb *redis.Client) domain.UserSessionRepository {
+	return &userSessionRepository{rdb: rdb}
+}
+
+func (r *userSessionRepository) Create(session *domain.UserSession) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	indexKey := userSessionIndexPrefix + session.UserID
+
+	_, err = r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, userSessionPrefix+session.ID, data, time.Until(session.ExpiresAt))
+		// Index tidak diberi TTL; ID sesi yang kedaluwarsa dibersihkan saat dibaca
+		pipe.SAdd(ctx, indexKey, session.ID)
+		return nil
+	})
+	return err
+}
+
+func (r *userSessionRepository) FindByID(id string) (*domain.UserSession, error) {
+	val, err := r.rdb.Get(context.Background(), userSessionPrefix+id).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var session domain.UserSession
+	if err := json.Unmarshal([]byte(val), &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *userSessionRepository) FindByUserID(userID string) ([]domain.UserSession, error) {
+	ctx := context.Background()
+	indexKey := userSessionIndexPrefix + userID
+
+	ids, err := r.rdb.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]domain.UserSession, 0, len(ids))
+	for _, id := range ids {
+		session, err := r.FindByID(id)
+		if errors.Is(err, redis.Nil) {
+			// Sesi sudah kedaluwarsa, bersihkan dari index
+			r.rdb.SRem(ctx, indexKey, id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *session)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+	return sessions, nil
+}
+
+func (r *userSessionRepository) Touch(id string, at time.Time) error {
+	ctx := context.Background()
+	key := userSessionPrefix + id
+
+	return r.rdb.Watch(ctx, func(tx *redis.Tx) error {
+		val, err := tx.Get(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+
+		var session domain.UserSession
+		if err := json.Unmarshal([]byte(val), &session); err != nil {
+			return err
+		}
+		session.LastSeenAt = at
+
+		data, err := json.Marshal(session)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, data, redis.KeepTTL)
+			return nil
+		})
+		return err
+	}, key)
+}
+
+func (r *userSessionRepository) Delete(session *domain.UserSession) error {
+	ctx := context.Background()
+
+	_, err := r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, userSessionPrefix+session.ID)
+		pipe.SRem(ctx, userSessionIndexPrefix+session.UserID, session.ID)
+		return nil
+	})
+	return err
+}
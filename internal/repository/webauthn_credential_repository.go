@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"jti-super-app-go/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type webAuthnCredentialRepository struct {
+	db *gorm.DB
+}
+
+func NewWebAuthnCredentialRepository(db *gorm.DB) domain.WebAuthnCredentialRepository {
+	return &webAuthnCredentialRepository{db: db}
+}
+
+func (r *webAuthnCredentialRepository) FindByUserID(userID string) ([]domain.WebAuthnCredential, error) {
+	var credentials []domain.WebAuthnCredential
+	if err := r.db.Where("m_user_id = ?", userID).Order("created_at ASC").Find(&credentials).Error; err != nil {
+		return nil, err
+	}
+	return credentials, nil
+}
+
+func (r *webAuthnCredentialRepository) FindByCredentialID(credentialID string) (*domain.WebAuthnCredential, error) {
+	var credential domain.WebAuthnCredential
+	if err := r.db.Where("credential_id = ?", credentialID).First(&credential).Error; err != nil {
+		return nil, err
+	}
+	return &credential, nil
+}
+
+func (r *webAuthnCredentialRepository) Create(credential *domain.WebAuthnCredential) error {
+	return r.db.Create(credential).Error
+}
+
+func (r *webAuthnCredentialRepository) UpdateUsage(id string, signCount uint32, usedAt time.Time) error {
+	return r.db.Model(&domain.WebAuthnCredential{}).Where("id = ?", id).Updates(map[string]any{
+		"sign_count":   signCount,
+		"last_used_at": usedAt,
+		"updated_at":   usedAt,
+	}).Error
+}
+
+func (r *webAuthnCredentialRepository) Delete(userID, id string) error {
+	result := r.db.Where("id = ? AND m_user_id = ?", id, userID).Delete(&domain.WebAuthnCredential{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"jti-super-app-go/internal/domain"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const webAuthnSessionPrefix = "webauthn_session:"
+
+type webAuthnSessionRepository struct {
+	rdb *redis.Client
+}
+
+func NewWebAuthnSessionRepository(rdb *redis.Client) domain.WebAuthnSessionRepository {
+	return &webAuthnSessionRepository{rdb: rdb}
+}
+
+func (r *webAuthnSessionRepository) Create(session *domain.WebAuthnSession, ttl time.Duration) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	return r.rdb.Set(context.Background(), webAuthnSessionPrefix+session.ID, data, ttl).Err()
+}
+
+func (r *webAuthnSessionRepository) Consume(id string) (*domain.WebAuthnSession, error) {
+	val, err := r.rdb.GetDel(context.Background(), webAuthnSessionPrefix+id).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var session domain.WebAuthnSession
+	if err := json.Unmarshal([]byte(val), &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"jti-super-app-go/config"
+	"net/http"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// UpstreamIdentity adalah identitas user menurut IdP upstream. Subject stabil
+// per provider, sedangkan email bisa berubah dan tidak selalu terverifikasi.
+type UpstreamIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type IdentityProvider interface {
+	Name() string
+	DisplayName() string
+	// TrustEmail menentukan apakah email terverifikasi dari provider boleh
+	// dipakai untuk menautkan akun secara otomatis.
+	TrustEmail() bool
+	// AuthCodeURL membuat URL authorize dengan state dan PKCE verifier.
+	AuthCodeURL(state, verifier string) string
+	Exchange(ctx context.Context, code, verifier string) (*UpstreamIdentity, error)
+}
+
+// IdentityProviderRegistry berisi semua IdP upstream yang aktif sesuai urutan config.
+type IdentityProviderRegistry interface {
+	Get(name string) (IdentityProvider, bool)
+	List() []IdentityProvider
+}
+
+type identityProviderRegistry struct {
+	providers []IdentityProvider
+	byName    map[string]IdentityProvider
+}
+
+func NewIdentityProviderRegistry(cfgs []config.IdentityProviderConfig) IdentityProviderRegistry {
+	registry := &identityProviderRegistry{byName: make(map[string]IdentityProvider, len(cfgs))}
+	for _, cfg := range cfgs {
+		provider := newOIDCIdentityProvider(cfg)
+		registry.providers = append(registry.providers, provider)
+		registry.byName[cfg.Name] = provider
+	}
+	return registry
+}
+
+func (r *identityProviderRegistry) Get(name string) (IdentityProvider, bool) {
+	provider, ok := r.byName[name]
+	return provider, ok
+}
+
+func (r *identityProviderRegistry) List() []IdentityProvider {
+	return r.providers
+}
+
+// oidcIdentityProvider memakai authorization code + PKCE lalu membaca claim dari
+// endpoint userinfo, yang didukung Google maupun Microsoft identity platform.
+type oidcIdentityProvider struct {
+	cfg         config.IdentityProviderConfig
+	oauthConfig *oauth2.Config
+	httpClient  *http.Client
+}
+
+func newOIDCIdentityProvider(cfg config.IdentityProviderConfig) *oidcIdentityProvider {
+	return &oidcIdentityProvider{
+		cfg: cfg,
+		oauthConfig: &oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Scopes:       cfg.Scopes,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  cfg.AuthURL,
+				TokenURL: cfg.TokenURL,
+			},
+		},
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *oidcIdentityProvider) Name() string        { return p.cfg.Name }
+func (p *oidcIdentityProvider) DisplayName() string { return p.cfg.DisplayName }
+func (p *oidcIdentityProvider) TrustEmail() bool    { return p.cfg.TrustEmail }
+
+func (p *oidcIdentityProvider) AuthCodeURL(state, verifier string) string {
+	return p.oauthConfig.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier))
+}
+
+func (p *oidcIdentityProvider) Exchange(ctx context.Context, code, verifier string) (*UpstreamIdentity, error) {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, p.httpClient)
+	token, err := p.oauthConfig.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code with %s: %w", p.cfg.DisplayName, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.cfg.UserInfoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	token.SetAuthHeader(req)
+
+	response, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user info from %s: %w", p.cfg.DisplayName, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s userinfo endpoint returned status %d", p.cfg.DisplayName, response.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if err != nil {
+		return nil, errors.New("failed to read response body: " + err.Error())
+	}
+
+	var claims struct {
+		Subject           string `json:"sub"`
+		Email             string `json:"email"`
+		EmailVerified     any    `json:"email_verified"`
+		Name              string `json:"name"`
+		PreferredUsername string `json:"preferred_username"`
+	}
+	if err := json.Unmarshal(body, &claims); err != nil {
+		return nil, errors.New("failed to unmarshal user info: " + err.Error())
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%s did not return a subject identifier", p.cfg.DisplayName)
+	}
+
+	identity := &UpstreamIdentity{
+		Provider: p.cfg.Name,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+		Name:     claims.Name,
+	}
+	// Beberapa provider mengirim email_verified sebagai string
+	switch v := claims.EmailVerified.(type) {
+	case bool:
+		identity.EmailVerified = v
+	case string:
+		identity.EmailVerified = v == "true"
+	}
+	// Microsoft tidak mengirim email_verified; email dianggap terverifikasi hanya
+	// jika provider dipercaya (tenant kampus)
+	if claims.EmailVerified == nil && p.cfg.TrustEmail {
+		identity.EmailVerified = identity.Email != ""
+	}
+	if identity.Email == "" {
+		identity.Email = claims.PreferredUsername
+	}
+
+	return identity, nil
+}
@@ -1,74 +1,160 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"jti-super-app-go/config"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
+// AccessTokenType membedakan access token dari ID token dan logout token yang
+// ditandatangani dengan kunci yang sama (RFC 9068).
+const AccessTokenType = "at+jwt"
+
+var ErrInvalidAccessToken = errors.New("token is not a valid access token")
+
 type JWTClaims struct {
-	UserID      string   `json:"user_id"`
-	Roles       []string `json:"roles"`
-	Permissions []string `json:"permissions"`
+	// TokenType (typ) wajib bernilai AccessTokenType agar ID token atau logout
+	// token tidak bisa dipakai sebagai bearer token.
+	TokenType string `json:"typ,omitempty"`
+	UserID    string `json:"user_id,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	SessionID string `json:"sid,omitempty"`
+	Scope     string `json:"scope,omitempty"`
+	// Roles dan Permissions hanya dibawa token client_credentials. Token user
+	// membawa PermissionVersion dan permission-nya diselesaikan live.
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	// PermissionVersion (pv) adalah versi permission user saat token diterbitkan.
+	// Token user tanpa pv tidak membawa permission apa pun, mis. token yang
+	// dibatasi atau token yang diterbitkan untuk OAuth client.
+	PermissionVersion *int64 `json:"pv,omitempty"`
+	// Restrictions membatasi token ke endpoint yang secara eksplisit mengizinkannya.
+	Restrictions []string `json:"restrictions,omitempty"`
+	// Actor terisi pada token impersonation dan menunjuk admin yang sebenarnya
+	// memakai token ini (claim "act" RFC 8693).
+	Actor *ActorClaim `json:"act,omitempty"`
 	jwt.RegisteredClaims
 }
 
+type ActorClaim struct {
+	Subject string `json:"sub"`
+}
+
 type JWTService interface {
-	GenerateToken(userID string, roles, permissions []string) (string, error)
+	// GenerateToken menerbitkan token user. Token untuk OAuth client membawa
+	// client ID sebagai audience (aud) sehingga ditolak di route first-party.
+	GenerateToken(userID, audience, sessionID, scope string, permissionVersion *int64, restrictions []string) (string, error)
+	GenerateClientToken(clientID, scope string, permissions []string) (string, error)
+	GenerateImpersonationToken(userID, actorID, sessionID string, permissionVersion *int64, ttl time.Duration) (string, error)
 	ValidateToken(tokenString string) (*JWTClaims, error)
+	AccessTokenTTL() time.Duration
 }
 
 type jwtService struct {
-	secretKey      string
-	expirationHours int
+	keys   SigningKeyService
+	issuer string
+	ttl    time.Duration
 }
 
-func NewJWTService() JWTService {
+func NewJWTService(keys SigningKeyService) JWTService {
 	cfg := config.AppConfig
 	return &jwtService{
-		secretKey:      cfg.JWTSecretKey,
-		expirationHours: cfg.JWTExpirationHours,
+		keys:   keys,
+		issuer: cfg.OIDC.Issuer,
+		ttl:    time.Duration(cfg.AccessTokenMinutes) * time.Minute,
 	}
 }
 
-func (s *jwtService) GenerateToken(userID string, roles, permissions []string) (string, error) {
+func (s *jwtService) AccessTokenTTL() time.Duration {
+	return s.ttl
+}
+
+func (s *jwtService) GenerateToken(userID, audience, sessionID, scope string, permissionVersion *int64, restrictions []string) (string, error) {
 	claims := JWTClaims{
-		UserID:      userID,
-		Roles:       roles,
+		UserID:            userID,
+		SessionID:         sessionID,
+		Scope:             scope,
+		PermissionVersion: permissionVersion,
+		Restrictions:      restrictions,
+		TokenType:         AccessTokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    s.issuer,
+			Subject:   userID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	if audience != "" {
+		claims.Audience = jwt.ClaimStrings{audience}
+	}
+
+	return s.keys.Sign(claims)
+}
+
+// GenerateClientToken menerbitkan token grant client_credentials. Subject-nya
+// adalah OAuth client, bukan user, sehingga claim user_id dikosongkan.
+func (s *jwtService) GenerateClientToken(clientID, scope string, permissions []string) (string, error) {
+	claims := JWTClaims{
+		ClientID:    clientID,
+		Scope:       scope,
+		Roles:       []string{},
 		Permissions: permissions,
+		TokenType:   AccessTokenType,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(s.expirationHours) * time.Hour)),
+			ID:        uuid.NewString(),
+			Issuer:    s.issuer,
+			Subject:   clientID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.ttl)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString([]byte(s.secretKey))
-	if err != nil {
-		return "", err
+	return s.keys.Sign(claims)
+}
+
+// GenerateImpersonationToken menerbitkan token atas nama userID yang dipakai
+// actorID. TTL-nya sendiri dan tidak pernah disertai refresh token.
+func (s *jwtService) GenerateImpersonationToken(userID, actorID, sessionID string, permissionVersion *int64, ttl time.Duration) (string, error) {
+	claims := JWTClaims{
+		UserID:            userID,
+		SessionID:         sessionID,
+		PermissionVersion: permissionVersion,
+		Actor:             &ActorClaim{Subject: actorID},
+		TokenType:         AccessTokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    s.issuer,
+			Subject:   userID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
 	}
 
-	return signedToken, nil
+	return s.keys.Sign(claims)
 }
 
 func (s *jwtService) ValidateToken(tokenString string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		// Check the signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(s.secretKey), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, s.keys.KeyFunc,
+		jwt.WithValidMethods([]string{"RS256", "ES256"}),
+		jwt.WithIssuer(s.issuer),
+		jwt.WithExpirationRequired(),
+	)
 
 	if err != nil {
 		return nil, err
 	}
 
 	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
+		if claims.TokenType != AccessTokenType || (claims.UserID == "" && claims.ClientID == "") {
+			return nil, ErrInvalidAccessToken
+		}
 		return claims, nil
 	}
 
 	return nil, fmt.Errorf("invalid token")
-}
\ No newline at end of file
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"crypto/tls"
+	"errors"
+	"jti-super-app-go/config"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+var (
+	ErrDirectoryUserNotFound       = errors.New("user not found in directory")
+	ErrDirectoryInvalidCredentials = errors.New("invalid directory credentials")
+)
+
+// DirectoryUser adalah atribut user yang dibaca dari LDAP.
+type DirectoryUser struct {
+	DN    string
+	Email string
+	Name  string
+}
+
+type LDAPService interface {
+	// Authenticate mencari user berdasarkan email lalu memverifikasi password
+	// dengan bind sebagai DN user tersebut.
+	Authenticate(email, password string) (*DirectoryUser, error)
+}
+
+type ldapService struct {
+	cfg config.LDAPConfig
+}
+
+func NewLDAPService(cfg config.LDAPConfig) LDAPService {
+	return &ldapService{cfg: cfg}
+}
+
+func (s *ldapService) Authenticate(email, password string) (*DirectoryUser, error) {
+	if password == "" {
+		return nil, ErrDirectoryInvalidCredentials
+	}
+
+	u, err := url.Parse(s.cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{ServerName: u.Hostname(), InsecureSkipVerify: s.cfg.InsecureSkipVerify}
+
+	conn, err := ldap.DialURL(s.cfg.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: s.cfg.Timeout}),
+		ldap.DialWithTLSConfig(tlsConfig),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetTimeout(s.cfg.Timeout)
+
+	if s.cfg.StartTLS && u.Scheme == "ldap" {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	// Tanpa BindDN search dilakukan secara anonim
+	if s.cfg.BindDN != "" {
+		if err := conn.Bind(s.cfg.BindDN, s.cfg.BindPassword); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := conn.Search(ldap.NewSearchRequest(
+		s.cfg.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, 0, false,
+		strings.ReplaceAll(s.cfg.UserFilter, "%s", ldap.EscapeFilter(email)),
+		[]string{s.cfg.EmailAttribute, s.cfg.NameAttribute},
+		nil,
+	))
+	if err != nil {
+		return nil, err
+	}
+	// Email yang cocok dengan lebih dari satu entry dianggap tidak ditemukan
+	if len(result.Entries) != 1 {
+		return nil, ErrDirectoryUserNotFound
+	}
+	entry := result.Entries[0]
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrDirectoryInvalidCredentials
+		}
+		return nil, err
+	}
+
+	user := &DirectoryUser{
+		DN:    entry.DN,
+		Email: entry.GetEqualFoldAttributeValue(s.cfg.EmailAttribute),
+		Name:  entry.GetEqualFoldAttributeValue(s.cfg.NameAttribute),
+	}
+	if user.Email == "" {
+		user.Email = email
+	}
+	return user, nil
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"fmt"
+	"jti-super-app-go/config"
+	"jti-super-app-go/internal/dto"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+type IDTokenClaims struct {
+	Nonce         string   `json:"nonce,omitempty"`
+	AuthTime      int64    `json:"auth_time,omitempty"`
+	Email         string   `json:"email,omitempty"`
+	EmailVerified *bool    `json:"email_verified,omitempty"`
+	Name          string   `json:"name,omitempty"`
+	Roles         []string `json:"roles,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// LogoutTokenClaims mengikuti OpenID Connect Back-Channel Logout 1.0 section 2.4.
+type LogoutTokenClaims struct {
+	Events map[string]struct{} `json:"events"`
+	jwt.RegisteredClaims
+}
+
+const (
+	backchannelLogoutEvent   = "http://schemas.openid.net/event/backchannel-logout"
+	logoutTokenTTL           = 2 * time.Minute
+	backchannelLogoutTimeout = 5 * time.Second
+)
+
+type OIDCService interface {
+	Issuer() string
+	GenerateIDToken(claims IDTokenClaims, ttl time.Duration) (string, error)
+	SendBackchannelLogout(logoutURI, clientID, subject string) error
+	JWKS() dto.JWKSetDTO
+	Discovery() dto.OpenIDConfigurationDTO
+}
+
+type oidcService struct {
+	issuer string
+	keys   SigningKeyService
+}
+
+func NewOIDCService(keys SigningKeyService) OIDCService {
+	return &oidcService{
+		issuer: config.AppConfig.OIDC.Issuer,
+		keys:   keys,
+	}
+}
+
+func (s *oidcService) Issuer() string {
+	return s.issuer
+}
+
+func (s *oidcService) GenerateIDToken(claims IDTokenClaims, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims.Issuer = s.issuer
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(ttl))
+
+	return s.keys.Sign(claims)
+}
+
+// SendBackchannelLogout mengirim logout token yang ditandatangani ke client
+// agar client mengakhiri sesinya sendiri.
+func (s *oidcService) SendBackchannelLogout(logoutURI, clientID, subject string) error {
+	now := time.Now()
+	logoutToken, err := s.keys.Sign(LogoutTokenClaims{
+		Events: map[string]struct{}{backchannelLogoutEvent: {}},
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    s.issuer,
+			Subject:   subject,
+			Audience:  jwt.ClaimStrings{clientID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(logoutTokenTTL)),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: backchannelLogoutTimeout}
+	res, err := client.PostForm(logoutURI, url.Values{"logout_token": {logoutToken}})
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("back-channel logout to %s returned status %d", logoutURI, res.StatusCode)
+	}
+	return nil
+}
+
+func (s *oidcService) JWKS() dto.JWKSetDTO {
+	return s.keys.JWKS()
+}
+
+func (s *oidcService) Discovery() dto.OpenIDConfigurationDTO {
+	baseURL := config.AppConfig.AppUrl
+	return dto.OpenIDConfigurationDTO{
+		Issuer:                            s.issuer,
+		AuthorizationEndpoint:             baseURL + "/api/v1/oauth/authorize",
+		TokenEndpoint:                     baseURL + "/api/v1/oauth/token",
+		UserinfoEndpoint:                  baseURL + "/api/v1/oauth/userinfo",
+		JwksURI:                           baseURL + "/.well-known/jwks.json",
+		EndSessionEndpoint:                baseURL + "/api/v1/oauth/logout",
+		IntrospectionEndpoint:             baseURL + "/api/v1/oauth/introspect",
+		RevocationEndpoint:                baseURL + "/api/v1/oauth/revoke",
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{"authorization_code", "refresh_token", "client_credentials"},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{s.keys.Algorithm()},
+		ScopesSupported:                   []string{"openid", "profile", "email", "academic", "roles"},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+		FrontchannelLogoutSupported:       true,
+		BackchannelLogoutSupported:        true,
+		ClaimsSupported:                   []string{"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "name", "picture", "email", "email_verified", "roles", "permissions", "academic"},
+	}
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"jti-super-app-go/config"
+	"jti-super-app-go/internal/dto"
+	"log"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	PasswordViolationTooShort        = "too_short"
+	PasswordViolationTooLong         = "too_long"
+	PasswordViolationMissingUpper    = "missing_uppercase"
+	PasswordViolationMissingLower    = "missing_lowercase"
+	PasswordViolationMissingDigit    = "missing_digit"
+	PasswordViolationMissingSymbol   = "missing_symbol"
+	PasswordViolationPersonalInfo    = "contains_personal_info"
+	PasswordViolationBreached        = "breached"
+	PasswordViolationRecentlyUsed    = "recently_used"
+	passwordMaxBytes                 = 72 // batas input bcrypt
+	passwordPersonalInfoMinimumRunes = 3
+)
+
+type PasswordPolicyService interface {
+	// Check mengembalikan semua aturan yang dilanggar password. personal berisi
+	// data diri (NIM, NIP, nama, email) yang tidak boleh muncul di dalam password.
+	Check(password string, personal ...string) []dto.PasswordViolationDTO
+	// HistorySize adalah jumlah password lama yang tidak boleh dipakai ulang.
+	HistorySize() int
+}
+
+type passwordPolicyService struct {
+	cfg      config.PasswordPolicyConfig
+	breached map[[sha1.Size]byte]struct{}
+}
+
+func NewPasswordPolicyService(cfg config.PasswordPolicyConfig) PasswordPolicyService {
+	s := &passwordPolicyService{cfg: cfg}
+	if cfg.BreachedListPath != "" {
+		breached, err := loadBreachedPasswords(cfg.BreachedListPath)
+		if err != nil {
+			log.Printf("Failed to load breached password list: %v", err)
+		} else {
+			log.Printf("Loaded %d breached password hashes", len(breached))
+			s.breached = breached
+		}
+	}
+	return s
+}
+
+func (s *passwordPolicyService) HistorySize() int {
+	return s.cfg.HistorySize
+}
+
+func (s *passwordPolicyService) Check(password string, personal ...string) []dto.PasswordViolationDTO {
+	violations := []dto.PasswordViolationDTO{}
+	add := func(code, message string) {
+		violations = append(violations, dto.PasswordViolationDTO{Code: code, Message: message})
+	}
+
+	if utf8.RuneCountInString(password) < s.cfg.MinLength {
+		add(PasswordViolationTooShort, fmt.Sprintf("password must be at least %d characters", s.cfg.MinLength))
+	}
+	if len(password) > passwordMaxBytes {
+		add(PasswordViolationTooLong, fmt.Sprintf("password must not exceed %d bytes", passwordMaxBytes))
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	if s.cfg.RequireUpper && !upper {
+		add(PasswordViolationMissingUpper, "password must contain an uppercase letter")
+	}
+	if s.cfg.RequireLower && !lower {
+		add(PasswordViolationMissingLower, "password must contain a lowercase letter")
+	}
+	if s.cfg.RequireDigit && !digit {
+		add(PasswordViolationMissingDigit, "password must contain a digit")
+	}
+	if s.cfg.RequireSymbol && !symbol {
+		add(PasswordViolationMissingSymbol, "password must contain a symbol")
+	}
+
+	if containsPersonalInfo(password, personal) {
+		add(PasswordViolationPersonalInfo, "password must not contain your NIM, NIP, name or email")
+	}
+
+	if s.breached != nil {
+		if _, found := s.breached[sha1.Sum([]byte(password))]; found {
+			add(PasswordViolationBreached, "password has appeared in a data breach, choose a different one")
+		}
+	}
+
+	return violations
+}
+
+// containsPersonalInfo membandingkan tanpa memperhatikan huruf besar/kecil.
+// Nama dipecah per kata sehingga "budi" ditolak untuk user "Budi Santoso".
+func containsPersonalInfo(password string, personal []string) bool {
+	lowered := strings.ToLower(password)
+	for _, value := range personal {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if local, _, ok := strings.Cut(value, "@"); ok {
+			value = local
+		}
+
+		parts := append([]string{value}, strings.FieldsFunc(value, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})...)
+		for _, part := range parts {
+			if utf8.RuneCountInString(part) >= passwordPersonalInfoMinimumRunes && strings.Contains(lowered, part) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func loadBreachedPasswords(path string) (map[[sha1.Size]byte]struct{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	breached := make(map[[sha1.Size]byte]struct{})
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if len(line) != hex.EncodedLen(sha1.Size) {
+			continue
+		}
+
+		var hash [sha1.Size]byte
+		if _, err := hex.Decode(hash[:], []byte(line)); err != nil {
+			continue
+		}
+		breached[hash] = struct{}{}
+	}
+	return breached, scanner.Err()
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"jti-super-app-go/config"
+	"jti-super-app-go/internal/domain"
+	"jti-super-app-go/internal/dto"
+	"log"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const signingKeyRefreshInterval = time.Minute
+
+type SigningKeyService interface {
+	Sign(claims jwt.Claims) (string, error)
+	KeyFunc(token *jwt.Token) (interface{}, error)
+	JWKS() dto.JWKSetDTO
+	Algorithm() string
+	Rotate() (*domain.SigningKey, error)
+}
+
+type loadedSigningKey struct {
+	id         string
+	algorithm  string
+	status     string
+	retiringAt *time.Time
+	private    crypto.Signer
+}
+
+type signingKeyService struct {
+	repo      domain.SigningKeyRepository
+	algorithm string
+	retention time.Duration
+
+	mu       sync.RWMutex
+	keys     []loadedSigningKey
+	loadedAt time.Time
+}
+
+func NewSigningKeyService(repo domain.SigningKeyRepository) SigningKeyService {
+	cfg := config.AppConfig
+	s := &signingKeyService{
+		repo:      repo,
+		algorithm: cfg.JWTSigningAlg,
+		retention: time.Duration(cfg.JWTKeyRetentionHours) * time.Hour,
+	}
+
+	if err := s.reload(true); err != nil {
+		log.Fatalf("Failed to load JWT signing keys: %v", err)
+	}
+
+	return s
+}
+
+func (s *signingKeyService) Algorithm() string {
+	return s.algorithm
+}
+
+func (s *signingKeyService) Sign(claims jwt.Claims) (string, error) {
+	key, err := s.activeKey()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.algorithm), claims)
+	token.Header["kid"] = key.id
+	return token.SignedString(key.private)
+}
+
+// KeyFunc dipakai oleh jwt.Parse untuk memilih public key berdasarkan header `kid`.
+func (s *signingKeyService) KeyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("token has no key id")
+	}
+
+	key, ok := s.findKey(kid)
+	if !ok {
+		// Key bisa saja baru dirotasi oleh instance lain
+		_ = s.reload(false)
+		if key, ok = s.findKey(kid); !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+	}
+
+	if token.Method.Alg() != key.algorithm {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.private.Public(), nil
+}
+
+func (s *signingKeyService) JWKS() dto.JWKSetDTO {
+	s.refreshIfStale()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	set := dto.JWKSetDTO{Keys: []dto.JWKDTO{}}
+	for _, key := range s.keys {
+		jwk := dto.JWKDTO{Use: "sig", Alg: key.algorithm, Kid: key.id}
+		switch pub := key.private.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case *ecdsa.PublicKey:
+			jwk.Kty = "EC"
+			jwk.Crv = "P-256"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, 32)))
+			jwk.Y = base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, 32)))
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+func (s *signingKeyService) Rotate() (*domain.SigningKey, error) {
+	key, err := generateSigningKey(s.algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Rotate(key, time.Now().Add(-s.retention)); err != nil {
+		return nil, err
+	}
+
+	if err := s.reload(false); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (s *signingKeyService) activeKey() (loadedSigningKey, error) {
+	s.refreshIfStale()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, key := range s.keys {
+		if key.status == domain.SigningKeyStatusActive {
+			return key, nil
+		}
+	}
+	return loadedSigningKey{}, errors.New("no active signing key")
+}
+
+func (s *signingKeyService) findKey(kid string) (loadedSigningKey, bool) {
+	s.refreshIfStale()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, key := range s.keys {
+		if key.id == kid {
+			return key, true
+		}
+	}
+	return loadedSigningKey{}, false
+}
+
+func (s *signingKeyService) refreshIfStale() {
+	s.mu.RLock()
+	stale := time.Since(s.loadedAt) > signingKeyRefreshInterval
+	s.mu.RUnlock()
+
+	if stale {
+		if err := s.reload(false); err != nil {
+			log.Println("Failed to refresh JWT signing keys:", err)
+		}
+	}
+}
+
+func (s *signingKeyService) reload(bootstrap bool) error {
+	rows, err := s.repo.FindUsable()
+	if err != nil {
+		return err
+	}
+
+	keys := make([]loadedSigningKey, 0, len(*rows))
+	hasActive := false
+	for _, row := range *rows {
+		// Key RETIRING yang sudah melewati masa retensi tidak lagi diterima
+		if row.Status == domain.SigningKeyStatusRetiring && row.RetiringAt != nil && time.Since(*row.RetiringAt) > s.retention {
+			continue
+		}
+
+		private, err := parsePrivateKeyPEM(row.PrivateKey)
+		if err != nil {
+			return fmt.Errorf("signing key %s: %w", row.ID, err)
+		}
+
+		if row.Status == domain.SigningKeyStatusActive {
+			hasActive = true
+		}
+		keys = append(keys, loadedSigningKey{
+			id:         row.ID,
+			algorithm:  row.Algorithm,
+			status:     row.Status,
+			retiringAt: row.RetiringAt,
+			private:    private,
+		})
+	}
+
+	if !hasActive && bootstrap {
+		log.Println("No active JWT signing key found, generating a new one")
+		_, err := s.Rotate()
+		return err
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	return nil
+}
+
+func generateSigningKey(algorithm string) (*domain.SigningKey, error) {
+	var private crypto.Signer
+	var err error
+
+	switch algorithm {
+	case "RS256":
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	case "ES256":
+		private, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.SigningKey{
+		ID:         keyID(private.Public()),
+		Algorithm:  algorithm,
+		PrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		Status:     domain.SigningKeyStatusActive,
+	}, nil
+}
+
+func parsePrivateKeyPEM(raw string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(raw))
+	if block == nil {
+		return nil, errors.New("invalid PEM data")
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported private key type")
+	}
+	return signer, nil
+}
+
+func keyID(pub crypto.PublicKey) string {
+	der, _ := x509.MarshalPKIXPublicKey(pub)
+	sum := sha256.Sum256(der)
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
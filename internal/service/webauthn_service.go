@@ -0,0 +1,221 @@
+package service
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"jti-super-app-go/config"
+	"jti-super-app-go/internal/domain"
+	"jti-super-app-go/internal/dto"
+	"strings"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+)
+
+const webAuthnTimeoutMs = 60000
+
+var ErrWebAuthnVerification = errors.New("passkey verification failed")
+
+// WebAuthnAttestedCredential adalah credential baru hasil ceremony registrasi.
+type WebAuthnAttestedCredential struct {
+	CredentialID []byte
+	PublicKey    []byte // COSE_Key apa adanya
+	Algorithm    int
+	SignCount    uint32
+	AAGUID       string
+	Transports   []string
+}
+
+type WebAuthnService interface {
+	NewChallenge() string
+	CreationOptions(challenge string, user *domain.User, exclude []domain.WebAuthnCredential) *dto.WebAuthnCreationOptionsDTO
+	RequestOptions(challenge string, allow []domain.WebAuthnCredential) *dto.WebAuthnRequestOptionsDTO
+	VerifyRegistration(challenge string, credential dto.WebAuthnCredentialDTO) (*WebAuthnAttestedCredential, error)
+	// VerifyAssertion memeriksa tanda tangan terhadap passkey yang tersimpan dan
+	// mengembalikan sign count baru.
+	VerifyAssertion(challenge string, credential dto.WebAuthnCredentialDTO, stored *domain.WebAuthnCredential) (uint32, error)
+}
+
+type webAuthnService struct {
+	cfg config.WebAuthnConfig
+}
+
+func NewWebAuthnService(cfg config.WebAuthnConfig) WebAuthnService {
+	return &webAuthnService{cfg: cfg}
+}
+
+func (s *webAuthnService) NewChallenge() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func (s *webAuthnService) CreationOptions(challenge string, user *domain.User, exclude []domain.WebAuthnCredential) *dto.WebAuthnCreationOptionsDTO {
+	return &dto.WebAuthnCreationOptionsDTO{
+		Challenge: challenge,
+		RP:        dto.WebAuthnRelyingPartyDTO{ID: s.cfg.RPID, Name: s.cfg.RPName},
+		User: dto.WebAuthnUserEntityDTO{
+			ID:          base64.RawURLEncoding.EncodeToString([]byte(user.ID)),
+			Name:        user.Email,
+			DisplayName: user.Name,
+		},
+		PubKeyCredParams:   credentialParameterDTOs(),
+		Timeout:            webAuthnTimeoutMs,
+		ExcludeCredentials: descriptors(exclude),
+		AuthenticatorSelection: dto.WebAuthnAuthenticatorSelectionDTO{
+			// Resident key dibutuhkan agar passkey bisa dipakai tanpa mengetik email
+			ResidentKey:      "preferred",
+			UserVerification: "required",
+		},
+		Attestation: "none",
+	}
+}
+
+func (s *webAuthnService) RequestOptions(challenge string, allow []domain.WebAuthnCredential) *dto.WebAuthnRequestOptionsDTO {
+	return &dto.WebAuthnRequestOptionsDTO{
+		Challenge:        challenge,
+		RPID:             s.cfg.RPID,
+		Timeout:          webAuthnTimeoutMs,
+		AllowCredentials: descriptors(allow),
+		UserVerification: "required",
+	}
+}
+
+func (s *webAuthnService) VerifyRegistration(challenge string, credential dto.WebAuthnCredentialDTO) (*WebAuthnAttestedCredential, error) {
+	raw, err := json.Marshal(credential)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(raw)
+	if err != nil {
+		return nil, verificationFailed(err)
+	}
+
+	// Format attestation selain "none" tetap diverifikasi oleh library, tetapi
+	// identitas authenticator tidak dipakai untuk keputusan apa pun.
+	clientDataHash, err := parsed.Verify(challenge, true, true, s.cfg.RPID, s.origins(), nil, protocol.TopOriginIgnoreVerificationMode, nil, credentialParameters())
+	if err != nil {
+		return nil, verificationFailed(err)
+	}
+
+	attested, err := webauthn.NewCredential(clientDataHash, parsed)
+	if err != nil {
+		return nil, verificationFailed(err)
+	}
+	if len(attested.ID) == 0 || !bytes.Equal(parsed.RawID, attested.ID) {
+		return nil, verificationError("credential id mismatch")
+	}
+
+	var key webauthncose.PublicKeyData
+	if err := webauthncbor.Unmarshal(attested.PublicKey, &key); err != nil {
+		return nil, verificationError("invalid credential public key")
+	}
+
+	aaguid := ""
+	if id, err := uuid.FromBytes(attested.Authenticator.AAGUID); err == nil && id != uuid.Nil {
+		aaguid = id.String()
+	}
+
+	return &WebAuthnAttestedCredential{
+		CredentialID: attested.ID,
+		PublicKey:    attested.PublicKey,
+		Algorithm:    int(key.Algorithm),
+		SignCount:    attested.Authenticator.SignCount,
+		AAGUID:       aaguid,
+		Transports:   credential.Response.Transports,
+	}, nil
+}
+
+func (s *webAuthnService) VerifyAssertion(challenge string, credential dto.WebAuthnCredentialDTO, stored *domain.WebAuthnCredential) (uint32, error) {
+	raw, err := json.Marshal(credential)
+	if err != nil {
+		return 0, err
+	}
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(raw)
+	if err != nil {
+		return 0, verificationFailed(err)
+	}
+
+	storedID, err := decodeB64URL(stored.CredentialID)
+	if err != nil || !bytes.Equal(parsed.RawID, storedID) {
+		return 0, verificationError("credential id mismatch")
+	}
+	coseKey, err := decodeB64URL(stored.PublicKey)
+	if err != nil {
+		return 0, verificationError("stored public key is corrupt")
+	}
+
+	if err := parsed.Verify(challenge, s.cfg.RPID, s.origins(), nil, protocol.TopOriginIgnoreVerificationMode, "", true, true, coseKey); err != nil {
+		return 0, verificationFailed(err)
+	}
+
+	// Sign count yang tidak naik menandakan authenticator kemungkinan dikloning.
+	// Authenticator yang tidak mendukung counter selalu mengirim 0.
+	authenticator := webauthn.Authenticator{SignCount: stored.SignCount}
+	authenticator.UpdateCounter(parsed.Response.AuthenticatorData.Counter)
+	if authenticator.CloneWarning {
+		return 0, verificationError("sign count did not increase, the authenticator may be cloned")
+	}
+
+	return authenticator.SignCount, nil
+}
+
+func (s *webAuthnService) origins() []string {
+	return []string{strings.TrimRight(s.cfg.Origin, "/")}
+}
+
+// credentialParameters adalah algoritma yang ditawarkan saat registrasi dan
+// diterima kembali saat attestation diverifikasi.
+func credentialParameters() []protocol.CredentialParameter {
+	return []protocol.CredentialParameter{
+		{Type: protocol.PublicKeyCredentialType, Algorithm: webauthncose.AlgES256},
+		{Type: protocol.PublicKeyCredentialType, Algorithm: webauthncose.AlgEdDSA},
+		{Type: protocol.PublicKeyCredentialType, Algorithm: webauthncose.AlgRS256},
+	}
+}
+
+func credentialParameterDTOs() []dto.WebAuthnCredentialParameterDTO {
+	params := credentialParameters()
+	result := make([]dto.WebAuthnCredentialParameterDTO, 0, len(params))
+	for _, p := range params {
+		result = append(result, dto.WebAuthnCredentialParameterDTO{Type: string(p.Type), Alg: int(p.Algorithm)})
+	}
+	return result
+}
+
+func descriptors(credentials []domain.WebAuthnCredential) []dto.WebAuthnCredentialDescriptorDTO {
+	result := make([]dto.WebAuthnCredentialDescriptorDTO, 0, len(credentials))
+	for _, c := range credentials {
+		result = append(result, dto.WebAuthnCredentialDescriptorDTO{
+			Type:       "public-key",
+			ID:         c.CredentialID,
+			Transports: c.Transports,
+		})
+	}
+	return result
+}
+
+func decodeB64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+func verificationError(reason string) error {
+	return fmt.Errorf("%w: %s", ErrWebAuthnVerification, reason)
+}
+
+// verificationFailed membungkus error dari library agar handler cukup memeriksa
+// ErrWebAuthnVerification.
+func verificationFailed(err error) error {
+	var protocolErr *protocol.Error
+	if errors.As(err, &protocolErr) && protocolErr.DevInfo != "" {
+		return verificationError(protocolErr.Details + ": " + protocolErr.DevInfo)
+	}
+	return verificationError(err.Error())
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"jti-super-app-go/config"
+	"jti-super-app-go/internal/domain"
+	"jti-super-app-go/internal/dto"
+	"math/big"
+	"testing"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+)
+
+const (
+	testRPID      = "example.com"
+	testOrigin    = "https://example.com"
+	testChallenge = "dGVzdC1jaGFsbGVuZ2U"
+)
+
+var testCredentialID = []byte("credential-1")
+
+func newTestWebAuthnService() WebAuthnService {
+	return NewWebAuthnService(config.WebAuthnConfig{RPID: testRPID, RPName: "Test", Origin: testOrigin})
+}
+
+// testAuthenticator menandatangani assertion dengan key ES256 atau RS256.
+type testAuthenticator struct {
+	signer  crypto.Signer
+	coseKey []byte
+}
+
+func newES256Authenticator(t *testing.T) *testAuthenticator {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testAuthenticator{
+		signer: key,
+		coseKey: cborEncode(t, map[int64]any{
+			1:  int64(2),
+			3:  int64(webauthncose.AlgES256),
+			-1: int64(1),
+			-2: key.X.FillBytes(make([]byte, 32)),
+			-3: key.Y.FillBytes(make([]byte, 32)),
+		}),
+	}
+}
+
+func newRS256Authenticator(t *testing.T) *testAuthenticator {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testAuthenticator{
+		signer: key,
+		coseKey: cborEncode(t, map[int64]any{
+			1:  int64(3),
+			3:  int64(webauthncose.AlgRS256),
+			-1: key.N.Bytes(),
+			-2: big.NewInt(int64(key.E)).Bytes(),
+		}),
+	}
+}
+
+func (a *testAuthenticator) sign(t *testing.T, authData, clientDataJSON []byte) []byte {
+	t.Helper()
+	hash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(append([]byte(nil), authData...), hash[:]...))
+	sig, err := a.signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sig
+}
+
+func testAuthData(rpID string, flags protocol.AuthenticatorFlags, signCount uint32, coseKey []byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	b := append([]byte(nil), rpIDHash[:]...)
+	b = append(b, byte(flags))
+	b = binary.BigEndian.AppendUint32(b, signCount)
+	if coseKey != nil {
+		b = append(b, make([]byte, 16)...) // AAGUID kosong
+		b = binary.BigEndian.AppendUint16(b, uint16(len(testCredentialID)))
+		b = append(b, testCredentialID...)
+		b = append(b, coseKey...)
+	}
+	return b
+}
+
+func testClientData(t *testing.T, ceremony, challenge, origin string) []byte {
+	t.Helper()
+	b, err := json.Marshal(map[string]string{"type": ceremony, "challenge": challenge, "origin": origin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// cborEncode mengenkode nilai test dengan encoder CBOR milik library WebAuthn.
+func cborEncode(t *testing.T, v any) []byte {
+	t.Helper()
+	b, err := webauthncbor.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestVerifyRegistration(t *testing.T) {
+	es256 := newES256Authenticator(t)
+	rs256 := newRS256Authenticator(t)
+	flags := protocol.FlagUserPresent | protocol.FlagUserVerified | protocol.FlagAttestedCredentialData
+
+	tests := []struct {
+		name      string
+		auth      *testAuthenticator
+		format    string
+		attStmt   map[string]any
+		authData  []byte
+		challenge string
+		rawID     []byte
+		wantAlg   int
+		wantErr   bool
+	}{
+		{name: "none attestation with ES256", auth: es256, format: "none", wantAlg: int(webauthncose.AlgES256)},
+		{name: "none attestation with RS256", auth: rs256, format: "none", wantAlg: int(webauthncose.AlgRS256)},
+		{
+			name:    "packed attestation with an invalid signature",
+			auth:    es256,
+			format:  "packed",
+			attStmt: map[string]any{"alg": int64(webauthncose.AlgES256), "sig": []byte{0x30, 0x00}},
+			wantErr: true,
+		},
+		{name: "none attestation with a statement", auth: es256, format: "none", attStmt: map[string]any{"alg": int64(webauthncose.AlgES256)}, wantErr: true},
+		{name: "rpIdHash mismatch", auth: es256, authData: testAuthData("evil.example", flags, 0, es256.coseKey), wantErr: true},
+		{name: "missing user presence", auth: es256, authData: testAuthData(testRPID, flags&^protocol.FlagUserPresent, 0, es256.coseKey), wantErr: true},
+		{name: "missing user verification", auth: es256, authData: testAuthData(testRPID, flags&^protocol.FlagUserVerified, 0, es256.coseKey), wantErr: true},
+		{name: "missing attested credential data", auth: es256, authData: testAuthData(testRPID, flags&^protocol.FlagAttestedCredentialData, 0, nil), wantErr: true},
+		{name: "challenge mismatch", auth: es256, challenge: "b3RoZXI", wantErr: true},
+		{name: "credential id mismatch", auth: es256, rawID: []byte("other"), wantErr: true},
+	}
+
+	svc := newTestWebAuthnService()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authData := tt.authData
+			if authData == nil {
+				authData = testAuthData(testRPID, flags, 0, tt.auth.coseKey)
+			}
+			attStmt := tt.attStmt
+			if attStmt == nil {
+				attStmt = map[string]any{}
+			}
+			format := tt.format
+			if format == "" {
+				format = "none"
+			}
+			challenge := tt.challenge
+			if challenge == "" {
+				challenge = testChallenge
+			}
+			rawID := tt.rawID
+			if rawID == nil {
+				rawID = testCredentialID
+			}
+
+			attestation := cborEncode(t, map[string]any{"fmt": format, "attStmt": attStmt, "authData": authData})
+			credential := dto.WebAuthnCredentialDTO{
+				ID:    b64(rawID),
+				RawID: b64(rawID),
+				Type:  "public-key",
+				Response: dto.WebAuthnAuthenticatorResponseDTO{
+					ClientDataJSON:    b64(testClientData(t, "webauthn.create", challenge, testOrigin)),
+					AttestationObject: b64(attestation),
+				},
+			}
+
+			got, err := svc.VerifyRegistration(testChallenge, credential)
+			if tt.wantErr {
+				if !errors.Is(err, ErrWebAuthnVerification) {
+					t.Fatalf("VerifyRegistration() error = %v, want ErrWebAuthnVerification", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyRegistration() error = %v", err)
+			}
+			if got.Algorithm != tt.wantAlg {
+				t.Errorf("Algorithm = %d, want %d", got.Algorithm, tt.wantAlg)
+			}
+			if string(got.PublicKey) != string(tt.auth.coseKey) {
+				t.Error("PublicKey does not match the attested COSE key")
+			}
+		})
+	}
+}
+
+func TestVerifyAssertion(t *testing.T) {
+	es256 := newES256Authenticator(t)
+	rs256 := newRS256Authenticator(t)
+	flags := protocol.FlagUserPresent | protocol.FlagUserVerified
+
+	tests := []struct {
+		name        string
+		auth        *testAuthenticator
+		rpID        string
+		flags       protocol.AuthenticatorFlags
+		signCount   uint32
+		storedCount uint32
+		origin      string
+		tamper      bool
+		wantErr     bool
+	}{
+		{name: "ES256", auth: es256, signCount: 5, storedCount: 4},
+		{name: "RS256", auth: rs256, signCount: 5, storedCount: 4},
+		{name: "authenticator without counter", auth: es256},
+		{name: "rpIdHash mismatch", auth: es256, rpID: "evil.example", signCount: 5, wantErr: true},
+		{name: "missing user presence", auth: es256, flags: protocol.FlagUserVerified, signCount: 5, wantErr: true},
+		{name: "sign count regression", auth: es256, signCount: 3, storedCount: 4, wantErr: true},
+		{name: "sign count not increased", auth: es256, signCount: 4, storedCount: 4, wantErr: true},
+		{name: "counter reset to zero", auth: es256, signCount: 0, storedCount: 4, wantErr: true},
+		{name: "origin mismatch", auth: es256, origin: "https://evil.example", signCount: 5, wantErr: true},
+		{name: "invalid signature", auth: rs256, tamper: true, signCount: 5, wantErr: true},
+	}
+
+	svc := newTestWebAuthnService()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rpID := tt.rpID
+			if rpID == "" {
+				rpID = testRPID
+			}
+			f := tt.flags
+			if f == 0 {
+				f = flags
+			}
+			origin := tt.origin
+			if origin == "" {
+				origin = testOrigin
+			}
+
+			authData := testAuthData(rpID, f, tt.signCount, nil)
+			clientData := testClientData(t, "webauthn.get", testChallenge, origin)
+			signature := tt.auth.sign(t, authData, clientData)
+			if tt.tamper {
+				signature[len(signature)-1] ^= 0xff
+			}
+
+			credential := dto.WebAuthnCredentialDTO{
+				ID:    b64(testCredentialID),
+				RawID: b64(testCredentialID),
+				Type:  "public-key",
+				Response: dto.WebAuthnAuthenticatorResponseDTO{
+					ClientDataJSON:    b64(clientData),
+					AuthenticatorData: b64(authData),
+					Signature:         b64(signature),
+				},
+			}
+			stored := &domain.WebAuthnCredential{
+				CredentialID: b64(testCredentialID),
+				PublicKey:    b64(tt.auth.coseKey),
+				SignCount:    tt.storedCount,
+			}
+
+			got, err := svc.VerifyAssertion(testChallenge, credential, stored)
+			if tt.wantErr {
+				if !errors.Is(err, ErrWebAuthnVerification) {
+					t.Fatalf("VerifyAssertion() error = %v, want ErrWebAuthnVerification", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyAssertion() error = %v", err)
+			}
+			if got != tt.signCount {
+				t.Errorf("VerifyAssertion() sign count = %d, want %d", got, tt.signCount)
+			}
+		})
+	}
+}
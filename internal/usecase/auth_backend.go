@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"jti-super-app-go/internal/domain"
+	"jti-super-app-go/internal/service"
+	"jti-super-app-go/pkg/constants"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var ErrAuthBackendRejected = errors.New("credentials rejected by authentication backend")
+
+// AuthBackend memverifikasi email dan password. Login mencoba setiap backend
+// secara berurutan dan berhenti pada backend pertama yang berhasil.
+type AuthBackend interface {
+	Name() string
+	Authenticate(email, password string) (*domain.User, error)
+}
+
+type localAuthBackend struct {
+	authRepo domain.AuthRepository
+}
+
+// NewLocalAuthBackend memverifikasi password bcrypt di tabel users.
+func NewLocalAuthBackend(authRepo domain.AuthRepository) AuthBackend {
+	return &localAuthBackend{authRepo: authRepo}
+}
+
+func (b *localAuthBackend) Name() string {
+	return "local"
+}
+
+func (b *localAuthBackend) Authenticate(email, password string) (*domain.User, error) {
+	user, err := b.authRepo.FindByEmail(email)
+	if err != nil {
+		return nil, ErrAuthBackendRejected
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return nil, ErrAuthBackendRejected
+	}
+	return user, nil
+}
+
+type ldapAuthBackend struct {
+	ldapService service.LDAPService
+	authRepo    domain.AuthRepository
+	userRepo    domain.UserRepository
+	provision   bool
+}
+
+// NewLDAPAuthBackend memverifikasi password ke direktori LDAP lalu
+// menyinkronkan user lokal. Bila provision aktif, user yang belum ada dibuat
+// tanpa role; admin tetap yang menentukan hak aksesnya.
+func NewLDAPAuthBackend(ldapService service.LDAPService, authRepo domain.AuthRepository, userRepo domain.UserRepository, provision bool) AuthBackend {
+	return &ldapAuthBackend{ldapService: ldapService, authRepo: authRepo, userRepo: userRepo, provision: provision}
+}
+
+func (b *ldapAuthBackend) Name() string {
+	return "ldap"
+}
+
+func (b *ldapAuthBackend) Authenticate(email, password string) (*domain.User, error) {
+	entry, err := b.ldapService.Authenticate(email, password)
+	if err != nil {
+		if !errors.Is(err, service.ErrDirectoryUserNotFound) && !errors.Is(err, service.ErrDirectoryInvalidCredentials) {
+			log.Printf("ldap authentication for %s failed: %v", email, err)
+		}
+		return nil, ErrAuthBackendRejected
+	}
+
+	user, err := b.authRepo.FindByEmail(entry.Email)
+	switch {
+	case err == nil:
+		return b.sync(user, entry)
+	case errors.Is(err, gorm.ErrRecordNotFound) && b.provision:
+		return b.provisionUser(entry)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, ErrAuthBackendRejected
+	default:
+		return nil, err
+	}
+}
+
+// sync memperbarui nama dan status verifikasi email dari direktori. Email di
+// direktori dikelola kampus sehingga dianggap sudah terverifikasi.
+func (b *ldapAuthBackend) sync(user *domain.User, entry *service.DirectoryUser) (*domain.User, error) {
+	updates := &domain.User{}
+	changed := false
+	if entry.Name != "" && entry.Name != user.Name {
+		updates.Name = entry.Name
+		changed = true
+	}
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		updates.EmailVerifiedAt = &now
+		changed = true
+	}
+	if !changed {
+		return user, nil
+	}
+
+	if _, err := b.userRepo.Update(user.ID, updates); err != nil {
+		return nil, err
+	}
+	return b.authRepo.FindByEmail(user.Email)
+}
+
+func (b *ldapAuthBackend) provisionUser(entry *service.DirectoryUser) (*domain.User, error) {
+	// Password lokal acak yang tidak diketahui siapa pun; user tetap login lewat
+	// LDAP atau mengaturnya sendiri lewat lupa password
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(base64.RawURLEncoding.EncodeToString(random)), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	name := entry.Name
+	if name == "" {
+		name = strings.Split(entry.Email, "@")[0]
+	}
+
+	now := time.Now()
+	imgPath := constants.EMPLOYEE_PATH
+	imgName := constants.DEFAULT_AVATAR
+	_, err = b.userRepo.Create(&domain.User{
+		ID:              uuid.NewString(),
+		Name:            name,
+		Email:           entry.Email,
+		EmailVerifiedAt: &now,
+		Password:        string(hashedPassword),
+		Status:          constants.StatusActive,
+		ImgPath:         &imgPath,
+		ImgName:         &imgName,
+		// Password dikelola direktori sehingga tidak ada password default yang wajib diganti
+		IsChangePassword: true,
+		CreatedAt:        &now,
+		UpdatedAt:        &now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.authRepo.FindByEmail(entry.Email)
+}
@@ -13,123 +13,236 @@ import (
 	"jti-super-app-go/internal/service"
 	"jti-super-app-go/pkg/constants"
 	"jti-super-app-go/pkg/helper"
+	"log"
 	"slices"
+	"strings"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
+var ErrInvalidCurrentPassword = errors.New("current password is incorrect")
+
+// AccountLockedError dikembalikan selama akun dikunci karena password salah berulang kali.
+type AccountLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *AccountLockedError) Error() string {
+	minutes := int(e.RetryAfter.Round(time.Minute).Minutes())
+	if minutes < 1 {
+		minutes = 1
+	}
+	return fmt.Sprintf("too many failed login attempts, try again in %d minute(s)", minutes)
+}
+
 type AuthUseCase interface {
 	Login(req dto.LoginRequestDTO) (*dto.LoginResponseDTO, error)
-	LoginWithGoogle(userInfo *service.GoogleUserInfo) (*dto.LoginResponseDTO, error)
+	// LoginWithExternalIdentity melanjutkan login user yang sudah diverifikasi IdP upstream.
+	LoginWithExternalIdentity(user *domain.User) (*dto.LoginResponseDTO, error)
+	VerifyTwoFactor(req dto.TwoFactorLoginRequestDTO) (*dto.LoginResponseDTO, error)
+	VerifyTwoFactorPasskey(req dto.WebAuthnSecondFactorFinishRequestDTO) (*dto.LoginResponseDTO, error)
+	LoginWithPasskey(req dto.WebAuthnLoginFinishRequestDTO) (*dto.LoginResponseDTO, error)
+	Refresh(refreshToken string) (*dto.TokenPairDTO, error)
 	Logout(tokenString string) error
-	VerifyEmail(token string) error
-	ResendVerificationEmail(email string) error
 	ForgotPassword(req dto.ForgotPasswordRequestDTO) error
-	ResetPassword(req dto.ResetPasswordRequestDTO) error
+	// ResetPassword mengganti password lewat token email dan mengembalikan ID
+	// user agar pemanggil bisa mengakhiri sesinya.
+	ResetPassword(req dto.ResetPasswordRequestDTO) (string, error)
+	ChangePassword(userID string, req dto.ChangePasswordRequestDTO) error
 	Me(userID string) (*dto.UserDetailInfoDTO, error)
 }
 
 type authUseCase struct {
-	authRepo      domain.AuthRepository
-	userRepo      domain.UserRepository
-	employeeRepo  domain.EmployeeRepository
-	studentRepo   domain.StudentRepository
-	passResetRepo domain.PasswordResetRepository
-	jwtService    service.JWTService
-	emailService  service.EmailService
+	authRepo        domain.AuthRepository
+	userRepo        domain.UserRepository
+	employeeRepo    domain.EmployeeRepository
+	studentRepo     domain.StudentRepository
+	passResetRepo   domain.PasswordResetRepository
+	jwtService      service.JWTService
+	emailService    service.EmailService
+	tokenUC         TokenUseCase
+	twoFactorUC     TwoFactorUseCase
+	webAuthnUC      WebAuthnUseCase
+	attemptRepo     domain.LoginAttemptRepository
+	passwordUC      PasswordUseCase
+	verificationUC  EmailVerificationUseCase
+	authorizationUC AuthorizationUseCase
+	backends        []AuthBackend
 }
 
-func NewAuthUseCase(authRepo domain.AuthRepository, userRepo domain.UserRepository, employeeRepo domain.EmployeeRepository, studentRepo domain.StudentRepository, passResetRepo domain.PasswordResetRepository, jwtService service.JWTService, emailService service.EmailService) AuthUseCase {
+func NewAuthUseCase(authRepo domain.AuthRepository, userRepo domain.UserRepository, employeeRepo domain.EmployeeRepository, studentRepo domain.StudentRepository, passResetRepo domain.PasswordResetRepository, jwtService service.JWTService, emailService service.EmailService, tokenUC TokenUseCase, twoFactorUC TwoFactorUseCase, webAuthnUC WebAuthnUseCase, attemptRepo domain.LoginAttemptRepository, passwordUC PasswordUseCase, verificationUC EmailVerificationUseCase, authorizationUC AuthorizationUseCase, backends []AuthBackend) AuthUseCase {
 	return &authUseCase{
-		authRepo:      authRepo,
-		userRepo:      userRepo,
-		employeeRepo:  employeeRepo,
-		studentRepo:   studentRepo,
-		passResetRepo: passResetRepo,
-		jwtService:    jwtService,
-		emailService:  emailService,
+		authRepo:        authRepo,
+		userRepo:        userRepo,
+		employeeRepo:    employeeRepo,
+		studentRepo:     studentRepo,
+		passResetRepo:   passResetRepo,
+		jwtService:      jwtService,
+		emailService:    emailService,
+		tokenUC:         tokenUC,
+		twoFactorUC:     twoFactorUC,
+		webAuthnUC:      webAuthnUC,
+		attemptRepo:     attemptRepo,
+		passwordUC:      passwordUC,
+		verificationUC:  verificationUC,
+		authorizationUC: authorizationUC,
+		backends:        backends,
 	}
 }
 
 func (uc *authUseCase) Login(req dto.LoginRequestDTO) (*dto.LoginResponseDTO, error) {
-	user, err := uc.authRepo.FindByEmail(req.Email)
-	if err != nil {
-		return nil, errors.New("invalid email or password")
+	account := strings.ToLower(strings.TrimSpace(req.Email))
+	if lockedFor, err := uc.attemptRepo.LockedFor(account); err == nil && lockedFor > 0 {
+		return nil, &AccountLockedError{RetryAfter: lockedFor}
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
+	user, err := uc.authenticate(req.Email, req.Password)
 	if err != nil {
-		return nil, errors.New("invalid email or password")
+		return nil, uc.loginFailed(account)
 	}
+	_ = uc.attemptRepo.Reset(account)
 
 	if user.EmailVerifiedAt == nil {
-		go uc.ResendVerificationEmail(user.Email)
+		// Pengiriman dibatasi cooldown sehingga login berulang tidak membanjiri inbox
+		go uc.verificationUC.Send(user)
 
-		return nil, errors.New("please verify your email address, a new verification link has been sent")
+		return nil, errors.New("please verify your email address, check your inbox for the verification link")
 	}
 
-	var roleNames []string
-	var permissionNames []string
-	permissionSet := make(map[string]struct{})
+	return uc.completeLogin(user)
+}
 
-	for _, role := range user.Roles {
-		roleNames = append(roleNames, role.Name)
-		for _, perm := range role.Permissions {
-			permissionSet[perm.Name] = struct{}{}
+// authenticate mencoba setiap backend secara berurutan, mis. LDAP lalu
+// password lokal, dan mengembalikan user dari backend pertama yang berhasil.
+func (uc *authUseCase) authenticate(email, password string) (*domain.User, error) {
+	for _, backend := range uc.backends {
+		user, err := backend.Authenticate(email, password)
+		if err == nil {
+			return user, nil
+		}
+		if !errors.Is(err, ErrAuthBackendRejected) {
+			log.Printf("auth backend %s: %v", backend.Name(), err)
 		}
 	}
+	return nil, ErrAuthBackendRejected
+}
 
-	for perm := range permissionSet {
-		permissionNames = append(permissionNames, perm)
+// loginFailed mencatat password yang salah dan mengunci akun setelah melewati
+// ambang batas. Setiap kegagalan berikutnya menggandakan durasi kunci.
+func (uc *authUseCase) loginFailed(account string) error {
+	invalid := errors.New("invalid email or password")
+
+	cfg := config.AppConfig.LoginLockout
+	if cfg.Threshold <= 0 {
+		return invalid
+	}
+
+	failures, err := uc.attemptRepo.RecordFailure(account, cfg.ResetAfter)
+	if err != nil || failures < cfg.Threshold {
+		return invalid
+	}
+
+	duration := cfg.BaseDuration << min(failures-cfg.Threshold, 16)
+	if duration <= 0 || duration > cfg.MaxDuration {
+		duration = cfg.MaxDuration
+	}
+	if err := uc.attemptRepo.Lock(account, duration); err != nil {
+		return invalid
+	}
+	return &AccountLockedError{RetryAfter: duration}
+}
+
+func (uc *authUseCase) LoginWithExternalIdentity(user *domain.User) (*dto.LoginResponseDTO, error) {
+	return uc.completeLogin(user)
+}
+
+// VerifyTwoFactor menyelesaikan login yang tertahan di langkah 2FA.
+func (uc *authUseCase) VerifyTwoFactor(req dto.TwoFactorLoginRequestDTO) (*dto.LoginResponseDTO, error) {
+	userID, err := uc.twoFactorUC.VerifyChallenge(req.ChallengeToken, req.Code)
+	if err != nil {
+		return nil, err
 	}
 
-	token, err := uc.jwtService.GenerateToken(user.ID, roleNames, permissionNames)
+	user, err := uc.userRepo.FindByID(userID)
 	if err != nil {
-		return nil, errors.New("could not generate token")
+		return nil, ErrInvalidTwoFactorChallenge
 	}
 
-	return &dto.LoginResponseDTO{
-		Token: token,
-		User: dto.UserLoginInfo{
-			ID:               user.ID,
-			Name:             user.Name,
-			Email:            user.Email,
-			IsChangePassword: user.IsChangePassword,
-			Roles:            roleNames,
-			Permissions:      permissionNames,
-		},
-	}, nil
+	return uc.loginResponse(user)
 }
 
-func (uc *authUseCase) LoginWithGoogle(userInfo *service.GoogleUserInfo) (*dto.LoginResponseDTO, error) {
-	user, err := uc.authRepo.FindByEmail(userInfo.Email)
+// VerifyTwoFactorPasskey menyelesaikan langkah 2FA dengan passkey.
+func (uc *authUseCase) VerifyTwoFactorPasskey(req dto.WebAuthnSecondFactorFinishRequestDTO) (*dto.LoginResponseDTO, error) {
+	userID, err := uc.webAuthnUC.FinishSecondFactor(req.ChallengeToken, req.SessionID, req.BrowserID, req.Credential)
 	if err != nil {
-		return nil, errors.New("email not registered")
+		return nil, err
 	}
 
-	var roleNames []string
-	var permissionNames []string
-	permissionSet := make(map[string]struct{})
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, ErrInvalidTwoFactorChallenge
+	}
 
-	for _, role := range user.Roles {
-		roleNames = append(roleNames, role.Name)
-		for _, perm := range role.Permissions {
-			permissionSet[perm.Name] = struct{}{}
-		}
+	return uc.loginResponse(user)
+}
+
+// LoginWithPasskey adalah login passwordless. Passkey sudah mensyaratkan user
+// verification sehingga tidak perlu langkah 2FA lagi.
+func (uc *authUseCase) LoginWithPasskey(req dto.WebAuthnLoginFinishRequestDTO) (*dto.LoginResponseDTO, error) {
+	user, err := uc.webAuthnUC.FinishLogin(req.SessionID, req.BrowserID, req.Credential)
+	if err != nil {
+		return nil, err
 	}
 
-	for perm := range permissionSet {
-		permissionNames = append(permissionNames, perm)
+	return uc.loginResponse(user)
+}
+
+// completeLogin menerbitkan token, atau challenge 2FA jika user sudah
+// mengaktifkan TOTP atau mendaftarkan passkey.
+func (uc *authUseCase) completeLogin(user *domain.User) (*dto.LoginResponseDTO, error) {
+	totpEnabled, err := uc.twoFactorUC.IsEnabled(user.ID)
+	if err != nil {
+		return nil, errors.New("could not check two-factor authentication")
+	}
+	hasPasskeys, err := uc.webAuthnUC.HasPasskeys(user.ID)
+	if err != nil {
+		return nil, errors.New("could not check two-factor authentication")
+	}
+
+	var methods []string
+	if totpEnabled {
+		methods = append(methods, constants.TwoFactorMethodTOTP)
 	}
+	if hasPasskeys {
+		methods = append(methods, constants.TwoFactorMethodPasskey)
+	}
+
+	if len(methods) > 0 {
+		challengeToken, err := uc.twoFactorUC.Challenge(user.ID)
+		if err != nil {
+			return nil, errors.New("could not start two-factor authentication")
+		}
+		return &dto.LoginResponseDTO{ChallengeToken: challengeToken, ChallengeMethods: methods}, nil
+	}
+
+	return uc.loginResponse(user)
+}
+
+func (uc *authUseCase) loginResponse(user *domain.User) (*dto.LoginResponseDTO, error) {
+	roleNames, permissionNames := collectRolesAndPermissions(user)
 
-	token, err := uc.jwtService.GenerateToken(user.ID, roleNames, permissionNames)
+	tokens, err := uc.tokenUC.IssueForUser(user, "", "")
 	if err != nil {
 		return nil, errors.New("could not generate token")
 	}
 
 	return &dto.LoginResponseDTO{
-		Token: token,
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		Restrictions: tokens.Restrictions,
+		SessionID:    tokens.SessionID,
 		User: dto.UserLoginInfo{
 			ID:               user.ID,
 			Name:             user.Name,
@@ -141,23 +254,23 @@ func (uc *authUseCase) LoginWithGoogle(userInfo *service.GoogleUserInfo) (*dto.L
 	}, nil
 }
 
+func (uc *authUseCase) Refresh(refreshToken string) (*dto.TokenPairDTO, error) {
+	return uc.tokenUC.Refresh(refreshToken, "")
+}
+
 func (uc *authUseCase) Logout(tokenString string) error {
 	claims, err := uc.jwtService.ValidateToken(tokenString)
 	if err != nil {
 		return nil
 	}
 
-	remaining := time.Until(claims.ExpiresAt.Time)
-	if remaining <= 0 {
-		return nil
-	}
-
-	err = config.Rdb.Set(context.Background(), tokenString, "blacklisted", remaining).Err()
-	if err != nil {
-		return errors.New("failed to blacklist token")
+	if claims.SessionID != "" {
+		if err := uc.tokenUC.RevokeFamily(claims.SessionID); err != nil {
+			return errors.New("failed to revoke refresh token")
+		}
 	}
 
-	return nil
+	return uc.tokenUC.RevokeAccessToken(tokenString)
 }
 
 func (uc *authUseCase) ForgotPassword(req dto.ForgotPasswordRequestDTO) error {
@@ -198,102 +311,46 @@ func (uc *authUseCase) ForgotPassword(req dto.ForgotPasswordRequestDTO) error {
 	return nil
 }
 
-func (uc *authUseCase) ResetPassword(req dto.ResetPasswordRequestDTO) error {
+func (uc *authUseCase) ResetPassword(req dto.ResetPasswordRequestDTO) (string, error) {
 	pr, err := uc.passResetRepo.FindByTokenAndEmail(req.Token, req.Email)
 	if err != nil {
-		return errors.New("invalid or expired token")
+		return "", errors.New("invalid or expired token")
 	}
 
 	if time.Since(pr.CreatedAt) > time.Hour*1 {
 		uc.passResetRepo.Delete(req.Token)
-		return errors.New("invalid or expired token")
+		return "", errors.New("invalid or expired token")
 	}
 
 	user, err := uc.authRepo.FindByEmail(req.Email)
 	if err != nil {
-		return errors.New("user not found")
+		return "", errors.New("user not found")
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
-	if err != nil {
-		return err
-	}
-	user.Password = string(hashedPassword)
-	user.IsChangePassword = true
-
-	if _, err := uc.userRepo.Update(user.ID, user); err != nil {
-		return err
+	if err := uc.passwordUC.Change(user, req.Password); err != nil {
+		return "", err
 	}
 
 	uc.passResetRepo.Delete(req.Token)
+	// Reset password lewat email membuktikan kepemilikan akun sehingga kunci dilepas
+	_ = uc.attemptRepo.Reset(strings.ToLower(strings.TrimSpace(user.Email)))
 
-	return nil
+	return user.ID, nil
 }
 
-// Implementasi untuk Verifikasi Email
-func (uc *authUseCase) VerifyEmail(token string) error {
-	// Logika untuk verifikasi email. Anda bisa menggunakan Redis untuk menyimpan token verifikasi.
-	// Contoh:
-	email, err := config.Rdb.Get(context.Background(), "verify_email:"+token).Result()
-	if err != nil {
-		return errors.New("invalid or expired token")
-	}
-
-	user, err := uc.authRepo.FindByEmail(email)
-	if err != nil {
-		return err
-	}
-
-	now := time.Now()
-	user.EmailVerifiedAt = &now
-	if _, err := uc.userRepo.Update(user.ID, user); err != nil {
-		return err
-	}
-
-	// Hapus token dari Redis
-	config.Rdb.Del(context.Background(), "verify_email:"+token)
-	return nil
-}
-
-func (uc *authUseCase) ResendVerificationEmail(email string) error {
-	user, err := uc.authRepo.FindByEmail(email)
+// ChangePassword mengganti password user yang sedang login setelah password
+// lamanya diverifikasi.
+func (uc *authUseCase) ChangePassword(userID string, req dto.ChangePasswordRequestDTO) error {
+	user, err := uc.userRepo.FindByID(userID)
 	if err != nil {
 		return errors.New("user not found")
 	}
-	if user.EmailVerifiedAt != nil {
-		return errors.New("email already verified")
-	}
 
-	// Buat token baru
-	tokenBytes := make([]byte, 32)
-	if _, err := rand.Read(tokenBytes); err != nil {
-		return err
-	}
-	token := hex.EncodeToString(tokenBytes)
-
-	// Simpan ke Redis dengan TTL (misal: 15 menit)
-	err = config.Rdb.Set(context.Background(), "verify_email:"+token, user.Email, 15*time.Minute).Err()
-	if err != nil {
-		return err
-	}
-
-	verificationLink := "http://your-backend-app.com/api/v1/auth/email/verify/" + token
-	subject := "Verify Your Email Address"
-	body := fmt.Sprintf(`
-		<h1>Welcome to JTI Super App!</h1>
-		<p>Please verify your email address by clicking the link below:</p>
-		<a href="%s">Verify Email</a>
-		<p>This link will expire in 15 minutes.</p>
-	`, verificationLink)
-	if err := uc.emailService.SendEmail(user.Email, subject, body); err != nil {
-		return err
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		return ErrInvalidCurrentPassword
 	}
 
-	// TODO: Kirim email verifikasi ke pengguna
-	// Contoh: `http://frontend.com/verify-email?token=` + token
-	fmt.Println("Verification Email Token:", token) // Untuk debugging
-
-	return nil
+	return uc.passwordUC.Change(user, req.Password)
 }
 
 func (uc *authUseCase) Me(userID string) (*dto.UserDetailInfoDTO, error) {
@@ -306,6 +363,11 @@ func (uc *authUseCase) Me(userID string) (*dto.UserDetailInfoDTO, error) {
 	cached, err := config.Rdb.Get(context.Background(), cacheKey).Result()
 	if err == nil && cached != "" {
 		if err := json.Unmarshal([]byte(cached), &userInfo); err == nil {
+			// Cache profil tidak ikut diinvalidasi saat permission sebuah role
+			// berubah, jadi role dan permission selalu diambil dari set live
+			if set, err := uc.authorizationUC.Resolve(userID); err == nil {
+				userInfo.Roles, userInfo.Permissions = set.Roles, set.Permissions
+			}
 			return &userInfo, nil
 		}
 	}
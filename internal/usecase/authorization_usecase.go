@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"context"
+	"jti-super-app-go/config"
+	"jti-super-app-go/internal/domain"
+	"jti-super-app-go/internal/dto"
+	"jti-super-app-go/pkg/constants"
+	"log"
+	"slices"
+	"time"
+)
+
+// PermissionSetTTL membatasi umur cache; versi tetap menjadi penentu utama.
+const PermissionSetTTL = time.Hour
+
+// AuthorizationUseCase menyelesaikan role dan permission user secara live
+// sehingga perubahan role langsung berlaku tanpa menunggu token kedaluwarsa.
+type AuthorizationUseCase interface {
+	Resolve(userID string) (*domain.PermissionSet, error)
+	Version(userID string) (int64, error)
+	InvalidateUsers(userIDs ...string)
+	InvalidateAll()
+}
+
+type authorizationUseCase struct {
+	cacheRepo  domain.PermissionCacheRepository
+	userRepo   domain.UserRepository
+	scopedRepo domain.ScopedPermissionRepository
+}
+
+func NewAuthorizationUseCase(cacheRepo domain.PermissionCacheRepository, userRepo domain.UserRepository, scopedRepo domain.ScopedPermissionRepository) AuthorizationUseCase {
+	return &authorizationUseCase{cacheRepo: cacheRepo, userRepo: userRepo, scopedRepo: scopedRepo}
+}
+
+func (uc *authorizationUseCase) Resolve(userID string) (*domain.PermissionSet, error) {
+	// Versi dibaca sebelum memuat dari database; perubahan yang terjadi di
+	// antaranya menaikkan versi lagi sehingga set ini segera dimuat ulang
+	version, err := uc.cacheRepo.Version(userID)
+	if err != nil {
+		log.Printf("permission cache unavailable, loading from database: %v", err)
+		return uc.load(userID, 0)
+	}
+
+	if set, err := uc.cacheRepo.Get(userID); err == nil && set.Version == version {
+		return set, nil
+	}
+
+	set, err := uc.load(userID, version)
+	if err != nil {
+		return nil, err
+	}
+	_ = uc.cacheRepo.Set(userID, set, PermissionSetTTL)
+	return set, nil
+}
+
+func (uc *authorizationUseCase) load(userID string, version int64) (*domain.PermissionSet, error) {
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &domain.PermissionSet{Version: version, Roles: []string{}, Permissions: []string{}}
+	// User yang dinonaktifkan langsung kehilangan semua akses
+	if user.Status != constants.StatusActive {
+		return set, nil
+	}
+
+	roles, permissions := collectRolesAndPermissions(user)
+	if roles != nil {
+		set.Roles = roles
+	}
+	if permissions != nil {
+		set.Permissions = permissions
+	}
+
+	scopes, err := uc.loadScopes(userID, set.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	set.Scopes = scopes
+	return set, nil
+}
+
+// loadScopes mengelompokkan grant ber-scope per permission. Permission yang
+// sudah dimiliki tanpa batas lewat role tidak perlu dibatasi lagi.
+func (uc *authorizationUseCase) loadScopes(userID string, global []string) (map[string]dto.AccessScope, error) {
+	grants, err := uc.scopedRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := map[string]dto.AccessScope{}
+	for _, grant := range grants {
+		name := grant.Permission.Name
+		// Grant yang permission-nya sudah dihapus atau tidak mendukung scope diabaikan
+		if name == "" || slices.Contains(global, name) || !slices.Contains(constants.ScopablePermissions, name) {
+			continue
+		}
+
+		scope := scopes[name]
+		switch grant.ScopeType {
+		case constants.AccessScopeMajor:
+			studyProgramIDs, labIDs, err := uc.scopedRepo.ExpandMajors([]string{grant.ScopeID})
+			if err != nil {
+				return nil, err
+			}
+			scope.Merge(dto.AccessScope{MajorIDs: []string{grant.ScopeID}, StudyProgramIDs: studyProgramIDs, LabIDs: labIDs})
+		case constants.AccessScopeStudyProgram:
+			scope.Merge(dto.AccessScope{StudyProgramIDs: []string{grant.ScopeID}})
+		case constants.AccessScopeLab:
+			scope.Merge(dto.AccessScope{LabIDs: []string{grant.ScopeID}})
+		}
+		scopes[name] = scope
+	}
+
+	if len(scopes) == 0 {
+		return nil, nil
+	}
+	return scopes, nil
+}
+
+func (uc *authorizationUseCase) Version(userID string) (int64, error) {
+	return uc.cacheRepo.Version(userID)
+}
+
+// InvalidateUsers juga menghapus cache /auth/me yang ikut memuat role.
+func (uc *authorizationUseCase) InvalidateUsers(userIDs ...string) {
+	if err := uc.cacheRepo.BumpUsers(userIDs...); err != nil {
+		log.Printf("failed to invalidate permission cache: %v", err)
+	}
+	for _, id := range userIDs {
+		config.Rdb.Del(context.Background(), "user_info:"+id)
+	}
+}
+
+func (uc *authorizationUseCase) InvalidateAll() {
+	if err := uc.cacheRepo.BumpAll(); err != nil {
+		log.Printf("failed to invalidate permission cache: %v", err)
+	}
+}
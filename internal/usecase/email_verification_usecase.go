@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"jti-super-app-go/config"
+	"jti-super-app-go/internal/domain"
+	"jti-super-app-go/internal/dto"
+	"jti-super-app-go/internal/service"
+	"jti-super-app-go/pkg/constants"
+	"jti-super-app-go/pkg/helper"
+	"log"
+	"time"
+)
+
+var (
+	ErrEmailAlreadyVerified     = errors.New("email already verified")
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification link")
+)
+
+// VerificationThrottledError dikembalikan bila link verifikasi diminta terlalu sering.
+type VerificationThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *VerificationThrottledError) Error() string {
+	return fmt.Sprintf("verification email was sent recently, try again in %d second(s)", int(e.RetryAfter.Seconds())+1)
+}
+
+type EmailVerificationUseCase interface {
+	// Send membuat token baru dan mengirim email verifikasi ke user, dengan throttling.
+	Send(user *domain.User) error
+	Resend(email string) error
+	Verify(token string) error
+	// SendToCohort mengirim email verifikasi di background ke semua mahasiswa
+	// satu angkatan yang belum terverifikasi dan mengembalikan jumlah antrean.
+	SendToCohort(req dto.CohortVerificationRequestDTO, scope *dto.AccessScope) (int, error)
+}
+
+type emailVerificationUseCase struct {
+	repo         domain.EmailVerificationRepository
+	authRepo     domain.AuthRepository
+	userRepo     domain.UserRepository
+	studentRepo  domain.StudentRepository
+	emailService service.EmailService
+}
+
+func NewEmailVerificationUseCase(repo domain.EmailVerificationRepository, authRepo domain.AuthRepository, userRepo domain.UserRepository, studentRepo domain.StudentRepository, emailService service.EmailService) EmailVerificationUseCase {
+	return &emailVerificationUseCase{
+		repo:         repo,
+		authRepo:     authRepo,
+		userRepo:     userRepo,
+		studentRepo:  studentRepo,
+		emailService: emailService,
+	}
+}
+
+func (uc *emailVerificationUseCase) Send(user *domain.User) error {
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+
+	cfg := config.AppConfig.EmailVerification
+	retryAfter, err := uc.repo.Throttle(user.ID, cfg.ResendCooldown, cfg.DailyLimit)
+	if err != nil {
+		return err
+	}
+	if retryAfter > 0 {
+		return &VerificationThrottledError{RetryAfter: retryAfter}
+	}
+
+	token := helper.GenCode()
+	if err := uc.repo.Create(user.ID, hashToken(token), cfg.TTL); err != nil {
+		return err
+	}
+
+	data := dto.EmailTemplateAuthDataDto{
+		Name:      user.Name,
+		Link:      config.AppConfig.AppUrl + "/api/v1/auth/email/verify/" + token,
+		LogoURL:   helper.GetUrlFile(constants.EMPLOYEE_PATH, constants.DEFAULT_AVATAR),
+		ExpiresIn: humanizeDuration(cfg.TTL),
+	}
+	return uc.emailService.SendEmailWithTemplate(user.Email, "Verify Your Email Address", "templates/auth/verification.html", data)
+}
+
+func (uc *emailVerificationUseCase) Resend(email string) error {
+	user, err := uc.authRepo.FindByEmail(email)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	return uc.Send(user)
+}
+
+func (uc *emailVerificationUseCase) Verify(token string) error {
+	userID, err := uc.repo.Consume(hashToken(token))
+	if err != nil {
+		return ErrInvalidVerificationToken
+	}
+
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return ErrInvalidVerificationToken
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	if _, err := uc.userRepo.Update(user.ID, user); err != nil {
+		return err
+	}
+
+	// Data /me di-cache sehingga perlu dibuang agar status verifikasi langsung terlihat
+	config.Rdb.Del(context.Background(), "user_info:"+user.ID)
+	return nil
+}
+
+func (uc *emailVerificationUseCase) SendToCohort(req dto.CohortVerificationRequestDTO, scope *dto.AccessScope) (int, error) {
+	// Pemanggil ber-scope wajib menyebut program studinya; kosong berarti semua
+	if !scope.AllowsStudyProgram(req.StudyProgramID) {
+		return 0, ErrOutsideAccessScope
+	}
+
+	users, err := uc.studentRepo.FindUnverifiedUsers(req.Generation, req.StudyProgramID)
+	if err != nil {
+		return 0, err
+	}
+
+	// Pengiriman berurutan sekaligus menjadi pembatas laju ke SMTP server
+	go func() {
+		sent, skipped := 0, 0
+		for i := range users {
+			if err := uc.Send(&users[i]); err != nil {
+				skipped++
+				log.Printf("Cohort verification email to %s skipped: %v", users[i].Email, err)
+				continue
+			}
+			sent++
+		}
+		log.Printf("Cohort %d verification emails finished: %d sent, %d skipped", req.Generation, sent, skipped)
+	}()
+
+	return len(users), nil
+}
+
+func humanizeDuration(d time.Duration) string {
+	if d >= time.Hour && d%time.Hour == 0 {
+		return fmt.Sprintf("%d jam", int(d.Hours()))
+	}
+	return fmt.Sprintf("%d menit", int(d.Minutes()))
+}
@@ -25,16 +25,18 @@ type EmployeeUseCase interface {
 }
 
 type employeeUseCase struct {
-	db       *gorm.DB
-	empRepo  domain.EmployeeRepository
-	userRepo domain.UserRepository
+	db         *gorm.DB
+	empRepo    domain.EmployeeRepository
+	userRepo   domain.UserRepository
+	passwordUC PasswordUseCase
 }
 
-func NewEmployeeUseCase(db *gorm.DB, empRepo domain.EmployeeRepository, userRepo domain.UserRepository) EmployeeUseCase {
+func NewEmployeeUseCase(db *gorm.DB, empRepo domain.EmployeeRepository, userRepo domain.UserRepository, passwordUC PasswordUseCase) EmployeeUseCase {
 	return &employeeUseCase{
-		db:       db,
-		empRepo:  empRepo,
-		userRepo: userRepo,
+		db:         db,
+		empRepo:    empRepo,
+		userRepo:   userRepo,
+		passwordUC: passwordUC,
 	}
 }
 
@@ -55,6 +57,16 @@ func (u *employeeUseCase) Create(payload *dto.StoreEmployeeDTO) (*domain.Employe
 	imgPath := constants.EMPLOYEE_PATH
 	imgName := constants.DEFAULT_AVATAR
 
+	// Password default (NIP) sengaja dikecualikan dari password policy karena
+	// wajib diganti saat login pertama
+	initialPassword := payload.NIP
+	if payload.Password != nil && *payload.Password != "" {
+		if err := u.passwordUC.Validate(*payload.Password, payload.NIP, payload.Name, payload.Email); err != nil {
+			return nil, err
+		}
+		initialPassword = *payload.Password
+	}
+
 	tx := u.db.Begin()
 	if tx.Error != nil {
 		return nil, tx.Error
@@ -78,7 +90,7 @@ func (u *employeeUseCase) Create(payload *dto.StoreEmployeeDTO) (*domain.Employe
 		}
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(payload.NIP), bcrypt.DefaultCost)
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(initialPassword), bcrypt.DefaultCost)
 	if err != nil {
 		tx.Rollback()
 		return nil, err
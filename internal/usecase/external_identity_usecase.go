@@ -0,0 +1,335 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"jti-super-app-go/internal/domain"
+	"jti-super-app-go/internal/dto"
+	"jti-super-app-go/internal/service"
+	"jti-super-app-go/pkg/constants"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+const (
+	// ExternalAuthStateTTL memberi waktu user untuk login di halaman IdP.
+	ExternalAuthStateTTL = 10 * time.Minute
+	// ExternalLinkTicketTTL cukup untuk frontend menukar ticket setelah redirect.
+	ExternalLinkTicketTTL = 5 * time.Minute
+)
+
+var (
+	ErrIdentityProviderNotFound    = errors.New("identity provider not found")
+	ErrInvalidExternalAuthState    = errors.New("login state is invalid or has expired")
+	ErrExternalAuthCancelled       = errors.New("sign in with the identity provider was cancelled")
+	ErrExternalIdentityNotLinked   = errors.New("this account is not linked to any user, sign in with your password and link it from your profile")
+	ErrExternalIdentityInUse       = errors.New("this account is already linked to another user")
+	ErrExternalProviderLinked      = errors.New("a different account from this provider is already linked")
+	ErrExternalIdentityNotFound    = errors.New("linked identity not found")
+	ErrInvalidExternalLinkTicket   = errors.New("link ticket is invalid or has expired")
+	ErrExternalIdentityLoginFailed = errors.New("external login failed")
+)
+
+// ExternalAuthResult adalah hasil callback IdP. Untuk login User terisi,
+// sedangkan untuk penautan LinkTicket yang terisi.
+type ExternalAuthResult struct {
+	Purpose     string
+	User        *domain.User
+	LinkTicket  string
+	ClientState string
+	ReturnHost  string
+}
+
+type ExternalIdentityUseCase interface {
+	Providers() []dto.IdentityProviderResource
+	// BeginLogin mengembalikan URL authorize IdP. browserID harus dikirim ulang
+	// saat callback agar state tidak bisa dipakai dari browser lain.
+	BeginLogin(provider, browserID, clientState, returnHost string) (string, error)
+	// BeginLink memverifikasi ulang password user lalu mengembalikan URL
+	// authorize IdP. Seperti login, state terikat ke browserID.
+	BeginLink(userID, provider, currentPassword, browserID string) (string, error)
+	// Callback menukar code dari IdP. Bila state valid, result tetap dikembalikan
+	// bersama error agar handler tahu ke mana error harus diarahkan.
+	Callback(ctx context.Context, provider, state, code, browserID string) (*ExternalAuthResult, error)
+	CompleteLink(userID, ticket, browserID string) (*dto.ExternalIdentityResource, error)
+	List(userID string) ([]dto.ExternalIdentityResource, error)
+	Unlink(userID, id string) error
+}
+
+type externalIdentityUseCase struct {
+	identityRepo domain.ExternalIdentityRepository
+	stateRepo    domain.ExternalAuthStateRepository
+	userRepo     domain.UserRepository
+	authRepo     domain.AuthRepository
+	providers    service.IdentityProviderRegistry
+}
+
+func NewExternalIdentityUseCase(identityRepo domain.ExternalIdentityRepository, stateRepo domain.ExternalAuthStateRepository, userRepo domain.UserRepository, authRepo domain.AuthRepository, providers service.IdentityProviderRegistry) ExternalIdentityUseCase {
+	return &externalIdentityUseCase{
+		identityRepo: identityRepo,
+		stateRepo:    stateRepo,
+		userRepo:     userRepo,
+		authRepo:     authRepo,
+		providers:    providers,
+	}
+}
+
+func (uc *externalIdentityUseCase) Providers() []dto.IdentityProviderResource {
+	providers := uc.providers.List()
+	resources := make([]dto.IdentityProviderResource, 0, len(providers))
+	for _, p := range providers {
+		resources = append(resources, dto.IdentityProviderResource{Name: p.Name(), DisplayName: p.DisplayName()})
+	}
+	return resources
+}
+
+func (uc *externalIdentityUseCase) BeginLogin(provider, browserID, clientState, returnHost string) (string, error) {
+	return uc.begin(&domain.ExternalAuthState{
+		Provider:    provider,
+		Purpose:     domain.ExternalAuthPurposeLogin,
+		BrowserID:   browserID,
+		ClientState: clientState,
+		ReturnHost:  returnHost,
+	})
+}
+
+func (uc *externalIdentityUseCase) BeginLink(userID, provider, currentPassword, browserID string) (string, error) {
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return "", err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(currentPassword)); err != nil {
+		return "", ErrInvalidCurrentPassword
+	}
+
+	return uc.begin(&domain.ExternalAuthState{
+		Provider:  provider,
+		Purpose:   domain.ExternalAuthPurposeLink,
+		UserID:    userID,
+		BrowserID: browserID,
+	})
+}
+
+func (uc *externalIdentityUseCase) begin(state *domain.ExternalAuthState) (string, error) {
+	provider, ok := uc.providers.Get(state.Provider)
+	if !ok {
+		return "", ErrIdentityProviderNotFound
+	}
+
+	state.State = uuid.NewString()
+	state.CodeVerifier = oauth2.GenerateVerifier()
+	state.CreatedAt = time.Now()
+	if err := uc.stateRepo.Create(state, ExternalAuthStateTTL); err != nil {
+		return "", err
+	}
+
+	return provider.AuthCodeURL(state.State, state.CodeVerifier), nil
+}
+
+func (uc *externalIdentityUseCase) Callback(ctx context.Context, providerName, state, code, browserID string) (*ExternalAuthResult, error) {
+	authState, err := uc.stateRepo.Consume(state)
+	if err != nil || authState.Provider != providerName {
+		return nil, ErrInvalidExternalAuthState
+	}
+	if authState.BrowserID == "" || subtle.ConstantTimeCompare([]byte(authState.BrowserID), []byte(browserID)) != 1 {
+		return nil, ErrInvalidExternalAuthState
+	}
+
+	// Mulai dari sini tujuan redirect sudah diketahui sehingga result tetap
+	// dikembalikan bersama error
+	result := &ExternalAuthResult{
+		Purpose:     authState.Purpose,
+		ClientState: authState.ClientState,
+		ReturnHost:  authState.ReturnHost,
+	}
+
+	provider, ok := uc.providers.Get(providerName)
+	if !ok {
+		return result, ErrIdentityProviderNotFound
+	}
+	if code == "" {
+		return result, ErrExternalAuthCancelled
+	}
+
+	upstream, err := provider.Exchange(ctx, code, authState.CodeVerifier)
+	if err != nil {
+		return result, err
+	}
+
+	if authState.Purpose == domain.ExternalAuthPurposeLink {
+		ticket := &domain.ExternalLinkTicket{
+			Ticket:    uuid.NewString(),
+			UserID:    authState.UserID,
+			BrowserID: authState.BrowserID,
+			Provider:  upstream.Provider,
+			Subject:   upstream.Subject,
+			Email:     upstream.Email,
+		}
+		if err := uc.stateRepo.CreateTicket(ticket, ExternalLinkTicketTTL); err != nil {
+			return result, err
+		}
+		result.LinkTicket = ticket.Ticket
+		return result, nil
+	}
+
+	user, err := uc.resolveLogin(provider, upstream)
+	if err != nil {
+		return result, err
+	}
+	result.User = user
+	return result, nil
+}
+
+// resolveLogin mencari user dari pasangan provider dan subject. Jika belum
+// tertaut, identitas hanya ditautkan otomatis bila provider dipercaya dan
+// email dari IdP sudah terverifikasi.
+func (uc *externalIdentityUseCase) resolveLogin(provider service.IdentityProvider, upstream *service.UpstreamIdentity) (*domain.User, error) {
+	now := time.Now()
+	email := optionalEmail(upstream.Email)
+
+	identity, err := uc.identityRepo.FindByProviderSubject(upstream.Provider, upstream.Subject)
+	switch {
+	case err == nil:
+		if err := uc.identityRepo.Touch(identity.ID, email, now); err != nil {
+			return nil, err
+		}
+		return uc.activeUser(identity.UserID)
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+
+	if !provider.TrustEmail() || !upstream.EmailVerified || upstream.Email == "" {
+		return nil, ErrExternalIdentityNotLinked
+	}
+
+	user, err := uc.authRepo.FindByEmail(upstream.Email)
+	if err != nil {
+		return nil, ErrExternalIdentityNotLinked
+	}
+	if user.Status != constants.StatusActive {
+		return nil, ErrExternalIdentityLoginFailed
+	}
+
+	err = uc.identityRepo.Create(&domain.ExternalIdentity{
+		ID:          uuid.NewString(),
+		UserID:      user.ID,
+		Provider:    upstream.Provider,
+		Subject:     upstream.Subject,
+		Email:       email,
+		LastLoginAt: &now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+	if err != nil {
+		// User sudah menautkan akun lain dari provider yang sama
+		return nil, ErrExternalProviderLinked
+	}
+	return user, nil
+}
+
+func (uc *externalIdentityUseCase) activeUser(userID string) (*domain.User, error) {
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil || user.Status != constants.StatusActive {
+		return nil, ErrExternalIdentityLoginFailed
+	}
+	return user, nil
+}
+
+func (uc *externalIdentityUseCase) CompleteLink(userID, ticket, browserID string) (*dto.ExternalIdentityResource, error) {
+	linkTicket, err := uc.stateRepo.ConsumeTicket(ticket)
+	if err != nil || subtle.ConstantTimeCompare([]byte(linkTicket.UserID), []byte(userID)) != 1 {
+		return nil, ErrInvalidExternalLinkTicket
+	}
+	if linkTicket.BrowserID == "" || subtle.ConstantTimeCompare([]byte(linkTicket.BrowserID), []byte(browserID)) != 1 {
+		return nil, ErrInvalidExternalLinkTicket
+	}
+
+	existing, err := uc.identityRepo.FindByProviderSubject(linkTicket.Provider, linkTicket.Subject)
+	switch {
+	case err == nil && existing.UserID == userID:
+		resource := uc.toResource(existing)
+		return &resource, nil
+	case err == nil:
+		return nil, ErrExternalIdentityInUse
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+
+	linked, err := uc.identityRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range linked {
+		if l.Provider == linkTicket.Provider {
+			return nil, ErrExternalProviderLinked
+		}
+	}
+
+	now := time.Now()
+	identity := &domain.ExternalIdentity{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Provider:  linkTicket.Provider,
+		Subject:   linkTicket.Subject,
+		Email:     optionalEmail(linkTicket.Email),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := uc.identityRepo.Create(identity); err != nil {
+		return nil, err
+	}
+
+	resource := uc.toResource(identity)
+	return &resource, nil
+}
+
+func (uc *externalIdentityUseCase) List(userID string) ([]dto.ExternalIdentityResource, error) {
+	identities, err := uc.identityRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]dto.ExternalIdentityResource, 0, len(identities))
+	for _, identity := range identities {
+		resources = append(resources, uc.toResource(&identity))
+	}
+	return resources, nil
+}
+
+func (uc *externalIdentityUseCase) Unlink(userID, id string) error {
+	err := uc.identityRepo.Delete(userID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrExternalIdentityNotFound
+	}
+	return err
+}
+
+func (uc *externalIdentityUseCase) toResource(identity *domain.ExternalIdentity) dto.ExternalIdentityResource {
+	displayName := identity.Provider
+	// Provider yang sudah dihapus dari config tetap ditampilkan agar bisa dilepas
+	if provider, ok := uc.providers.Get(identity.Provider); ok {
+		displayName = provider.DisplayName()
+	}
+	return dto.ExternalIdentityResource{
+		ID:          identity.ID,
+		Provider:    identity.Provider,
+		DisplayName: displayName,
+		Email:       identity.Email,
+		LastLoginAt: identity.LastLoginAt,
+		CreatedAt:   identity.CreatedAt,
+	}
+}
+
+func optionalEmail(email string) *string {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return nil
+	}
+	return &email
+}
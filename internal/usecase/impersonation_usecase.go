@@ -0,0 +1,207 @@
+package usecase
+
+import (
+	"errors"
+	"jti-super-app-go/config"
+	"jti-super-app-go/internal/domain"
+	"jti-super-app-go/internal/dto"
+	"jti-super-app-go/pkg/constants"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrImpersonateSelf           = errors.New("you cannot impersonate yourself")
+	ErrImpersonationNotAllowed   = errors.New("this user cannot be impersonated")
+	ErrImpersonationTargetAbsent = errors.New("user not found or inactive")
+	ErrImpersonationNotFound     = errors.New("impersonation session not found")
+)
+
+type ImpersonationUseCase interface {
+	// Start menerbitkan token atas nama userID dan mencatatnya di audit log.
+	Start(impersonatorID, userID string, req dto.StartImpersonationRequestDTO, meta dto.SessionMetaDTO) (*dto.ImpersonationResponseDTO, error)
+	// Stop mencabut token impersonation dan mencatat akhir sesinya.
+	Stop(impersonationID, impersonatorID, reason string) error
+	// Banner mengembalikan info impersonation untuk ditampilkan di respons Me.
+	Banner(impersonationID string) (*dto.ImpersonationBannerDTO, error)
+	FindAll(params dto.QueryParams, userID string) ([]dto.ImpersonationLogResource, int64, error)
+}
+
+type impersonationUseCase struct {
+	logRepo         domain.ImpersonationLogRepository
+	userRepo        domain.UserRepository
+	tokenUC         TokenUseCase
+	authorizationUC AuthorizationUseCase
+}
+
+func NewImpersonationUseCase(logRepo domain.ImpersonationLogRepository, userRepo domain.UserRepository, tokenUC TokenUseCase, authorizationUC AuthorizationUseCase) ImpersonationUseCase {
+	return &impersonationUseCase{logRepo: logRepo, userRepo: userRepo, tokenUC: tokenUC, authorizationUC: authorizationUC}
+}
+
+func (uc *impersonationUseCase) Start(impersonatorID, userID string, req dto.StartImpersonationRequestDTO, meta dto.SessionMetaDTO) (*dto.ImpersonationResponseDTO, error) {
+	if impersonatorID == userID {
+		return nil, ErrImpersonateSelf
+	}
+
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil || user.Status != constants.StatusActive {
+		return nil, ErrImpersonationTargetAbsent
+	}
+
+	// Admin lain tidak bisa di-impersonate agar permission tidak bisa dieskalasi
+	roles, permissions := collectRolesAndPermissions(user)
+	if slices.Contains(permissions, constants.PermissionImpersonateUsers) {
+		return nil, ErrImpersonationNotAllowed
+	}
+
+	// Token impersonation memakai permission target secara live, sehingga
+	// target tidak boleh punya akses yang tidak dimiliki impersonator
+	impersonatorSet, err := uc.authorizationUC.Resolve(impersonatorID)
+	if err != nil {
+		return nil, err
+	}
+	userSet, err := uc.authorizationUC.Resolve(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !permissionSetCovers(impersonatorSet, userSet) {
+		return nil, ErrImpersonationNotAllowed
+	}
+
+	ttl := time.Duration(config.AppConfig.ImpersonationMinutes) * time.Minute
+	now := time.Now()
+	log := &domain.ImpersonationLog{
+		ID:             uuid.NewString(),
+		ImpersonatorID: impersonatorID,
+		UserID:         user.ID,
+		Reason:         req.Reason,
+		IPAddress:      optionalString(meta.IPAddress),
+		UserAgent:      optionalString(truncate(meta.UserAgent, 500)),
+		StartedAt:      now,
+		ExpiresAt:      now.Add(ttl),
+	}
+	// Audit log ditulis lebih dulu sehingga tidak ada token tanpa jejak
+	if err := uc.logRepo.Create(log); err != nil {
+		return nil, err
+	}
+
+	tokens, err := uc.tokenUC.IssueImpersonation(user, impersonatorID, log.ID, ttl)
+	if err != nil {
+		_ = uc.logRepo.End(log.ID, domain.ImpersonationEndStopped, time.Now())
+		return nil, err
+	}
+
+	return &dto.ImpersonationResponseDTO{
+		ImpersonationID: log.ID,
+		Token:           tokens.AccessToken,
+		ExpiresIn:       tokens.ExpiresIn,
+		ExpiresAt:       log.ExpiresAt,
+		User: dto.UserLoginInfo{
+			ID:               user.ID,
+			Name:             user.Name,
+			Email:            user.Email,
+			IsChangePassword: user.IsChangePassword,
+			Roles:            roles,
+			Permissions:      permissions,
+		},
+	}, nil
+}
+
+func (uc *impersonationUseCase) Stop(impersonationID, impersonatorID, reason string) error {
+	log, err := uc.logRepo.FindByID(impersonationID)
+	if err != nil || log.ImpersonatorID != impersonatorID {
+		return ErrImpersonationNotFound
+	}
+
+	if err := uc.tokenUC.RevokeFamily(log.ID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if now.After(log.ExpiresAt) {
+		reason, now = domain.ImpersonationEndExpired, log.ExpiresAt
+	}
+	return uc.logRepo.End(log.ID, reason, now)
+}
+
+func (uc *impersonationUseCase) Banner(impersonationID string) (*dto.ImpersonationBannerDTO, error) {
+	log, err := uc.logRepo.FindByID(impersonationID)
+	if err != nil {
+		return nil, ErrImpersonationNotFound
+	}
+
+	return &dto.ImpersonationBannerDTO{
+		Active:           true,
+		ImpersonationID:  log.ID,
+		ImpersonatorID:   log.ImpersonatorID,
+		ImpersonatorName: log.Impersonator.Name,
+		ExpiresAt:        log.ExpiresAt,
+	}, nil
+}
+
+func (uc *impersonationUseCase) FindAll(params dto.QueryParams, userID string) ([]dto.ImpersonationLogResource, int64, error) {
+	logs, total, err := uc.logRepo.FindAll(params, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	now := time.Now()
+	resources := make([]dto.ImpersonationLogResource, 0, len(*logs))
+	for _, log := range *logs {
+		resource := dto.ImpersonationLogResource{
+			ID:           log.ID,
+			Impersonator: dto.ImpersonationUserResource{ID: log.Impersonator.ID, Name: log.Impersonator.Name, Email: log.Impersonator.Email},
+			User:         dto.ImpersonationUserResource{ID: log.User.ID, Name: log.User.Name, Email: log.User.Email},
+			Reason:       log.Reason,
+			IPAddress:    log.IPAddress,
+			UserAgent:    log.UserAgent,
+			StartedAt:    log.StartedAt,
+			ExpiresAt:    log.ExpiresAt,
+			EndedAt:      log.EndedAt,
+			EndReason:    log.EndReason,
+		}
+		// Sesi yang tidak pernah dihentikan berakhir saat tokennya kedaluwarsa
+		if log.EndedAt == nil && now.After(log.ExpiresAt) {
+			expired := domain.ImpersonationEndExpired
+			resource.EndedAt, resource.EndReason = &log.ExpiresAt, &expired
+		}
+		resources = append(resources, resource)
+	}
+	return resources, total, nil
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
+
+// permissionSetCovers bernilai true bila setiap permission di other, termasuk
+// permission ber-scope, juga dimiliki set dengan cakupan yang sama atau lebih luas.
+func permissionSetCovers(set, other *domain.PermissionSet) bool {
+	for _, name := range other.Permissions {
+		if !slices.Contains(set.Permissions, name) {
+			return false
+		}
+	}
+	for name, scope := range other.Scopes {
+		if slices.Contains(set.Permissions, name) {
+			continue
+		}
+		held, ok := set.Scopes[name]
+		if !ok || !held.Covers(scope) {
+			return false
+		}
+	}
+	return true
+}
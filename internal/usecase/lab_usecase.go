@@ -9,33 +9,41 @@ import (
 
 type LabUseCase interface {
 	FindAll(params dto.QueryParams, majorId string) (*[]domain.Lab, int64, error)
-	FindByID(id string) (*domain.Lab, error)
+	FindByID(id string, scope *dto.AccessScope) (*domain.Lab, error)
 	FindAllAsOptions(majorId string) (*[]domain.Lab, error)
-	Create(dto *dto.StoreLabDTO) (*domain.Lab, error)
-	Update(id string, dto *dto.UpdateLabDTO) (*domain.Lab, error)
-	Delete(id string) error
+	Create(dto *dto.StoreLabDTO, scope *dto.AccessScope) (*domain.Lab, error)
+	Update(id string, dto *dto.UpdateLabDTO, scope *dto.AccessScope) (*domain.Lab, error)
+	Delete(id string, scope *dto.AccessScope) error
 }
 
 type labUseCase struct {
-	repo domain.LabRepository
+	repo            domain.LabRepository
+	authorizationUC AuthorizationUseCase
 }
 
-func NewLabUseCase(repo domain.LabRepository) LabUseCase {
-	return &labUseCase{repo: repo}
+func NewLabUseCase(repo domain.LabRepository, authorizationUC AuthorizationUseCase) LabUseCase {
+	return &labUseCase{repo: repo, authorizationUC: authorizationUC}
 }
 
 func (u *labUseCase) FindAll(params dto.QueryParams, majorId string) (*[]domain.Lab, int64, error) {
 	return u.repo.FindAll(params, majorId)
 }
 
-func (u *labUseCase) FindByID(id string) (*domain.Lab, error) {
+func (u *labUseCase) FindByID(id string, scope *dto.AccessScope) (*domain.Lab, error) {
+	if !scope.AllowsLab(id) {
+		return nil, ErrOutsideAccessScope
+	}
 	return u.repo.FindByID(id)
 }
 func (u *labUseCase) FindAllAsOptions(majorId string) (*[]domain.Lab, error) {
 	return u.repo.FindAllAsOptions(majorId)
 }
 
-func (u *labUseCase) Create(dto *dto.StoreLabDTO) (*domain.Lab, error) {
+func (u *labUseCase) Create(dto *dto.StoreLabDTO, scope *dto.AccessScope) (*domain.Lab, error) {
+	if !scope.AllowsMajor(dto.MajorID) {
+		return nil, ErrOutsideAccessScope
+	}
+
 	lab := &domain.Lab{
 		ID:          uuid.NewString(),
 		Code:        dto.Code,
@@ -52,18 +60,50 @@ func (u *labUseCase) Create(dto *dto.StoreLabDTO) (*domain.Lab, error) {
 			Period:     emp.Period,
 		}
 	}
-	return u.repo.Create(lab)
+	created, err := u.repo.Create(lab)
+	if err != nil {
+		return nil, err
+	}
+	// Scope jurusan dijabarkan ke daftar lab saat permission dimuat
+	u.authorizationUC.InvalidateAll()
+	return created, nil
 }
 
-func (u *labUseCase) Update(id string, dto *dto.UpdateLabDTO) (*domain.Lab, error) {
+func (u *labUseCase) Update(id string, dto *dto.UpdateLabDTO, scope *dto.AccessScope) (*domain.Lab, error) {
+	if !scope.AllowsLab(id) {
+		return nil, ErrOutsideAccessScope
+	}
+	// Kepala lab tidak boleh memindahkan lab ke jurusan di luar scope-nya
+	if scope != nil {
+		current, err := u.repo.FindByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if current.MajorID != dto.MajorID && !scope.AllowsMajor(dto.MajorID) {
+			return nil, ErrOutsideAccessScope
+		}
+	}
+
 	lab := &domain.Lab{
 		Code:    dto.Code,
 		Name:    dto.Name,
 		MajorID: dto.MajorID,
 	}
-	return u.repo.Update(id, lab)
+	updated, err := u.repo.Update(id, lab)
+	if err != nil {
+		return nil, err
+	}
+	u.authorizationUC.InvalidateAll()
+	return updated, nil
 }
 
-func (u *labUseCase) Delete(id string) error {
-	return u.repo.Delete(id)
+func (u *labUseCase) Delete(id string, scope *dto.AccessScope) error {
+	if !scope.AllowsLab(id) {
+		return ErrOutsideAccessScope
+	}
+	if err := u.repo.Delete(id); err != nil {
+		return err
+	}
+	u.authorizationUC.InvalidateAll()
+	return nil
 }
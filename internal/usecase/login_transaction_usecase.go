@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"crypto/subtle"
+	"errors"
+	"jti-super-app-go/internal/domain"
+	"jti-super-app-go/internal/dto"
+	"jti-super-app-go/pkg/helper"
+	"time"
+)
+
+// LoginTransactionTTL adalah batas waktu user menyelesaikan halaman login.
+const LoginTransactionTTL = 10 * time.Minute
+
+// LoginResultTTL adalah batas waktu browser menukar code hasil login eksternal.
+const LoginResultTTL = time.Minute
+
+var ErrInvalidLoginTransaction = errors.New("login session is invalid or has expired")
+
+type LoginTransactionUseCase interface {
+	Begin(browserID, returnTo string, req *dto.OauthAuthorizeRequestDTO) (string, error)
+	Complete(state, browserID string) (*domain.LoginTransaction, error)
+	// StoreResult menyimpan hasil login untuk browser ini dan mengembalikan
+	// code sekali pakai yang ditukar lewat ConsumeResult.
+	StoreResult(browserID string, login *dto.LoginResponseDTO) (string, error)
+	ConsumeResult(code, browserID string) (*dto.LoginResponseDTO, error)
+}
+
+type loginTransactionUseCase struct {
+	repo domain.LoginTransactionRepository
+}
+
+func NewLoginTransactionUseCase(repo domain.LoginTransactionRepository) LoginTransactionUseCase {
+	return &loginTransactionUseCase{repo: repo}
+}
+
+// Begin membuat transaksi login baru dan mengembalikan state-nya. req boleh nil
+// jika login tidak dimulai dari endpoint authorize.
+func (uc *loginTransactionUseCase) Begin(browserID, returnTo string, req *dto.OauthAuthorizeRequestDTO) (string, error) {
+	tx := &domain.LoginTransaction{
+		State:     helper.GenCode(),
+		BrowserID: browserID,
+		ReturnTo:  returnTo,
+		CreatedAt: time.Now(),
+	}
+
+	if req != nil {
+		tx.ClientID = req.ClientID
+		tx.RedirectURI = req.RedirectURI
+		tx.Scope = req.Scope
+		tx.Nonce = req.Nonce
+		tx.CodeChallenge = req.CodeChallenge
+		tx.CodeChallengeMethod = req.CodeChallengeMethod
+	}
+
+	if err := uc.repo.Create(tx, LoginTransactionTTL); err != nil {
+		return "", err
+	}
+	return tx.State, nil
+}
+
+// Complete memakai transaksi sekali saja dan memastikan browser yang
+// menyelesaikan login sama dengan yang memulainya.
+func (uc *loginTransactionUseCase) Complete(state, browserID string) (*domain.LoginTransaction, error) {
+	if state == "" || browserID == "" {
+		return nil, ErrInvalidLoginTransaction
+	}
+
+	tx, err := uc.repo.Consume(state)
+	if err != nil {
+		return nil, ErrInvalidLoginTransaction
+	}
+
+	if subtle.ConstantTimeCompare([]byte(tx.BrowserID), []byte(browserID)) != 1 {
+		return nil, ErrInvalidLoginTransaction
+	}
+
+	return tx, nil
+}
+
+func (uc *loginTransactionUseCase) StoreResult(browserID string, login *dto.LoginResponseDTO) (string, error) {
+	if browserID == "" {
+		return "", ErrInvalidLoginTransaction
+	}
+
+	result := &domain.LoginResult{
+		Code:      helper.GenCode(),
+		BrowserID: browserID,
+		SessionID: login.SessionID,
+		Login:     *login,
+	}
+	if err := uc.repo.CreateResult(result, LoginResultTTL); err != nil {
+		return "", err
+	}
+	return result.Code, nil
+}
+
+func (uc *loginTransactionUseCase) ConsumeResult(code, browserID string) (*dto.LoginResponseDTO, error) {
+	if code == "" || browserID == "" {
+		return nil, ErrInvalidLoginTransaction
+	}
+
+	result, err := uc.repo.ConsumeResult(code)
+	if err != nil {
+		return nil, ErrInvalidLoginTransaction
+	}
+
+	if subtle.ConstantTimeCompare([]byte(result.BrowserID), []byte(browserID)) != 1 {
+		return nil, ErrInvalidLoginTransaction
+	}
+
+	login := result.Login
+	login.SessionID = result.SessionID
+	return &login, nil
+}
@@ -1,8 +1,11 @@
 package usecase
 
 import (
+	"errors"
+	"jti-super-app-go/config"
 	"jti-super-app-go/internal/domain"
 	"jti-super-app-go/internal/dto"
+	"jti-super-app-go/pkg/helper"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
@@ -14,6 +17,8 @@ type OauthClientUseCase interface {
 	Create(dto *dto.StoreOauthClientDTO) (*domain.OauthClient, error)
 	Update(id string, dto *dto.UpdateOauthClientDTO) (*domain.OauthClient, error)
 	Delete(id string) error
+	Authenticate(clientID, clientSecret string) (*domain.OauthClient, error)
+	IsAllowedReturnTo(returnTo string) bool
 }
 
 type oauthClientUseCase struct {
@@ -33,43 +38,79 @@ func (u *oauthClientUseCase) FindByID(id string) (*domain.OauthClient, error) {
 }
 
 func (u *oauthClientUseCase) Create(dto *dto.StoreOauthClientDTO) (*domain.OauthClient, error) {
-	hashedSecret, err := bcrypt.GenerateFromPassword([]byte(dto.Secret), bcrypt.DefaultCost)
-	if err != nil {
-		return nil, err
+	client := &domain.OauthClient{
+		ID:                    uuid.NewString(),
+		Name:                  dto.Name,
+		RedirectURIs:          dto.RedirectURIs,
+		IsPublic:              dto.IsPublic,
+		Scopes:                dto.Scopes,
+		FrontchannelLogoutURI: dto.FrontchannelLogoutURI,
+		BackchannelLogoutURI:  dto.BackchannelLogoutURI,
 	}
 
-	client := &domain.OauthClient{
-		ID:       uuid.NewString(),
-		Name:     dto.Name,
-		Secret:   string(hashedSecret),
-		Redirect: dto.Redirect,
+	// Public client (SPA/mobile) tidak bisa menyimpan secret, autentikasinya lewat PKCE
+	if !dto.IsPublic {
+		hashedSecret, err := bcrypt.GenerateFromPassword([]byte(dto.Secret), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+		client.Secret = string(hashedSecret)
 	}
 
-	err = u.repo.Create(client)
+	err := u.repo.Create(client)
 	if err != nil {
 		return nil, err
 	}
 
-	return client, nil
+	if len(dto.PermissionIDs) > 0 {
+		if err := u.repo.UpdatePermissions(client.ID, dto.PermissionIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	return u.repo.FindByID(client.ID)
 }
 
 func (u *oauthClientUseCase) Update(id string, dto *dto.UpdateOauthClientDTO) (*domain.OauthClient, error) {
-	hashedSecret, err := bcrypt.GenerateFromPassword([]byte(dto.Secret), bcrypt.DefaultCost)
+	existing, err := u.repo.FindByID(id)
 	if err != nil {
 		return nil, err
 	}
 
-	client := &domain.OauthClient{
-		Name:     dto.Name,
-		Secret:   string(hashedSecret),
-		Redirect: dto.Redirect,
+	if !dto.IsPublic && dto.Secret == "" && existing.Secret == "" {
+		return nil, errors.New("secret is required for confidential clients")
 	}
 
-	err = u.repo.Update(id, client)
+	err = u.repo.Save(id, &domain.OauthClient{
+		Name:                  dto.Name,
+		RedirectURIs:          dto.RedirectURIs,
+		IsPublic:              dto.IsPublic,
+		Scopes:                dto.Scopes,
+		FrontchannelLogoutURI: dto.FrontchannelLogoutURI,
+		BackchannelLogoutURI:  dto.BackchannelLogoutURI,
+	}, "name", "redirect_uris", "is_public", "scopes", "frontchannel_logout_uri", "backchannel_logout_uri")
 	if err != nil {
 		return nil, err
 	}
 
+	switch {
+	case dto.IsPublic:
+		err = u.repo.Save(id, &domain.OauthClient{Secret: ""}, "secret")
+	case dto.Secret != "":
+		hashedSecret, hashErr := bcrypt.GenerateFromPassword([]byte(dto.Secret), bcrypt.DefaultCost)
+		if hashErr != nil {
+			return nil, hashErr
+		}
+		err = u.repo.Save(id, &domain.OauthClient{Secret: string(hashedSecret)}, "secret")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.repo.UpdatePermissions(id, dto.PermissionIDs); err != nil {
+		return nil, err
+	}
+
 	updatedClient, err := u.repo.FindByID(id)
 	if err != nil {
 		return nil, err
@@ -81,3 +122,45 @@ func (u *oauthClientUseCase) Update(id string, dto *dto.UpdateOauthClientDTO) (*
 func (u *oauthClientUseCase) Delete(id string) error {
 	return u.repo.Delete(id)
 }
+
+func (u *oauthClientUseCase) Authenticate(clientID, clientSecret string) (*domain.OauthClient, error) {
+	client, err := u.repo.FindByID(clientID)
+	if err != nil {
+		return nil, errors.New("invalid client")
+	}
+
+	// Public client dibuktikan lewat code_verifier PKCE saat penukaran code
+	if client.IsPublic {
+		return client, nil
+	}
+
+	if clientSecret == "" {
+		return nil, errors.New("invalid client")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(client.Secret), []byte(clientSecret)); err != nil {
+		return nil, errors.New("invalid client")
+	}
+
+	return client, nil
+}
+
+// IsAllowedReturnTo memvalidasi tujuan redirect pada alur login web: hanya path
+// lokal, origin aplikasi ini, atau origin dari redirect URI client terdaftar.
+func (u *oauthClientUseCase) IsAllowedReturnTo(returnTo string) bool {
+	if helper.IsSafeReturnTo(returnTo, []string{helper.Origin(config.AppConfig.AppUrl)}) {
+		return true
+	}
+
+	uris, err := u.repo.FindAllRedirectURIs()
+	if err != nil {
+		return false
+	}
+
+	origins := make([]string, 0, len(uris))
+	for _, uri := range uris {
+		origins = append(origins, helper.Origin(uri))
+	}
+
+	return helper.IsSafeReturnTo(returnTo, origins)
+}
@@ -2,34 +2,180 @@ package usecase
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"jti-super-app-go/config"
+	"jti-super-app-go/internal/domain"
 	"jti-super-app-go/internal/dto"
+	"jti-super-app-go/internal/service"
+	"jti-super-app-go/pkg/constants"
 	"jti-super-app-go/pkg/helper"
+	"log"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// OauthError membawa kode error OAuth 2.0 (RFC 6749 section 5.2) agar handler
+// bisa meneruskannya apa adanya ke client.
+type OauthError struct {
+	Code        string
+	Description string
+}
+
+func (e *OauthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+const (
+	oauthCodePrefix     = "oauth:code:"
+	oauthCodeUsedPrefix = "oauth:code_used:"
+	oauthCodeTTL        = 10 * time.Minute
+	// Penanda code terpakai disimpan lebih lama dari umur code agar replay
+	// yang datang belakangan tetap bisa mencabut token hasil penukaran pertama.
+	oauthCodeUsedTTL = 24 * time.Hour
+	// Nilai penanda selama token belum selesai diterbitkan dan setelah replay
+	// terdeteksi; selain itu penanda berisi family ID token hasil penukaran.
+	oauthCodePending  = "pending"
+	oauthCodeReplayed = "replayed"
 )
 
+// claimCodeScript mengambil code sekaligus memasang penanda pending dalam satu
+// operasi atomik sehingga replay tidak pernah melihat code dan penanda sama-sama
+// kosong. Penanda hanya dibuat untuk code yang benar-benar ada.
+var claimCodeScript = redis.NewScript(`
+local code = redis.call('GETDEL', KEYS[1])
+if code then
+	redis.call('SET', KEYS[2], ARGV[1], 'EX', ARGV[2])
+end
+return code
+`)
+
+func newOauthError(code, description string) *OauthError {
+	return &OauthError{Code: code, Description: description}
+}
+
 type OauthUsecase interface {
-	Authorize(clientID, redirectURI, responseType string, user *dto.LoginResponseDTO) (dto.StoreOauthCodeDTO, error)
+	ValidateAuthorizeRequest(req *dto.OauthAuthorizeRequestDTO, client *domain.OauthClient) error
+	HasConsent(userID, clientID, scope string) (bool, error)
+	GrantConsent(userID, clientID, scope string) error
+	Authorize(req dto.OauthAuthorizeRequestDTO, client *domain.OauthClient, user *dto.LoginResponseDTO, sessionID string) (dto.StoreOauthCodeDTO, error)
+	Exchange(req dto.OauthTokenRequestDTO, client *domain.OauthClient) (*dto.OauthTokenResponseDTO, error)
+	UserInfo(userID, sessionID, scope string) (*dto.OidcUserInfoDTO, error)
+	Logout(sessionID string) ([]string, error)
 }
 
 type oauthUsecase struct {
-	// dependencies can be added here
+	userRepo     domain.UserRepository
+	clientRepo   domain.OauthClientRepository
+	ssoRepo      domain.SSOSessionRepository
+	consentRepo  domain.OauthConsentRepository
+	studentRepo  domain.StudentRepository
+	employeeRepo domain.EmployeeRepository
+	tokenUC      TokenUseCase
+	jwtService   service.JWTService
+	oidcService  service.OIDCService
+}
+
+func NewOauthUsecase(userRepo domain.UserRepository, clientRepo domain.OauthClientRepository, ssoRepo domain.SSOSessionRepository, consentRepo domain.OauthConsentRepository, studentRepo domain.StudentRepository, employeeRepo domain.EmployeeRepository, tokenUC TokenUseCase, jwtService service.JWTService, oidcService service.OIDCService) OauthUsecase {
+	return &oauthUsecase{
+		userRepo:     userRepo,
+		clientRepo:   clientRepo,
+		ssoRepo:      ssoRepo,
+		consentRepo:  consentRepo,
+		studentRepo:  studentRepo,
+		employeeRepo: employeeRepo,
+		tokenUC:      tokenUC,
+		jwtService:   jwtService,
+		oidcService:  oidcService,
+	}
+}
+
+// ValidateAuthorizeRequest memeriksa PKCE dan scope yang diminta. Jika scope
+// kosong, seluruh scope milik client dipakai sebagai default.
+func (uc *oauthUsecase) ValidateAuthorizeRequest(req *dto.OauthAuthorizeRequestDTO, client *domain.OauthClient) error {
+	if err := validateCodeChallenge(*req, client); err != nil {
+		return err
+	}
+
+	requested := strings.Fields(req.Scope)
+	if len(requested) == 0 {
+		requested = client.Scopes
+	}
+
+	for _, scope := range requested {
+		if !slices.Contains(client.Scopes, scope) {
+			return newOauthError("invalid_scope", "scope "+scope+" is not allowed for this client")
+		}
+	}
+
+	req.Scope = strings.Join(requested, " ")
+	return nil
+}
+
+func (uc *oauthUsecase) HasConsent(userID, clientID, scope string) (bool, error) {
+	consent, err := uc.consentRepo.FindByUserAndClient(userID, clientID)
+	if err != nil {
+		return false, err
+	}
+	if consent == nil {
+		return false, nil
+	}
+
+	for _, s := range strings.Fields(scope) {
+		if !slices.Contains(consent.Scopes, s) {
+			return false, nil
+		}
+	}
+	return true, nil
 }
 
-func NewOauthUsecase() OauthUsecase {
-	return &oauthUsecase{}
+// GrantConsent menggabungkan scope baru dengan consent yang sudah pernah diberikan.
+func (uc *oauthUsecase) GrantConsent(userID, clientID, scope string) error {
+	consent, err := uc.consentRepo.FindByUserAndClient(userID, clientID)
+	if err != nil {
+		return err
+	}
+	if consent == nil {
+		consent = &domain.OauthConsent{ID: uuid.NewString(), UserID: userID, ClientID: clientID}
+	}
+
+	for _, s := range strings.Fields(scope) {
+		if !slices.Contains(consent.Scopes, s) {
+			consent.Scopes = append(consent.Scopes, s)
+		}
+	}
+
+	return uc.consentRepo.Save(consent)
 }
 
-func (uc *oauthUsecase) Authorize(clientID, redirectURI, responseType string, user *dto.LoginResponseDTO) (dto.StoreOauthCodeDTO, error) {
+func (uc *oauthUsecase) Authorize(req dto.OauthAuthorizeRequestDTO, client *domain.OauthClient, user *dto.LoginResponseDTO, sessionID string) (dto.StoreOauthCodeDTO, error) {
+	if err := uc.ValidateAuthorizeRequest(&req, client); err != nil {
+		return dto.StoreOauthCodeDTO{}, err
+	}
+
 	code := helper.GenCode()
 
 	data := dto.StoreOauthCodeDTO{
-		Code:        code,
-		ClientID:    clientID,
-		UserSub:     *user,
-		RedirectURI: redirectURI,
-		ExpiresAt:   time.Now().Add(10 * time.Minute),
+		Code:                code,
+		ClientID:            req.ClientID,
+		UserID:              user.User.ID,
+		SessionID:           sessionID,
+		RedirectURI:         req.RedirectURI,
+		Scope:               req.Scope,
+		Nonce:               req.Nonce,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		AuthTime:            time.Now(),
+		ExpiresAt:           time.Now().Add(oauthCodeTTL),
 	}
 
 	jsonData, err := json.Marshal(data)
@@ -37,10 +183,387 @@ func (uc *oauthUsecase) Authorize(clientID, redirectURI, responseType string, us
 		return dto.StoreOauthCodeDTO{}, err
 	}
 
-	storeToRedis := config.Rdb.Set(context.Background(), code, jsonData, 10*time.Minute)
+	storeToRedis := config.Rdb.Set(context.Background(), oauthCodePrefix+code, jsonData, oauthCodeTTL)
 	if storeToRedis.Err() != nil {
 		return dto.StoreOauthCodeDTO{}, storeToRedis.Err()
 	}
 
 	return data, nil
 }
+
+func (uc *oauthUsecase) Exchange(req dto.OauthTokenRequestDTO, client *domain.OauthClient) (*dto.OauthTokenResponseDTO, error) {
+	switch req.GrantType {
+	case "", "authorization_code":
+		return uc.exchangeAuthorizationCode(req, client)
+	case "refresh_token":
+		return uc.exchangeRefreshToken(req, client)
+	case "client_credentials":
+		return uc.exchangeClientCredentials(client)
+	default:
+		return nil, newOauthError("unsupported_grant_type", "grant type is not supported")
+	}
+}
+
+func (uc *oauthUsecase) exchangeAuthorizationCode(req dto.OauthTokenRequestDTO, client *domain.OauthClient) (*dto.OauthTokenResponseDTO, error) {
+	if req.Code == "" || req.RedirectURI == "" {
+		return nil, newOauthError("invalid_request", "code and redirect_uri are required")
+	}
+
+	if !client.HasRedirectURI(req.RedirectURI) {
+		return nil, newOauthError("invalid_grant", "redirect_uri does not match the registered client")
+	}
+
+	ctx := context.Background()
+
+	// Hanya penukaran pertama yang mendapatkan data code. Penanda dipasang dalam
+	// operasi yang sama, sebelum token diterbitkan, agar replay yang datang
+	// bersamaan tetap terdeteksi
+	marker := oauthCodeUsedPrefix + req.Code
+	val, err := claimCodeScript.Run(ctx, config.Rdb, []string{oauthCodePrefix + req.Code, marker}, oauthCodePending, int(oauthCodeUsedTTL.Seconds())).Text()
+	if errors.Is(err, redis.Nil) {
+		if err := uc.revokeReplayedCode(req.Code); err != nil {
+			return nil, err
+		}
+		return nil, newOauthError("invalid_grant", "authorization code is invalid or expired")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var ac dto.StoreOauthCodeDTO
+	if err := json.Unmarshal([]byte(val), &ac); err != nil {
+		return nil, err
+	}
+
+	if ac.Code != req.Code || time.Now().After(ac.ExpiresAt) || ac.ClientID != client.ID || ac.RedirectURI != req.RedirectURI {
+		return nil, newOauthError("invalid_grant", "authorization code is invalid or expired")
+	}
+
+	if err := verifyCodeVerifier(ac, req.CodeVerifier, client); err != nil {
+		return nil, err
+	}
+
+	user, err := uc.userRepo.FindByID(ac.UserID)
+	if err != nil || user.Status != constants.StatusActive {
+		return nil, newOauthError("invalid_grant", "user is no longer active")
+	}
+
+	tokens, err := uc.tokenUC.IssueForUser(user, client.ID, ac.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := config.Rdb.SetArgs(ctx, marker, tokens.SessionID, redis.SetArgs{Mode: "XX", Get: true, KeepTTL: true}).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	if previous == oauthCodeReplayed {
+		// Code dipakai ulang selagi token ini diterbitkan
+		if err := uc.tokenUC.RevokeFamily(tokens.SessionID); err != nil {
+			return nil, err
+		}
+		return nil, newOauthError("invalid_grant", "authorization code is invalid or expired")
+	}
+
+	// Dicatat agar client ikut di-logout saat sesi SSO user berakhir
+	ssoTTL := time.Duration(config.AppConfig.JWTExpirationHours) * time.Hour
+	if err := uc.ssoRepo.AddClient(ac.SessionID, domain.SSOSessionClient{ClientID: client.ID, UserID: user.ID, FamilyID: tokens.SessionID}, ssoTTL); err != nil {
+		log.Println("Failed to track SSO client session:", err)
+	}
+
+	res := &dto.OauthTokenResponseDTO{
+		AccessToken:  tokens.AccessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    tokens.ExpiresIn,
+		RefreshToken: tokens.RefreshToken,
+		Scope:        ac.Scope,
+	}
+
+	if hasScope(ac.Scope, constants.ScopeOpenID) {
+		claims := service.IDTokenClaims{
+			Nonce:    ac.Nonce,
+			AuthTime: ac.AuthTime.Unix(),
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:  user.ID,
+				Audience: jwt.ClaimStrings{client.ID},
+			},
+		}
+		if hasScope(ac.Scope, constants.ScopeProfile) {
+			claims.Name = user.Name
+		}
+		if hasScope(ac.Scope, constants.ScopeEmail) {
+			verified := user.EmailVerifiedAt != nil
+			claims.Email = user.Email
+			claims.EmailVerified = &verified
+		}
+		if hasScope(ac.Scope, constants.ScopeRoles) {
+			claims.Roles, _ = collectRolesAndPermissions(user)
+		}
+
+		idToken, err := uc.oidcService.GenerateIDToken(claims, time.Duration(tokens.ExpiresIn)*time.Second)
+		if err != nil {
+			return nil, err
+		}
+		res.IDToken = idToken
+	}
+
+	return res, nil
+}
+
+// revokeReplayedCode mencabut semua token yang pernah diterbitkan dari code yang
+// dipakai ulang (RFC 6749 section 4.1.2).
+// Code yang belum selesai ditukar ditandai replayed sehingga penukaran pertama
+// mencabut token-nya sendiri.
+func (uc *oauthUsecase) revokeReplayedCode(code string) error {
+	familyID, err := config.Rdb.SetArgs(context.Background(), oauthCodeUsedPrefix+code, oauthCodeReplayed, redis.SetArgs{Mode: "XX", Get: true, KeepTTL: true}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if familyID == oauthCodePending || familyID == oauthCodeReplayed {
+		return nil
+	}
+
+	log.Printf("Authorization code replay detected, revoking token family %s", familyID)
+	return uc.tokenUC.RevokeFamily(familyID)
+}
+
+func (uc *oauthUsecase) exchangeRefreshToken(req dto.OauthTokenRequestDTO, client *domain.OauthClient) (*dto.OauthTokenResponseDTO, error) {
+	if req.RefreshToken == "" {
+		return nil, newOauthError("invalid_request", "refresh_token is required")
+	}
+
+	tokens, err := uc.tokenUC.Refresh(req.RefreshToken, client.ID)
+	if err != nil {
+		return nil, newOauthError("invalid_grant", err.Error())
+	}
+
+	return &dto.OauthTokenResponseDTO{
+		AccessToken:  tokens.AccessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    tokens.ExpiresIn,
+		RefreshToken: tokens.RefreshToken,
+	}, nil
+}
+
+// exchangeClientCredentials menerbitkan token untuk komunikasi antar layanan
+// (RFC 6749 section 4.4). Tidak ada refresh token karena client bisa meminta ulang.
+func (uc *oauthUsecase) exchangeClientCredentials(client *domain.OauthClient) (*dto.OauthTokenResponseDTO, error) {
+	if client.IsPublic {
+		return nil, newOauthError("unauthorized_client", "public clients cannot use the client_credentials grant")
+	}
+
+	permissions := client.PermissionNames()
+	if len(permissions) == 0 {
+		return nil, newOauthError("unauthorized_client", "client has no permissions granted")
+	}
+
+	// Hak akses client ditentukan oleh permission-nya, bukan scope data user
+	accessToken, err := uc.jwtService.GenerateClientToken(client.ID, "", permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.OauthTokenResponseDTO{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(uc.jwtService.AccessTokenTTL().Seconds()),
+	}, nil
+}
+
+// UserInfo mengembalikan claim sesuai scope access token. Token first-party
+// (family tanpa OAuth client) mendapat seluruh claim.
+func (uc *oauthUsecase) UserInfo(userID, sessionID, scope string) (*dto.OidcUserInfoDTO, error) {
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Token tanpa scope dari OAuth client hanya mendapat claim openid
+	firstParty := uc.tokenUC.IsFirstPartySession(sessionID)
+	info := &dto.OidcUserInfoDTO{Sub: user.ID}
+
+	if firstParty || hasScope(scope, constants.ScopeProfile) {
+		info.Name = user.Name
+		if user.ImgPath != nil && user.ImgName != nil {
+			info.Picture = helper.GetUrlFile(*user.ImgPath, *user.ImgName)
+		}
+	}
+
+	if firstParty || hasScope(scope, constants.ScopeEmail) {
+		verified := user.EmailVerifiedAt != nil
+		info.Email = user.Email
+		info.EmailVerified = &verified
+	}
+
+	// Scope roles hanya membuka nama role; permission tetap milik first-party
+	if firstParty {
+		info.Roles, info.Permissions = collectRolesAndPermissions(user)
+	} else if hasScope(scope, constants.ScopeRoles) {
+		info.Roles, _ = collectRolesAndPermissions(user)
+	}
+
+	if firstParty || hasScope(scope, constants.ScopeAcademic) {
+		info.Academic = uc.academicInfo(user.ID)
+	}
+
+	return info, nil
+}
+
+func (uc *oauthUsecase) academicInfo(userID string) *dto.OidcAcademicInfoDTO {
+	if student, err := uc.studentRepo.FindByUserID(userID); err == nil {
+		return &dto.OidcAcademicInfoDTO{
+			NIM:              student.NIM,
+			Generation:       student.Generation,
+			StudyProgramID:   student.StudyProgram.ID,
+			StudyProgramName: student.StudyProgram.Name,
+			MajorID:          student.StudyProgram.Major.ID,
+			MajorName:        student.StudyProgram.Major.Name,
+		}
+	}
+
+	if employee, err := uc.employeeRepo.FindByUserID(userID); err == nil {
+		info := &dto.OidcAcademicInfoDTO{
+			NIP:      employee.Nip,
+			Position: employee.Position,
+		}
+		if employee.StudyProgramID != nil {
+			info.StudyProgramID = employee.StudyProgram.ID
+			info.StudyProgramName = employee.StudyProgram.Name
+		}
+		if employee.MajorID != nil {
+			info.MajorID = employee.Major.ID
+			info.MajorName = employee.Major.Name
+		}
+		return info
+	}
+
+	return nil
+}
+
+// Logout mengakhiri satu sesi SSO: token setiap client dicabut, client dengan
+// back-channel logout diberi tahu secara asinkron, lalu record SSO dihapus.
+// Nilai kembaliannya adalah URI front-channel logout yang harus dimuat browser.
+func (uc *oauthUsecase) Logout(sessionID string) ([]string, error) {
+	sessions, err := uc.ssoRepo.FindClients(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	notified := make(map[string]bool)
+	var frontchannelURIs []string
+	for _, session := range sessions {
+		if session.FamilyID != "" {
+			_ = uc.tokenUC.RevokeFamily(session.FamilyID)
+		}
+
+		if notified[session.ClientID] {
+			continue
+		}
+		notified[session.ClientID] = true
+
+		client, err := uc.clientRepo.FindByID(session.ClientID)
+		if err != nil {
+			continue
+		}
+
+		if client.BackchannelLogoutURI != nil && *client.BackchannelLogoutURI != "" {
+			go func(uri, clientID, userID string) {
+				if err := uc.oidcService.SendBackchannelLogout(uri, clientID, userID); err != nil {
+					log.Printf("Back-channel logout for client %s failed: %v", clientID, err)
+				}
+			}(*client.BackchannelLogoutURI, client.ID, session.UserID)
+		}
+
+		if client.FrontchannelLogoutURI != nil && *client.FrontchannelLogoutURI != "" {
+			frontchannelURIs = append(frontchannelURIs, frontchannelLogoutURL(*client.FrontchannelLogoutURI, uc.oidcService.Issuer()))
+		}
+	}
+
+	if err := uc.ssoRepo.Delete(sessionID); err != nil {
+		return nil, err
+	}
+
+	return frontchannelURIs, nil
+}
+
+// frontchannelLogoutURL menambahkan parameter iss (OIDC Front-Channel Logout 1.0 section 2).
+func frontchannelLogoutURL(logoutURI, issuer string) string {
+	u, err := url.Parse(logoutURI)
+	if err != nil {
+		return logoutURI
+	}
+
+	q := u.Query()
+	q.Set("iss", issuer)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// validateCodeChallenge menerapkan RFC 7636: public client wajib memakai PKCE dan
+// hanya metode S256 yang diterima.
+func validateCodeChallenge(req dto.OauthAuthorizeRequestDTO, client *domain.OauthClient) error {
+	if req.CodeChallenge == "" {
+		if client.IsPublic {
+			return newOauthError("invalid_request", "code_challenge is required for public clients")
+		}
+		return nil
+	}
+
+	if req.CodeChallengeMethod != "S256" {
+		return newOauthError("invalid_request", "code_challenge_method must be S256")
+	}
+
+	// base64url dari SHA-256 selalu 43 karakter
+	if len(req.CodeChallenge) != 43 {
+		return newOauthError("invalid_request", "code_challenge is malformed")
+	}
+
+	return nil
+}
+
+func verifyCodeVerifier(ac dto.StoreOauthCodeDTO, verifier string, client *domain.OauthClient) error {
+	if ac.CodeChallenge == "" {
+		if client.IsPublic {
+			return newOauthError("invalid_grant", "authorization code was not bound to a code_challenge")
+		}
+		return nil
+	}
+
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return newOauthError("invalid_grant", "code_verifier is missing or malformed")
+	}
+
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(ac.CodeChallenge)) != 1 {
+		return newOauthError("invalid_grant", "code_verifier does not match code_challenge")
+	}
+
+	return nil
+}
+
+func hasScope(scope, want string) bool {
+	return slices.Contains(strings.Fields(scope), want)
+}
+
+func collectRolesAndPermissions(user *domain.User) ([]string, []string) {
+	var roleNames []string
+	var permissionNames []string
+	permissionSet := make(map[string]struct{})
+
+	for _, role := range user.Roles {
+		roleNames = append(roleNames, role.Name)
+		for _, perm := range role.Permissions {
+			permissionSet[perm.Name] = struct{}{}
+		}
+	}
+
+	for perm := range permissionSet {
+		permissionNames = append(permissionNames, perm)
+	}
+
+	return roleNames, permissionNames
+}
@@ -0,0 +1,258 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"jti-super-app-go/config"
+	"jti-super-app-go/internal/domain"
+	"jti-super-app-go/internal/dto"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testCodeVerifier = "dBjftJeZ4CVP-mJ92K9HwS7oUQ8xrZbXxXtbBGvXcQkq9zU"
+
+func s256Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func TestValidateCodeChallenge(t *testing.T) {
+	public := &domain.OauthClient{ID: "spa", IsPublic: true}
+	confidential := &domain.OauthClient{ID: "web"}
+	challenge := s256Challenge(testCodeVerifier)
+
+	tests := []struct {
+		name    string
+		client  *domain.OauthClient
+		method  string
+		value   string
+		wantErr bool
+	}{
+		{name: "public client with S256", client: public, method: "S256", value: challenge},
+		{name: "public client without challenge", client: public, wantErr: true},
+		{name: "public client with plain", client: public, method: "plain", value: testCodeVerifier[:43], wantErr: true},
+		{name: "public client without method", client: public, value: challenge, wantErr: true},
+		{name: "malformed challenge", client: public, method: "S256", value: challenge[:42], wantErr: true},
+		{name: "confidential client without challenge", client: confidential},
+		{name: "confidential client with plain", client: confidential, method: "plain", value: testCodeVerifier[:43], wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := dto.OauthAuthorizeRequestDTO{CodeChallenge: tt.value, CodeChallengeMethod: tt.method}
+			if err := validateCodeChallenge(req, tt.client); (err != nil) != tt.wantErr {
+				t.Fatalf("validateCodeChallenge() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyCodeVerifier(t *testing.T) {
+	public := &domain.OauthClient{ID: "spa", IsPublic: true}
+	confidential := &domain.OauthClient{ID: "web"}
+	challenge := s256Challenge(testCodeVerifier)
+
+	tests := []struct {
+		name      string
+		client    *domain.OauthClient
+		challenge string
+		verifier  string
+		wantErr   bool
+	}{
+		{name: "S256 match", client: public, challenge: challenge, verifier: testCodeVerifier},
+		{name: "S256 mismatch", client: public, challenge: challenge, verifier: strings.Repeat("a", 43), wantErr: true},
+		{name: "missing verifier", client: public, challenge: challenge, wantErr: true},
+		{name: "verifier too short", client: public, challenge: s256Challenge("short"), verifier: "short", wantErr: true},
+		{name: "verifier too long", client: public, challenge: s256Challenge(strings.Repeat("a", 129)), verifier: strings.Repeat("a", 129), wantErr: true},
+		// Verifier yang sama dengan challenge (metode plain) tidak diterima
+		{name: "plain verifier", client: public, challenge: testCodeVerifier[:43], verifier: testCodeVerifier[:43], wantErr: true},
+		{name: "public client code without challenge", client: public, verifier: testCodeVerifier, wantErr: true},
+		{name: "confidential client code without challenge", client: confidential},
+		{name: "confidential client still checked", client: confidential, challenge: challenge, verifier: strings.Repeat("a", 43), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ac := dto.StoreOauthCodeDTO{CodeChallenge: tt.challenge, CodeChallengeMethod: "S256"}
+			err := verifyCodeVerifier(ac, tt.verifier, tt.client)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("verifyCodeVerifier() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var oauthErr *OauthError
+			if tt.wantErr && (!errors.As(err, &oauthErr) || oauthErr.Code != "invalid_grant") {
+				t.Errorf("verifyCodeVerifier() error = %v, want invalid_grant", err)
+			}
+		})
+	}
+}
+
+type stubSSOSessionRepo struct {
+	domain.SSOSessionRepository
+}
+
+func (stubSSOSessionRepo) AddClient(sessionID string, client domain.SSOSessionClient, ttl time.Duration) error {
+	return nil
+}
+
+// hookedTokenUseCase menjalankan afterIssue setelah token diterbitkan untuk
+// mensimulasikan request lain yang datang selama penukaran code berlangsung.
+type hookedTokenUseCase struct {
+	TokenUseCase
+	afterIssue func(tokens *dto.TokenPairDTO)
+}
+
+func (uc *hookedTokenUseCase) IssueForUser(user *domain.User, clientID, scope string) (*dto.TokenPairDTO, error) {
+	tokens, err := uc.TokenUseCase.IssueForUser(user, clientID, scope)
+	if err == nil && uc.afterIssue != nil {
+		uc.afterIssue(tokens)
+	}
+	return tokens, err
+}
+
+type oauthTestFixture struct {
+	uc     *oauthUsecase
+	tokens *hookedTokenUseCase
+	client *domain.OauthClient
+	user   *domain.User
+}
+
+func newOauthTestFixture(t *testing.T) *oauthTestFixture {
+	t.Helper()
+	useTestRedis(t)
+	user := activeTestUser()
+	tokens := &hookedTokenUseCase{TokenUseCase: newTestTokenUseCase(user)}
+	return &oauthTestFixture{
+		uc:     &oauthUsecase{userRepo: &stubUserRepo{user: user}, ssoRepo: stubSSOSessionRepo{}, tokenUC: tokens},
+		tokens: tokens,
+		client: &domain.OauthClient{ID: "spa", IsPublic: true, RedirectURIs: []string{"https://spa.example.com/callback"}},
+		user:   user,
+	}
+}
+
+// storeCode menyimpan authorization code seperti yang dilakukan Authorize.
+func (f *oauthTestFixture) storeCode(t *testing.T, code string) dto.OauthTokenRequestDTO {
+	t.Helper()
+	data, err := json.Marshal(dto.StoreOauthCodeDTO{
+		Code:                code,
+		ClientID:            f.client.ID,
+		UserID:              f.user.ID,
+		RedirectURI:         f.client.RedirectURIs[0],
+		CodeChallenge:       s256Challenge(testCodeVerifier),
+		CodeChallengeMethod: "S256",
+		AuthTime:            time.Now(),
+		ExpiresAt:           time.Now().Add(oauthCodeTTL),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Rdb.Set(context.Background(), oauthCodePrefix+code, data, oauthCodeTTL).Err(); err != nil {
+		t.Fatal(err)
+	}
+	return dto.OauthTokenRequestDTO{GrantType: "authorization_code", Code: code, RedirectURI: f.client.RedirectURIs[0], CodeVerifier: testCodeVerifier}
+}
+
+func assertInvalidGrant(t *testing.T, err error) {
+	t.Helper()
+	var oauthErr *OauthError
+	if !errors.As(err, &oauthErr) || oauthErr.Code != "invalid_grant" {
+		t.Fatalf("Exchange() error = %v, want invalid_grant", err)
+	}
+}
+
+func TestExchangeAuthorizationCodeReplay(t *testing.T) {
+	tests := []struct {
+		name string
+		// replayDuringIssue memakai ulang code sebelum penukaran pertama selesai
+		replayDuringIssue bool
+	}{
+		{name: "replay after the exchange revokes the issued family"},
+		{name: "replay during the exchange revokes the issued family", replayDuringIssue: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newOauthTestFixture(t)
+			req := f.storeCode(t, "code-1")
+
+			var replayErr error
+			var issued *dto.TokenPairDTO
+			f.tokens.afterIssue = func(tokens *dto.TokenPairDTO) {
+				issued = tokens
+				if tt.replayDuringIssue {
+					_, replayErr = f.uc.Exchange(req, f.client)
+				}
+			}
+
+			_, err := f.uc.Exchange(req, f.client)
+			if tt.replayDuringIssue {
+				assertInvalidGrant(t, err)
+			} else {
+				if err != nil {
+					t.Fatalf("Exchange() error = %v", err)
+				}
+				_, replayErr = f.uc.Exchange(req, f.client)
+			}
+			assertInvalidGrant(t, replayErr)
+
+			revoked, err := f.tokens.IsSessionRevoked(issued.SessionID)
+			if err != nil || !revoked {
+				t.Errorf("IsSessionRevoked() = %v, %v, want true", revoked, err)
+			}
+			// Refresh token dari penukaran pertama ikut dicabut
+			if _, err := f.tokens.Refresh(issued.RefreshToken, f.client.ID); !errors.Is(err, ErrInvalidRefreshToken) {
+				t.Errorf("Refresh() after replay error = %v, want ErrInvalidRefreshToken", err)
+			}
+		})
+	}
+}
+
+func TestExchangeAuthorizationCodeRepeatedReplay(t *testing.T) {
+	f := newOauthTestFixture(t)
+	req := f.storeCode(t, "code-1")
+
+	res, err := f.uc.Exchange(req, f.client)
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	// Replay berikutnya tidak menerbitkan ulang dan tidak gagal karena family sudah dicabut
+	for range 2 {
+		_, err := f.uc.Exchange(req, f.client)
+		assertInvalidGrant(t, err)
+	}
+
+	rotated, err := f.tokens.Refresh(res.RefreshToken, f.client.ID)
+	if !errors.Is(err, ErrInvalidRefreshToken) || rotated != nil {
+		t.Fatalf("Refresh() error = %v, want ErrInvalidRefreshToken", err)
+	}
+}
+
+func TestExchangeAuthorizationCodeRejectsWrongVerifier(t *testing.T) {
+	f := newOauthTestFixture(t)
+	req := f.storeCode(t, "code-1")
+	req.CodeVerifier = strings.Repeat("a", 43)
+
+	_, err := f.uc.Exchange(req, f.client)
+	assertInvalidGrant(t, err)
+
+	// Code sudah terpakai oleh percobaan yang gagal sehingga verifier yang benar pun ditolak
+	req.CodeVerifier = testCodeVerifier
+	_, err = f.uc.Exchange(req, f.client)
+	assertInvalidGrant(t, err)
+}
+
+func TestExchangeUnknownCodeLeavesNoMarker(t *testing.T) {
+	f := newOauthTestFixture(t)
+	req := dto.OauthTokenRequestDTO{Code: "unknown", RedirectURI: f.client.RedirectURIs[0], CodeVerifier: testCodeVerifier}
+
+	_, err := f.uc.Exchange(req, f.client)
+	assertInvalidGrant(t, err)
+
+	if n, err := config.Rdb.Exists(context.Background(), oauthCodeUsedPrefix+req.Code).Result(); err != nil || n != 0 {
+		t.Errorf("marker for an unknown code exists = %d, %v", n, err)
+	}
+}
@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"jti-super-app-go/internal/domain"
+	"jti-super-app-go/internal/dto"
+	"jti-super-app-go/internal/service"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordPolicyError berisi semua aturan password policy yang dilanggar.
+type PasswordPolicyError struct {
+	Violations []dto.PasswordViolationDTO
+}
+
+func (e *PasswordPolicyError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+type PasswordUseCase interface {
+	// Validate memeriksa password untuk akun yang belum ada, mis. saat admin
+	// membuat mahasiswa atau pegawai baru.
+	Validate(password string, personal ...string) error
+	// Change memeriksa password policy dan riwayat password, lalu menyimpan
+	// password baru dan menandai user sudah mengganti password default.
+	Change(user *domain.User, password string) error
+}
+
+type passwordUseCase struct {
+	userRepo     domain.UserRepository
+	studentRepo  domain.StudentRepository
+	employeeRepo domain.EmployeeRepository
+	historyRepo  domain.PasswordHistoryRepository
+	policy       service.PasswordPolicyService
+}
+
+func NewPasswordUseCase(userRepo domain.UserRepository, studentRepo domain.StudentRepository, employeeRepo domain.EmployeeRepository, historyRepo domain.PasswordHistoryRepository, policy service.PasswordPolicyService) PasswordUseCase {
+	return &passwordUseCase{
+		userRepo:     userRepo,
+		studentRepo:  studentRepo,
+		employeeRepo: employeeRepo,
+		historyRepo:  historyRepo,
+		policy:       policy,
+	}
+}
+
+func (uc *passwordUseCase) Validate(password string, personal ...string) error {
+	if violations := uc.policy.Check(password, personal...); len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+func (uc *passwordUseCase) Change(user *domain.User, password string) error {
+	violations := uc.policy.Check(password, uc.personalInfo(user)...)
+	reused, err := uc.recentlyUsed(user, password)
+	if err != nil {
+		return err
+	}
+	if reused {
+		violations = append(violations, dto.PasswordViolationDTO{
+			Code:    service.PasswordViolationRecentlyUsed,
+			Message: "password must not match any of your recent passwords",
+		})
+	}
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	user.Password = string(hashedPassword)
+	user.IsChangePassword = true
+	if _, err := uc.userRepo.Update(user.ID, user); err != nil {
+		return err
+	}
+
+	return uc.historyRepo.Push(&domain.PasswordHistory{
+		ID:        uuid.NewString(),
+		UserID:    user.ID,
+		Password:  user.Password,
+		CreatedAt: time.Now(),
+	}, uc.policy.HistorySize())
+}
+
+// recentlyUsed membandingkan dengan password aktif dan riwayat password.
+// Password aktif ikut dicek karena akun lama belum punya riwayat.
+func (uc *passwordUseCase) recentlyUsed(user *domain.User, password string) (bool, error) {
+	size := uc.policy.HistorySize()
+	if size <= 0 {
+		return false, nil
+	}
+
+	hashes := []string{user.Password}
+	histories, err := uc.historyRepo.FindRecent(user.ID, size)
+	if err != nil {
+		return false, err
+	}
+	for _, h := range histories {
+		hashes = append(hashes, h.Password)
+	}
+
+	for _, hash := range hashes {
+		if hash != "" && bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (uc *passwordUseCase) personalInfo(user *domain.User) []string {
+	personal := []string{user.Name, user.Email}
+	if student, err := uc.studentRepo.FindByUserID(user.ID); err == nil {
+		personal = append(personal, student.NIM)
+	}
+	if employee, err := uc.employeeRepo.FindByUserID(user.ID); err == nil {
+		personal = append(personal, employee.Nip)
+	}
+	return personal
+}
@@ -17,11 +17,12 @@ type PermissionUseCase interface {
 }
 
 type permissionUseCase struct {
-	repo domain.PermissionRepository
+	repo            domain.PermissionRepository
+	authorizationUC AuthorizationUseCase
 }
 
-func NewPermissionUseCase(repo domain.PermissionRepository) PermissionUseCase {
-	return &permissionUseCase{repo: repo}
+func NewPermissionUseCase(repo domain.PermissionRepository, authorizationUC AuthorizationUseCase) PermissionUseCase {
+	return &permissionUseCase{repo: repo, authorizationUC: authorizationUC}
 }
 
 func (u *permissionUseCase) FindByID(id string) (*domain.Permission, error) {
@@ -51,9 +52,18 @@ func (u *permissionUseCase) Update(id string, dto *dto.UpdatePermissionDTO) (*do
 		Name: dto.Name,
 	}
 
-	return u.repo.Update(id, permission)
+	updated, err := u.repo.Update(id, permission)
+	if err != nil {
+		return nil, err
+	}
+	u.authorizationUC.InvalidateAll()
+	return updated, nil
 }
 
 func (u *permissionUseCase) Delete(id string) error {
-	return u.repo.Delete(id)
+	if err := u.repo.Delete(id); err != nil {
+		return err
+	}
+	u.authorizationUC.InvalidateAll()
+	return nil
 }
@@ -0,0 +1,186 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"jti-super-app-go/internal/domain"
+	"jti-super-app-go/internal/dto"
+	"jti-super-app-go/pkg/constants"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// personalAccessTokenUsageInterval membatasi penulisan last_used_at agar
+// script yang memanggil API terus-menerus tidak menulis ke database tiap request.
+const personalAccessTokenUsageInterval = time.Minute
+
+var (
+	ErrPersonalAccessTokenNotFound = errors.New("personal access token not found")
+	ErrInvalidPersonalAccessToken  = errors.New("invalid or expired personal access token")
+	ErrPermissionNotHeld           = errors.New("token permissions must be a subset of your own permissions")
+	ErrInvalidTokenExpiry          = errors.New("expires_at must be in the future")
+	ErrPermissionNotDelegable      = errors.New("this permission cannot be granted to a personal access token")
+)
+
+// Permission yang menerbitkan token atas nama user lain tidak boleh dipegang
+// script berumur panjang.
+var nonDelegablePermissions = []string{constants.PermissionImpersonateUsers}
+
+// PersonalAccessTokenUseCase mengelola token berumur panjang yang dipakai
+// script untuk memanggil API atas nama user.
+type PersonalAccessTokenUseCase interface {
+	FindByUserID(userID string) ([]dto.PersonalAccessTokenResource, error)
+	// Create membatasi permission token ke permission yang dimiliki token
+	// pemanggil, bukan seluruh permission user.
+	Create(userID string, caller *domain.PermissionSet, req dto.StorePersonalAccessTokenDTO) (*dto.CreatedPersonalAccessTokenResponseDTO, error)
+	Revoke(userID, id string) error
+	// Authenticate memvalidasi token mentah dan mengembalikan permission
+	// efektifnya, yaitu irisan permission token dengan permission user saat ini.
+	Authenticate(token string) (*domain.PersonalAccessToken, *domain.PermissionSet, error)
+}
+
+type personalAccessTokenUseCase struct {
+	repo            domain.PersonalAccessTokenRepository
+	authorizationUC AuthorizationUseCase
+}
+
+func NewPersonalAccessTokenUseCase(repo domain.PersonalAccessTokenRepository, authorizationUC AuthorizationUseCase) PersonalAccessTokenUseCase {
+	return &personalAccessTokenUseCase{repo: repo, authorizationUC: authorizationUC}
+}
+
+func (uc *personalAccessTokenUseCase) FindByUserID(userID string) ([]dto.PersonalAccessTokenResource, error) {
+	tokens, err := uc.repo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]dto.PersonalAccessTokenResource, 0, len(tokens))
+	for _, token := range tokens {
+		resources = append(resources, toPersonalAccessTokenResource(&token))
+	}
+	return resources, nil
+}
+
+func (uc *personalAccessTokenUseCase) Create(userID string, caller *domain.PermissionSet, req dto.StorePersonalAccessTokenDTO) (*dto.CreatedPersonalAccessTokenResponseDTO, error) {
+	now := time.Now()
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return nil, ErrInvalidTokenExpiry
+	}
+
+	permissions := make([]string, 0, len(req.Permissions))
+	for _, name := range req.Permissions {
+		if slices.Contains(nonDelegablePermissions, name) {
+			return nil, ErrPermissionNotDelegable
+		}
+		if !holdsPermission(caller, name) {
+			return nil, ErrPermissionNotHeld
+		}
+		if !slices.Contains(permissions, name) {
+			permissions = append(permissions, name)
+		}
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	plain := constants.PersonalAccessTokenPrefix + base64.RawURLEncoding.EncodeToString(random)
+
+	token := &domain.PersonalAccessToken{
+		ID:          uuid.NewString(),
+		UserID:      userID,
+		Name:        req.Name,
+		TokenHash:   hashToken(plain),
+		Permissions: permissions,
+		ExpiresAt:   req.ExpiresAt,
+		CreatedAt:   now,
+	}
+	if err := uc.repo.Create(token); err != nil {
+		return nil, err
+	}
+
+	return &dto.CreatedPersonalAccessTokenResponseDTO{
+		PersonalAccessTokenResource: toPersonalAccessTokenResource(token),
+		Token:                       plain,
+	}, nil
+}
+
+func (uc *personalAccessTokenUseCase) Revoke(userID, id string) error {
+	err := uc.repo.Delete(userID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrPersonalAccessTokenNotFound
+	}
+	return err
+}
+
+func (uc *personalAccessTokenUseCase) Authenticate(plain string) (*domain.PersonalAccessToken, *domain.PermissionSet, error) {
+	if !strings.HasPrefix(plain, constants.PersonalAccessTokenPrefix) {
+		return nil, nil, ErrInvalidPersonalAccessToken
+	}
+
+	token, err := uc.repo.FindByHash(hashToken(plain))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, ErrInvalidPersonalAccessToken
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	if token.Expired(now) {
+		return nil, nil, ErrInvalidPersonalAccessToken
+	}
+
+	// Permission yang dicabut dari user ikut hilang dari token-nya
+	userSet, err := uc.authorizationUC.Resolve(token.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	set := &domain.PermissionSet{Version: userSet.Version, Roles: []string{}, Permissions: []string{}}
+	for _, name := range token.Permissions {
+		if slices.Contains(nonDelegablePermissions, name) {
+			continue
+		}
+		if slices.Contains(userSet.Permissions, name) {
+			set.Permissions = append(set.Permissions, name)
+		} else if scope, ok := userSet.Scopes[name]; ok {
+			if set.Scopes == nil {
+				set.Scopes = make(map[string]dto.AccessScope)
+			}
+			set.Scopes[name] = scope
+		}
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= personalAccessTokenUsageInterval {
+		if err := uc.repo.UpdateLastUsed(token.ID, now); err != nil {
+			log.Printf("failed to record usage of personal access token %s: %v", token.ID, err)
+		}
+	}
+
+	return token, set, nil
+}
+
+func holdsPermission(set *domain.PermissionSet, name string) bool {
+	if slices.Contains(set.Permissions, name) {
+		return true
+	}
+	_, ok := set.Scopes[name]
+	return ok
+}
+
+func toPersonalAccessTokenResource(token *domain.PersonalAccessToken) dto.PersonalAccessTokenResource {
+	return dto.PersonalAccessTokenResource{
+		ID:          token.ID,
+		Name:        token.Name,
+		Permissions: token.Permissions,
+		LastUsedAt:  token.LastUsedAt,
+		ExpiresAt:   token.ExpiresAt,
+		CreatedAt:   token.CreatedAt,
+	}
+}
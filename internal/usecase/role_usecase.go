@@ -14,14 +14,16 @@ type RoleUseCase interface {
 	Create(role *dto.StoreRoleDTO) (*domain.Role, error)
 	Update(id string, role *dto.UpdateRoleDTO) (*domain.Role, error)
 	Delete(id string) error
+	GrantPermissions(roleName string, permissionNames []string) error
 }
 
 type roleUseCase struct {
-	repo domain.RoleRepository
+	repo            domain.RoleRepository
+	authorizationUC AuthorizationUseCase
 }
 
-func NewRoleUseCase(repo domain.RoleRepository) RoleUseCase {
-	return &roleUseCase{repo: repo}
+func NewRoleUseCase(repo domain.RoleRepository, authorizationUC AuthorizationUseCase) RoleUseCase {
+	return &roleUseCase{repo: repo, authorizationUC: authorizationUC}
 }
 
 func (u *roleUseCase) FindByID(id string) (*domain.Role, error) {
@@ -38,10 +40,11 @@ func (u *roleUseCase) Create(dto *dto.StoreRoleDTO) (*domain.Role, error) {
 		permissions = append(permissions, domain.Permission{ID: permID})
 	}
 	role := &domain.Role{
-		ID:          uuid.NewString(),
-		Name:        dto.Name,
-		GuardName:   "web",
-		Permissions: permissions,
+		ID:                uuid.NewString(),
+		Name:              dto.Name,
+		GuardName:         "web",
+		RequiresTwoFactor: dto.RequiresTwoFactor,
+		Permissions:       permissions,
 	}
 
 	return u.repo.Create(role)
@@ -53,16 +56,36 @@ func (u *roleUseCase) Update(id string, role *dto.UpdateRoleDTO) (*domain.Role,
 		permissions = append(permissions, domain.Permission{ID: permID})
 	}
 	updatedRole := &domain.Role{
-		Name:        role.Name,
-		Permissions: permissions,
+		Name:              role.Name,
+		RequiresTwoFactor: role.RequiresTwoFactor,
+		Permissions:       permissions,
 	}
-	return u.repo.Update(id, updatedRole)
+	updated, err := u.repo.Update(id, updatedRole)
+	if err != nil {
+		return nil, err
+	}
+	// Role baru belum dimiliki siapa pun, jadi hanya perubahan dan penghapusan
+	// yang perlu menginvalidasi permission user
+	u.authorizationUC.InvalidateAll()
+	return updated, nil
 }
 
 func (u *roleUseCase) Delete(id string) error {
-	return u.repo.Delete(id)
+	if err := u.repo.Delete(id); err != nil {
+		return err
+	}
+	u.authorizationUC.InvalidateAll()
+	return nil
 }
 
 func (u *roleUseCase) FindAllAsOptions() (*[]domain.Role, error) {
 	return u.repo.FindAllAsOptions()
 }
+
+func (u *roleUseCase) GrantPermissions(roleName string, permissionNames []string) error {
+	if err := u.repo.AttachPermissions(roleName, permissionNames); err != nil {
+		return err
+	}
+	u.authorizationUC.InvalidateAll()
+	return nil
+}
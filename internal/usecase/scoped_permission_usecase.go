@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"errors"
+	"jti-super-app-go/internal/domain"
+	"jti-super-app-go/internal/dto"
+	"jti-super-app-go/pkg/constants"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrOutsideAccessScope dikembalikan saat data yang dituju berada di luar
+	// jurusan, program studi, atau lab yang menjadi scope permission pemanggil.
+	ErrOutsideAccessScope       = errors.New("resource is outside of your access scope")
+	ErrScopedPermissionNotFound = errors.New("scoped permission not found")
+	ErrPermissionScopeNotFound  = errors.New("scope target not found")
+	ErrPermissionNotScopable    = errors.New("this permission cannot be granted within a scope")
+)
+
+// ScopedPermissionUseCase mengelola permission user yang hanya berlaku di
+// dalam satu jurusan, program studi, atau lab.
+type ScopedPermissionUseCase interface {
+	FindByUserID(userID string) ([]dto.ScopedPermissionResource, error)
+	Grant(userID string, req dto.StoreScopedPermissionDTO) (*dto.ScopedPermissionResource, error)
+	Revoke(userID, id string) error
+}
+
+type scopedPermissionUseCase struct {
+	repo            domain.ScopedPermissionRepository
+	userRepo        domain.UserRepository
+	permissionRepo  domain.PermissionRepository
+	authorizationUC AuthorizationUseCase
+}
+
+func NewScopedPermissionUseCase(repo domain.ScopedPermissionRepository, userRepo domain.UserRepository, permissionRepo domain.PermissionRepository, authorizationUC AuthorizationUseCase) ScopedPermissionUseCase {
+	return &scopedPermissionUseCase{
+		repo:            repo,
+		userRepo:        userRepo,
+		permissionRepo:  permissionRepo,
+		authorizationUC: authorizationUC,
+	}
+}
+
+func (uc *scopedPermissionUseCase) FindByUserID(userID string) ([]dto.ScopedPermissionResource, error) {
+	grants, err := uc.repo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]dto.ScopedPermissionResource, 0, len(grants))
+	for _, grant := range grants {
+		resources = append(resources, toScopedPermissionResource(&grant))
+	}
+	return resources, nil
+}
+
+func (uc *scopedPermissionUseCase) Grant(userID string, req dto.StoreScopedPermissionDTO) (*dto.ScopedPermissionResource, error) {
+	if _, err := uc.userRepo.FindByID(userID); err != nil {
+		return nil, err
+	}
+
+	permission, err := uc.permissionRepo.FindByID(req.PermissionID)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(constants.ScopablePermissions, permission.Name) {
+		return nil, ErrPermissionNotScopable
+	}
+
+	exists, err := uc.repo.ScopeExists(req.ScopeType, req.ScopeID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrPermissionScopeNotFound
+	}
+
+	grant := &domain.ScopedPermission{
+		ID:           uuid.NewString(),
+		UserID:       userID,
+		PermissionID: permission.ID,
+		ScopeType:    req.ScopeType,
+		ScopeID:      req.ScopeID,
+		CreatedAt:    time.Now(),
+		Permission:   *permission,
+	}
+	if err := uc.repo.Create(grant); err != nil {
+		return nil, err
+	}
+	uc.authorizationUC.InvalidateUsers(userID)
+
+	resource := toScopedPermissionResource(grant)
+	return &resource, nil
+}
+
+func (uc *scopedPermissionUseCase) Revoke(userID, id string) error {
+	err := uc.repo.Delete(userID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrScopedPermissionNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	uc.authorizationUC.InvalidateUsers(userID)
+	return nil
+}
+
+func toScopedPermissionResource(grant *domain.ScopedPermission) dto.ScopedPermissionResource {
+	return dto.ScopedPermissionResource{
+		ID:             grant.ID,
+		PermissionID:   grant.PermissionID,
+		PermissionName: grant.Permission.Name,
+		ScopeType:      grant.ScopeType,
+		ScopeID:        grant.ScopeID,
+		CreatedAt:      grant.CreatedAt,
+	}
+}
@@ -17,8 +17,8 @@ import (
 
 type StudentUseCase interface {
 	FindAll(params dto.QueryParams) (*[]domain.Student, int64, error)
-	FindByID(id string) (*domain.Student, error)
-	Create(payload *dto.StoreStudentDTO) (*domain.Student, error)
+	FindByID(id string, scope *dto.AccessScope) (*domain.Student, error)
+	Create(payload *dto.StoreStudentDTO, scope *dto.AccessScope) (*domain.Student, error)
 	Update(id string, payload *dto.UpdateStudentDTO) (*domain.Student, error)
 }
 
@@ -27,14 +27,16 @@ type studentUseCase struct {
 	studentRepo         domain.StudentRepository
 	userRepo            domain.UserRepository
 	studentSemesterRepo domain.StudentSemesterRepository
+	passwordUC          PasswordUseCase
 }
 
-func NewStudentUseCase(db *gorm.DB, studentRepo domain.StudentRepository, userRepo domain.UserRepository, studentSemesterRepo domain.StudentSemesterRepository) StudentUseCase {
+func NewStudentUseCase(db *gorm.DB, studentRepo domain.StudentRepository, userRepo domain.UserRepository, studentSemesterRepo domain.StudentSemesterRepository, passwordUC PasswordUseCase) StudentUseCase {
 	return &studentUseCase{
 		db:                  db,
 		studentRepo:         studentRepo,
 		userRepo:            userRepo,
 		studentSemesterRepo: studentSemesterRepo,
+		passwordUC:          passwordUC,
 	}
 }
 
@@ -42,11 +44,25 @@ func (u *studentUseCase) FindAll(params dto.QueryParams) (*[]domain.Student, int
 	return u.studentRepo.FindAll(params)
 }
 
-func (u *studentUseCase) Create(payload *dto.StoreStudentDTO) (*domain.Student, error) {
+func (u *studentUseCase) Create(payload *dto.StoreStudentDTO, scope *dto.AccessScope) (*domain.Student, error) {
+	if !scope.AllowsStudyProgram(payload.StudyProgramID) {
+		return nil, ErrOutsideAccessScope
+	}
+
 	var newStudent *domain.Student
 	imgPath := constants.STUDENT_PATH
 	imgName := constants.DEFAULT_AVATAR
 
+	// Password default (NIM) sengaja dikecualikan dari password policy karena
+	// wajib diganti saat login pertama
+	initialPassword := payload.NIM
+	if payload.Password != nil && *payload.Password != "" {
+		if err := u.passwordUC.Validate(*payload.Password, payload.NIM, payload.Name, payload.Email); err != nil {
+			return nil, err
+		}
+		initialPassword = *payload.Password
+	}
+
 	tx := u.db.Begin()
 	if tx.Error != nil {
 		return nil, tx.Error
@@ -70,7 +86,7 @@ func (u *studentUseCase) Create(payload *dto.StoreStudentDTO) (*domain.Student,
 		}
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(payload.NIM), bcrypt.DefaultCost)
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(initialPassword), bcrypt.DefaultCost)
 	if err != nil {
 		tx.Rollback()
 		return nil, err
@@ -141,7 +157,7 @@ func (u *studentUseCase) Create(payload *dto.StoreStudentDTO) (*domain.Student,
 	return newStudent, nil
 }
 
-func (u *studentUseCase) FindByID(id string) (*domain.Student, error) {
+func (u *studentUseCase) FindByID(id string, scope *dto.AccessScope) (*domain.Student, error) {
 	student, err := u.studentRepo.FindByID(id)
 	if err != nil {
 		return nil, err
@@ -151,6 +167,10 @@ func (u *studentUseCase) FindByID(id string) (*domain.Student, error) {
 		return nil, fmt.Errorf("student with ID %s not found", id)
 	}
 
+	if !scope.AllowsStudyProgram(student.StudentProgramID) {
+		return nil, ErrOutsideAccessScope
+	}
+
 	return student, nil
 }
 
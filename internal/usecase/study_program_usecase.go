@@ -17,11 +17,12 @@ type StudyProgramUseCase interface {
 }
 
 type studyProgramUseCase struct {
-	repo domain.StudyProgramRepository
+	repo            domain.StudyProgramRepository
+	authorizationUC AuthorizationUseCase
 }
 
-func NewStudyProgramUseCase(repo domain.StudyProgramRepository) StudyProgramUseCase {
-	return &studyProgramUseCase{repo: repo}
+func NewStudyProgramUseCase(repo domain.StudyProgramRepository, authorizationUC AuthorizationUseCase) StudyProgramUseCase {
+	return &studyProgramUseCase{repo: repo, authorizationUC: authorizationUC}
 }
 
 func (u *studyProgramUseCase) FindAll(params dto.QueryParams, majorId string) (*[]domain.StudyProgram, int64, error) {
@@ -42,7 +43,13 @@ func (u *studyProgramUseCase) Create(dto *dto.StoreStudyProgramDTO) (*domain.Stu
 		Name:    dto.Name,
 		MajorID: dto.MajorID,
 	}
-	return u.repo.Create(studyProgram)
+	created, err := u.repo.Create(studyProgram)
+	if err != nil {
+		return nil, err
+	}
+	// Scope jurusan dijabarkan ke daftar program studi saat permission dimuat
+	u.authorizationUC.InvalidateAll()
+	return created, nil
 }
 
 func (u *studyProgramUseCase) Update(id string, dto *dto.UpdateStudyProgramDTO) (*domain.StudyProgram, error) {
@@ -51,9 +58,18 @@ func (u *studyProgramUseCase) Update(id string, dto *dto.UpdateStudyProgramDTO)
 		Name:    dto.Name,
 		MajorID: dto.MajorID,
 	}
-	return u.repo.Update(id, studyProgram)
+	updated, err := u.repo.Update(id, studyProgram)
+	if err != nil {
+		return nil, err
+	}
+	u.authorizationUC.InvalidateAll()
+	return updated, nil
 }
 
 func (u *studyProgramUseCase) Delete(id string) error {
-	return u.repo.Delete(id)
+	if err := u.repo.Delete(id); err != nil {
+		return err
+	}
+	u.authorizationUC.InvalidateAll()
+	return nil
 }
@@ -10,11 +10,11 @@ import (
 type SubjectUseCase interface {
 	FindAll(params dto.QueryParams) (*[]domain.Subject, int64, error)
 	FindAllAsOptions(studyProgramID, semesterID string) (*[]domain.Subject, error)
-	Create(payload *dto.StoreSubjectDTO) (*domain.Subject, error)
-	Update(id string, payload *dto.UpdateSubjectDTO) (*domain.Subject, error)
-	Delete(id string) error
-	GetLectureOnSubject(studyProgramID, semesterID string) (*[]domain.SubjectSemester, error)
-	StoreLectureOnSubject(data []dto.LectureMappingDTO) error
+	Create(payload *dto.StoreSubjectDTO, scope *dto.AccessScope) (*domain.Subject, error)
+	Update(id string, payload *dto.UpdateSubjectDTO, scope *dto.AccessScope) (*domain.Subject, error)
+	Delete(id string, scope *dto.AccessScope) error
+	GetLectureOnSubject(studyProgramID, semesterID string, scope *dto.AccessScope) (*[]domain.SubjectSemester, error)
+	StoreLectureOnSubject(data []dto.LectureMappingDTO, scope *dto.AccessScope) error
 }
 
 type subjectUseCase struct {
@@ -34,7 +34,11 @@ func (u *subjectUseCase) FindAllAsOptions(studyProgramID, semesterID string) (*[
 	return u.subjectRepo.FindAllAsOptions(studyProgramID, semesterID)
 }
 
-func (u *subjectUseCase) Create(payload *dto.StoreSubjectDTO) (*domain.Subject, error) {
+func (u *subjectUseCase) Create(payload *dto.StoreSubjectDTO, scope *dto.AccessScope) (*domain.Subject, error) {
+	if !scope.AllowsStudyProgram(payload.StudyProgramID) {
+		return nil, ErrOutsideAccessScope
+	}
+
 	status := "ACTIVE"
 	if payload.Status != nil {
 		status = *payload.Status
@@ -49,7 +53,14 @@ func (u *subjectUseCase) Create(payload *dto.StoreSubjectDTO) (*domain.Subject,
 	return u.subjectRepo.Create(subject)
 }
 
-func (u *subjectUseCase) Update(id string, payload *dto.UpdateSubjectDTO) (*domain.Subject, error) {
+func (u *subjectUseCase) Update(id string, payload *dto.UpdateSubjectDTO, scope *dto.AccessScope) (*domain.Subject, error) {
+	if err := u.checkSubjectScope(id, scope); err != nil {
+		return nil, err
+	}
+	if !scope.AllowsStudyProgram(payload.StudyProgramID) {
+		return nil, ErrOutsideAccessScope
+	}
+
 	subject := &domain.Subject{
 		StudyProgramID: payload.StudyProgramID,
 		Code:           payload.Code,
@@ -59,12 +70,51 @@ func (u *subjectUseCase) Update(id string, payload *dto.UpdateSubjectDTO) (*doma
 	return u.subjectRepo.Update(id, subject)
 }
 
-func (u *subjectUseCase) Delete(id string) error { return u.subjectRepo.Delete(id) }
+func (u *subjectUseCase) Delete(id string, scope *dto.AccessScope) error {
+	if err := u.checkSubjectScope(id, scope); err != nil {
+		return err
+	}
+	return u.subjectRepo.Delete(id)
+}
 
-func (u *subjectUseCase) GetLectureOnSubject(studyProgramID, semesterID string) (*[]domain.SubjectSemester, error) {
+func (u *subjectUseCase) GetLectureOnSubject(studyProgramID, semesterID string, scope *dto.AccessScope) (*[]domain.SubjectSemester, error) {
+	if !scope.AllowsStudyProgram(studyProgramID) {
+		return nil, ErrOutsideAccessScope
+	}
 	return u.subjectSemesterRepo.GetLectureOnSubject(studyProgramID, semesterID)
 }
 
-func (u *subjectUseCase) StoreLectureOnSubject(data []dto.LectureMappingDTO) error {
+func (u *subjectUseCase) StoreLectureOnSubject(data []dto.LectureMappingDTO, scope *dto.AccessScope) error {
+	if scope != nil {
+		ids := make([]string, 0, len(data))
+		for _, item := range data {
+			ids = append(ids, item.SubjectSemesterID)
+		}
+		studyProgramIDs, err := u.subjectSemesterRepo.StudyProgramIDs(ids)
+		if err != nil {
+			return err
+		}
+		for _, id := range studyProgramIDs {
+			if !scope.AllowsStudyProgram(id) {
+				return ErrOutsideAccessScope
+			}
+		}
+	}
 	return u.subjectSemesterRepo.StoreLectureOnSubject(data)
 }
+
+// checkSubjectScope memastikan mata kuliah yang sudah ada berada di program
+// studi yang termasuk scope pemanggil.
+func (u *subjectUseCase) checkSubjectScope(id string, scope *dto.AccessScope) error {
+	if scope == nil {
+		return nil
+	}
+	subject, err := u.subjectRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if !scope.AllowsStudyProgram(subject.StudyProgramID) {
+		return ErrOutsideAccessScope
+	}
+	return nil
+}
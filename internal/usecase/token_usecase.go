@@ -0,0 +1,401 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"jti-super-app-go/config"
+	"jti-super-app-go/internal/domain"
+	"jti-super-app-go/internal/dto"
+	"jti-super-app-go/internal/service"
+	"jti-super-app-go/pkg/constants"
+	"jti-super-app-go/pkg/helper"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token has already been used, please log in again")
+)
+
+type TokenUseCase interface {
+	IssueForUser(user *domain.User, clientID, scope string) (*dto.TokenPairDTO, error)
+	// IssueImpersonation menerbitkan access token tanpa refresh token untuk
+	// actorID yang bertindak sebagai user. Session ID-nya adalah familyID.
+	IssueImpersonation(user *domain.User, actorID, familyID string, ttl time.Duration) (*dto.TokenPairDTO, error)
+	Refresh(refreshToken, clientID string) (*dto.TokenPairDTO, error)
+	RevokeFamily(familyID string) error
+	// IsSessionRevoked memeriksa apakah family token (claim sid) sudah dicabut.
+	IsSessionRevoked(familyID string) (bool, error)
+	// IsFirstPartySession bernilai true bila family token diterbitkan lewat login
+	// langsung ke aplikasi ini, bukan lewat OAuth client.
+	IsFirstPartySession(familyID string) bool
+	RevokeAccessToken(tokenString string) error
+	Introspect(token, tokenTypeHint string) *dto.OauthIntrospectionResponseDTO
+	Revoke(token, tokenTypeHint, clientID string) error
+}
+
+type tokenUseCase struct {
+	refreshRepo     domain.RefreshTokenRepository
+	userRepo        domain.UserRepository
+	twoFactorRepo   domain.TwoFactorRepository
+	passkeyRepo     domain.WebAuthnCredentialRepository
+	jwtService      service.JWTService
+	authorizationUC AuthorizationUseCase
+}
+
+func NewTokenUseCase(refreshRepo domain.RefreshTokenRepository, userRepo domain.UserRepository, twoFactorRepo domain.TwoFactorRepository, passkeyRepo domain.WebAuthnCredentialRepository, jwtService service.JWTService, authorizationUC AuthorizationUseCase) TokenUseCase {
+	return &tokenUseCase{
+		refreshRepo:     refreshRepo,
+		userRepo:        userRepo,
+		twoFactorRepo:   twoFactorRepo,
+		passkeyRepo:     passkeyRepo,
+		jwtService:      jwtService,
+		authorizationUC: authorizationUC,
+	}
+}
+
+func (uc *tokenUseCase) IssueForUser(user *domain.User, clientID, scope string) (*dto.TokenPairDTO, error) {
+	now := time.Now()
+	family := &domain.RefreshTokenFamily{
+		ID:        uuid.NewString(),
+		UserID:    user.ID,
+		ClientID:  clientID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(time.Duration(config.AppConfig.RefreshTokenHours) * time.Hour),
+	}
+
+	if err := uc.refreshRepo.CreateFamily(family); err != nil {
+		return nil, err
+	}
+
+	return uc.issue(user, family, scope)
+}
+
+func (uc *tokenUseCase) IssueImpersonation(user *domain.User, actorID, familyID string, ttl time.Duration) (*dto.TokenPairDTO, error) {
+	now := time.Now()
+	family := &domain.RefreshTokenFamily{
+		ID:        familyID,
+		UserID:    user.ID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+	if err := uc.refreshRepo.CreateFamily(family); err != nil {
+		return nil, err
+	}
+
+	// Restriction sengaja tidak dipasang: kewajiban seperti ganti password adalah
+	// milik user, bukan admin yang sedang melihat akunnya
+	accessToken, err := uc.jwtService.GenerateImpersonationToken(user.ID, actorID, family.ID, uc.permissionVersion(user.ID), ttl)
+	if err != nil {
+		return nil, errors.New("could not generate token")
+	}
+
+	if err := uc.refreshRepo.SetLastAccessToken(family.ID, accessToken); err != nil {
+		return nil, err
+	}
+
+	return &dto.TokenPairDTO{
+		AccessToken: accessToken,
+		ExpiresIn:   int(ttl.Seconds()),
+		SessionID:   family.ID,
+	}, nil
+}
+
+func (uc *tokenUseCase) Refresh(refreshToken, clientID string) (*dto.TokenPairDTO, error) {
+	tokenHash := hashToken(refreshToken)
+	// Client diperiksa sebelum token dipakai agar request dari client lain
+	// tidak menghanguskan token milik client yang sah
+	stored, _, err := uc.refreshRepo.Find(tokenHash)
+	if err != nil || stored.ClientID != clientID {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	token, firstUse, err := uc.refreshRepo.Consume(tokenHash)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if !firstUse {
+		// Token lama dipakai ulang: kemungkinan bocor, cabut seluruh family
+		log.Printf("Refresh token reuse detected for family %s (user %s)", token.FamilyID, token.UserID)
+		if err := uc.RevokeFamily(token.FamilyID); err != nil {
+			return nil, fmt.Errorf("failed to revoke token family after reuse: %w", err)
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	family, err := uc.refreshRepo.FindFamily(token.FamilyID)
+	if err != nil || family.Revoked || time.Now().After(family.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if family.ClientID != clientID {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := uc.userRepo.FindByID(family.UserID)
+	if err != nil || user.Status != constants.StatusActive {
+		if err := uc.RevokeFamily(family.ID); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	return uc.issue(user, family, token.Scope)
+}
+
+func (uc *tokenUseCase) RevokeFamily(familyID string) error {
+	// Penanda family menolak semua access token family ini, bukan hanya yang terakhir
+	if family, err := uc.refreshRepo.FindFamily(familyID); err == nil && family.LastAccessToken != "" {
+		_ = uc.RevokeAccessToken(family.LastAccessToken)
+	}
+
+	return uc.refreshRepo.RevokeFamily(familyID, uc.maxAccessTokenTTL())
+}
+
+func (uc *tokenUseCase) IsSessionRevoked(familyID string) (bool, error) {
+	return uc.refreshRepo.IsFamilyRevoked(familyID)
+}
+
+func (uc *tokenUseCase) IsFirstPartySession(familyID string) bool {
+	if familyID == "" {
+		return false
+	}
+	family, err := uc.refreshRepo.FindFamily(familyID)
+	return err == nil && family.ClientID == ""
+}
+
+// maxAccessTokenTTL adalah umur terpanjang access token yang bisa dimiliki
+// sebuah family, termasuk token impersonation.
+func (uc *tokenUseCase) maxAccessTokenTTL() time.Duration {
+	return max(uc.jwtService.AccessTokenTTL(), time.Duration(config.AppConfig.ImpersonationMinutes)*time.Minute)
+}
+
+func (uc *tokenUseCase) RevokeAccessToken(tokenString string) error {
+	claims, err := uc.jwtService.ValidateToken(tokenString)
+	if err != nil {
+		return nil
+	}
+
+	remaining := time.Until(claims.ExpiresAt.Time)
+	if remaining <= 0 {
+		return nil
+	}
+
+	err = config.Rdb.Set(context.Background(), tokenString, "blacklisted", remaining).Err()
+	if err != nil {
+		return errors.New("failed to blacklist token")
+	}
+
+	return nil
+}
+
+// Introspect menjelaskan status token sesuai RFC 7662. Token apa pun yang tidak
+// dikenali, kedaluwarsa, atau sudah dicabut dilaporkan sebagai tidak aktif.
+func (uc *tokenUseCase) Introspect(token, tokenTypeHint string) *dto.OauthIntrospectionResponseDTO {
+	if tokenTypeHint == "refresh_token" {
+		if res := uc.introspectRefreshToken(token); res.Active {
+			return res
+		}
+		return uc.introspectAccessToken(token)
+	}
+
+	if res := uc.introspectAccessToken(token); res.Active {
+		return res
+	}
+	return uc.introspectRefreshToken(token)
+}
+
+func (uc *tokenUseCase) introspectAccessToken(tokenString string) *dto.OauthIntrospectionResponseDTO {
+	inactive := &dto.OauthIntrospectionResponseDTO{Active: false}
+
+	val, err := config.Rdb.Get(context.Background(), tokenString).Result()
+	if err == nil && val == "blacklisted" {
+		return inactive
+	}
+
+	claims, err := uc.jwtService.ValidateToken(tokenString)
+	if err != nil {
+		return inactive
+	}
+
+	clientID := claims.ClientID
+	if claims.SessionID != "" {
+		family, err := uc.refreshRepo.FindFamily(claims.SessionID)
+		if err != nil || family.Revoked {
+			return inactive
+		}
+		clientID = family.ClientID
+	}
+
+	return &dto.OauthIntrospectionResponseDTO{
+		Active:    true,
+		Scope:     claims.Scope,
+		ClientID:  clientID,
+		Sub:       claims.Subject,
+		TokenType: "access_token",
+		Exp:       claims.ExpiresAt.Unix(),
+		Iat:       claims.IssuedAt.Unix(),
+		Iss:       claims.Issuer,
+		Jti:       claims.ID,
+	}
+}
+
+func (uc *tokenUseCase) introspectRefreshToken(refreshToken string) *dto.OauthIntrospectionResponseDTO {
+	inactive := &dto.OauthIntrospectionResponseDTO{Active: false}
+
+	token, used, err := uc.refreshRepo.Find(hashToken(refreshToken))
+	if err != nil || used || time.Now().After(token.ExpiresAt) {
+		return inactive
+	}
+
+	family, err := uc.refreshRepo.FindFamily(token.FamilyID)
+	if err != nil || family.Revoked {
+		return inactive
+	}
+
+	return &dto.OauthIntrospectionResponseDTO{
+		Active:    true,
+		Scope:     token.Scope,
+		ClientID:  token.ClientID,
+		Sub:       token.UserID,
+		TokenType: "refresh_token",
+		Exp:       token.ExpiresAt.Unix(),
+		Iat:       family.CreatedAt.Unix(),
+		Iss:       config.AppConfig.OIDC.Issuer,
+	}
+}
+
+// Revoke mencabut token milik clientID sesuai RFC 7009. Token yang tidak dikenal
+// atau milik client lain diabaikan tanpa error agar tidak membocorkan informasi.
+func (uc *tokenUseCase) Revoke(token, tokenTypeHint, clientID string) error {
+	if tokenTypeHint != "access_token" {
+		if revoked, err := uc.revokeRefreshToken(token, clientID); revoked || err != nil {
+			return err
+		}
+	}
+
+	claims, err := uc.jwtService.ValidateToken(token)
+	if err != nil {
+		if tokenTypeHint == "access_token" {
+			_, err = uc.revokeRefreshToken(token, clientID)
+			return err
+		}
+		return nil
+	}
+
+	owner := claims.ClientID
+	if claims.SessionID != "" {
+		family, err := uc.refreshRepo.FindFamily(claims.SessionID)
+		if err != nil {
+			return nil
+		}
+		owner = family.ClientID
+	}
+
+	if owner != clientID {
+		return nil
+	}
+
+	return uc.RevokeAccessToken(token)
+}
+
+func (uc *tokenUseCase) revokeRefreshToken(refreshToken, clientID string) (bool, error) {
+	token, _, err := uc.refreshRepo.Find(hashToken(refreshToken))
+	if err != nil || token.ClientID != clientID {
+		return false, nil
+	}
+
+	// Mencabut refresh token ikut mencabut seluruh family beserta access token terakhirnya
+	return true, uc.RevokeFamily(token.FamilyID)
+}
+
+func (uc *tokenUseCase) issue(user *domain.User, family *domain.RefreshTokenFamily, scope string) (*dto.TokenPairDTO, error) {
+	restrictions, err := uc.restrictionsFor(user)
+	if err != nil {
+		return nil, err
+	}
+
+	// Token untuk OAuth client tidak pernah membawa permission user; scope
+	// `roles` hanya menambah nama role di userinfo dan ID token. Token yang
+	// dibatasi juga tidak membawa permission sampai user menyelesaikan kewajibannya
+	var permissionVersion *int64
+	if family.ClientID == "" && len(restrictions) == 0 {
+		permissionVersion = uc.permissionVersion(user.ID)
+	}
+
+	accessToken, err := uc.jwtService.GenerateToken(user.ID, family.ClientID, family.ID, scope, permissionVersion, restrictions)
+	if err != nil {
+		return nil, errors.New("could not generate token")
+	}
+
+	refreshToken := helper.GenCode()
+	err = uc.refreshRepo.Store(hashToken(refreshToken), &domain.RefreshToken{
+		FamilyID:  family.ID,
+		UserID:    user.ID,
+		ClientID:  family.ClientID,
+		Scope:     scope,
+		ExpiresAt: family.ExpiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.refreshRepo.SetLastAccessToken(family.ID, accessToken); err != nil {
+		return nil, err
+	}
+
+	return &dto.TokenPairDTO{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(uc.jwtService.AccessTokenTTL().Seconds()),
+		Restrictions: restrictions,
+		SessionID:    family.ID,
+	}, nil
+}
+
+// permissionVersion menandai token agar permission-nya diselesaikan live.
+// Bila Redis tidak tersedia versi 0 tetap dipakai; versi hanya informatif.
+func (uc *tokenUseCase) permissionVersion(userID string) *int64 {
+	version, _ := uc.authorizationUC.Version(userID)
+	return &version
+}
+
+// restrictionsFor menentukan tindakan wajib yang belum diselesaikan user.
+func (uc *tokenUseCase) restrictionsFor(user *domain.User) ([]string, error) {
+	var restrictions []string
+
+	if user.RequiresTwoFactor() {
+		twoFactor, err := uc.twoFactorRepo.FindByUserID(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !twoFactor.Enabled() {
+			// Passkey yang terdaftar juga dihitung sebagai faktor kedua
+			passkeys, err := uc.passkeyRepo.FindByUserID(user.ID)
+			if err != nil {
+				return nil, err
+			}
+			if len(passkeys) == 0 {
+				restrictions = append(restrictions, constants.RestrictionTwoFactorEnrollment)
+			}
+		}
+	}
+
+	if !user.IsChangePassword {
+		restrictions = append(restrictions, constants.RestrictionPasswordChange)
+	}
+
+	return restrictions, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"errors"
+	"jti-super-app-go/config"
+	"jti-super-app-go/internal/domain"
+	"jti-super-app-go/internal/repository"
+	"jti-super-app-go/internal/service"
+	"jti-super-app-go/pkg/constants"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// useTestRedis mengarahkan config.Rdb ke Redis in-memory selama test berjalan.
+func useTestRedis(t *testing.T) *miniredis.Miniredis {
+	t.Helper()
+	server := miniredis.RunT(t)
+
+	previousRdb, previousConfig := config.Rdb, config.AppConfig
+	config.Rdb = redis.NewClient(&redis.Options{Addr: server.Addr()})
+	config.AppConfig = &config.Config{RefreshTokenHours: 24, ImpersonationMinutes: 30}
+	t.Cleanup(func() {
+		_ = config.Rdb.Close()
+		config.Rdb, config.AppConfig = previousRdb, previousConfig
+	})
+	return server
+}
+
+type stubJWTService struct {
+	service.JWTService
+}
+
+func (stubJWTService) GenerateToken(userID, audience, sessionID, scope string, permissionVersion *int64, restrictions []string) (string, error) {
+	return "access-" + uuid.NewString(), nil
+}
+
+// ValidateToken menolak semua token sehingga pencabutan access token terakhir
+// tidak menyentuh blacklist; yang diuji di sini adalah status family.
+func (stubJWTService) ValidateToken(tokenString string) (*service.JWTClaims, error) {
+	return nil, errors.New("not a jwt")
+}
+
+func (stubJWTService) AccessTokenTTL() time.Duration {
+	return 15 * time.Minute
+}
+
+type stubUserRepo struct {
+	domain.UserRepository
+	user *domain.User
+}
+
+func (r *stubUserRepo) FindByID(id string) (*domain.User, error) {
+	if r.user == nil || r.user.ID != id {
+		return nil, errors.New("record not found")
+	}
+	return r.user, nil
+}
+
+type stubAuthorizationUseCase struct {
+	AuthorizationUseCase
+}
+
+func (stubAuthorizationUseCase) Version(userID string) (int64, error) {
+	return 1, nil
+}
+
+func activeTestUser() *domain.User {
+	return &domain.User{ID: uuid.NewString(), Status: constants.StatusActive, IsChangePassword: true}
+}
+
+func newTestTokenUseCase(user *domain.User) TokenUseCase {
+	return NewTokenUseCase(repository.NewRefreshTokenRepository(config.Rdb), &stubUserRepo{user: user}, nil, nil, stubJWTService{}, stubAuthorizationUseCase{})
+}
+
+func TestRefreshRotation(t *testing.T) {
+	tests := []struct {
+		name     string
+		clientID string
+		// replay memakai ulang refresh token pertama setelah rotasi
+		replay          bool
+		refreshClientID string
+		wantErr         error
+		wantRevoked     bool
+	}{
+		{name: "rotates a first-party token", replay: false},
+		{name: "rotates a client token", clientID: "client-a", refreshClientID: "client-a"},
+		{name: "reuse revokes the family", replay: true, wantErr: ErrRefreshTokenReused, wantRevoked: true},
+		{name: "reuse of a client token revokes the family", clientID: "client-a", refreshClientID: "client-a", replay: true, wantErr: ErrRefreshTokenReused, wantRevoked: true},
+		{name: "another client cannot use the token", clientID: "client-a", refreshClientID: "client-b", wantErr: ErrInvalidRefreshToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestRedis(t)
+			user := activeTestUser()
+			uc := newTestTokenUseCase(user)
+
+			issued, err := uc.IssueForUser(user, tt.clientID, "")
+			if err != nil {
+				t.Fatalf("IssueForUser() error = %v", err)
+			}
+
+			rotated, err := uc.Refresh(issued.RefreshToken, tt.refreshClientID)
+			if tt.replay {
+				if err != nil {
+					t.Fatalf("first Refresh() error = %v", err)
+				}
+				_, err = uc.Refresh(issued.RefreshToken, tt.refreshClientID)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Refresh() error = %v, want %v", err, tt.wantErr)
+			}
+
+			revoked, err := uc.IsSessionRevoked(issued.SessionID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if revoked != tt.wantRevoked {
+				t.Errorf("IsSessionRevoked() = %v, want %v", revoked, tt.wantRevoked)
+			}
+
+			switch {
+			case tt.wantRevoked:
+				// Token hasil rotasi ikut mati bersama family-nya
+				if _, err := uc.Refresh(rotated.RefreshToken, tt.refreshClientID); !errors.Is(err, ErrInvalidRefreshToken) {
+					t.Errorf("Refresh() with rotated token error = %v, want ErrInvalidRefreshToken", err)
+				}
+			case tt.wantErr == nil:
+				if rotated.SessionID != issued.SessionID || rotated.RefreshToken == issued.RefreshToken {
+					t.Errorf("Refresh() did not rotate within the same family")
+				}
+			default:
+				// Percobaan client lain tidak menghanguskan token milik client yang sah
+				if _, err := uc.Refresh(issued.RefreshToken, tt.clientID); err != nil {
+					t.Errorf("Refresh() by the owning client error = %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestRefreshRejectsInactiveUser(t *testing.T) {
+	useTestRedis(t)
+	user := activeTestUser()
+	uc := newTestTokenUseCase(user)
+
+	issued, err := uc.IssueForUser(user, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user.Status = "INACTIVE"
+
+	if _, err := uc.Refresh(issued.RefreshToken, ""); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("Refresh() error = %v, want ErrInvalidRefreshToken", err)
+	}
+	if revoked, _ := uc.IsSessionRevoked(issued.SessionID); !revoked {
+		t.Error("family of an inactive user was not revoked")
+	}
+}
@@ -0,0 +1,295 @@
+package usecase
+
+import (
+	"errors"
+	"jti-super-app-go/internal/domain"
+	"jti-super-app-go/internal/dto"
+	"jti-super-app-go/pkg/constants"
+	"jti-super-app-go/pkg/helper"
+	"strings"
+	"time"
+)
+
+const (
+	// TwoFactorChallengeTTL adalah batas waktu user memasukkan kode setelah password benar.
+	TwoFactorChallengeTTL = 5 * time.Minute
+	// TwoFactorMaxAttempts membatasi tebakan kode per challenge.
+	TwoFactorMaxAttempts = 5
+	RecoveryCodeCount    = 8
+)
+
+var (
+	ErrTwoFactorAlreadyEnabled   = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled       = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorSetupNotStarted  = errors.New("two-factor authentication setup has not been started")
+	ErrTwoFactorRequiredByRole   = errors.New("two-factor authentication is required for your role and cannot be disabled")
+	ErrInvalidTwoFactorCode      = errors.New("invalid two-factor authentication code")
+	ErrInvalidTwoFactorChallenge = errors.New("two-factor challenge is invalid or has expired")
+)
+
+type TwoFactorUseCase interface {
+	Status(userID string) (*dto.TwoFactorStatusResource, error)
+	Setup(userID string) (*dto.TwoFactorSetupResource, error)
+	Confirm(userID, code string) ([]string, error)
+	Disable(userID, code string) error
+	RegenerateRecoveryCodes(userID, code string) ([]string, error)
+	IsEnabled(userID string) (bool, error)
+	// Challenge memulai langkah kedua login dan mengembalikan token challenge.
+	Challenge(userID string) (string, error)
+	// VerifyChallenge memeriksa kode TOTP atau recovery code dan mengembalikan user ID.
+	VerifyChallenge(token, code string) (string, error)
+	// ChallengeUser membaca pemilik challenge tanpa menyelesaikannya, dipakai
+	// saat langkah kedua dijawab dengan passkey.
+	ChallengeUser(token string) (string, error)
+	// ResolveChallenge menandai challenge selesai setelah faktor lain terverifikasi.
+	ResolveChallenge(token string) (string, error)
+}
+
+type twoFactorUseCase struct {
+	repo          domain.TwoFactorRepository
+	challengeRepo domain.TwoFactorChallengeRepository
+	userRepo      domain.UserRepository
+}
+
+func NewTwoFactorUseCase(repo domain.TwoFactorRepository, challengeRepo domain.TwoFactorChallengeRepository, userRepo domain.UserRepository) TwoFactorUseCase {
+	return &twoFactorUseCase{repo: repo, challengeRepo: challengeRepo, userRepo: userRepo}
+}
+
+func (uc *twoFactorUseCase) Status(userID string) (*dto.TwoFactorStatusResource, error) {
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	twoFactor, err := uc.repo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &dto.TwoFactorStatusResource{Required: user.RequiresTwoFactor()}
+	if twoFactor.Enabled() {
+		status.Enabled = true
+		status.RecoveryCodesRemaining = len(twoFactor.RecoveryCodes)
+	}
+	return status, nil
+}
+
+// Setup membuat secret baru yang belum aktif. Memanggil Setup lagi sebelum
+// Confirm akan mengganti secret sebelumnya.
+func (uc *twoFactorUseCase) Setup(userID string) (*dto.TwoFactorSetupResource, error) {
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := uc.repo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing.Enabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret := helper.GenerateTOTPSecret()
+	err = uc.repo.Save(&domain.UserTwoFactor{
+		UserID:    userID,
+		Secret:    secret,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.TwoFactorSetupResource{
+		Secret:          secret,
+		ProvisioningURI: helper.TOTPProvisioningURI(constants.TOTP_ISSUER, user.Email, secret),
+	}, nil
+}
+
+// Confirm mengaktifkan 2FA setelah user membuktikan authenticator-nya bekerja,
+// lalu mengembalikan recovery code dalam bentuk plain yang hanya ditampilkan sekali.
+func (uc *twoFactorUseCase) Confirm(userID, code string) ([]string, error) {
+	twoFactor, err := uc.repo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor == nil {
+		return nil, ErrTwoFactorSetupNotStarted
+	}
+	if twoFactor.Enabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	if !uc.verifyTOTP(twoFactor, code) {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes := newRecoveryCodes()
+	now := time.Now()
+	twoFactor.RecoveryCodes = hashes
+	twoFactor.ConfirmedAt = &now
+	twoFactor.UpdatedAt = now
+	if err := uc.repo.Save(twoFactor); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (uc *twoFactorUseCase) Disable(userID, code string) error {
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.RequiresTwoFactor() {
+		return ErrTwoFactorRequiredByRole
+	}
+
+	twoFactor, err := uc.enabledTwoFactor(userID)
+	if err != nil {
+		return err
+	}
+
+	ok, err := uc.verify(twoFactor, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+
+	return uc.repo.Delete(userID)
+}
+
+func (uc *twoFactorUseCase) RegenerateRecoveryCodes(userID, code string) ([]string, error) {
+	twoFactor, err := uc.enabledTwoFactor(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !uc.verifyTOTP(twoFactor, code) {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes := newRecoveryCodes()
+	twoFactor.RecoveryCodes = hashes
+	twoFactor.UpdatedAt = time.Now()
+	if err := uc.repo.Save(twoFactor); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (uc *twoFactorUseCase) IsEnabled(userID string) (bool, error) {
+	twoFactor, err := uc.repo.FindByUserID(userID)
+	if err != nil {
+		return false, err
+	}
+	return twoFactor.Enabled(), nil
+}
+
+func (uc *twoFactorUseCase) Challenge(userID string) (string, error) {
+	challenge := &domain.TwoFactorChallenge{
+		Token:  helper.GenCode(),
+		UserID: userID,
+	}
+	if err := uc.challengeRepo.Create(challenge, TwoFactorChallengeTTL); err != nil {
+		return "", err
+	}
+	return challenge.Token, nil
+}
+
+func (uc *twoFactorUseCase) VerifyChallenge(token, code string) (string, error) {
+	challenge, err := uc.challengeRepo.Find(token)
+	if err != nil {
+		return "", ErrInvalidTwoFactorChallenge
+	}
+
+	twoFactor, err := uc.repo.FindByUserID(challenge.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	// User yang hanya memakai passkey tidak punya kode untuk dicocokkan
+	ok := false
+	if twoFactor.Enabled() {
+		ok, err = uc.verify(twoFactor, code)
+		if err != nil {
+			return "", err
+		}
+	}
+	if !ok {
+		// Challenge dibuang setelah terlalu banyak percobaan sehingga user harus login ulang
+		attempts, err := uc.challengeRepo.IncrementAttempts(token)
+		if err != nil || attempts >= TwoFactorMaxAttempts {
+			_ = uc.challengeRepo.Delete(token)
+		}
+		return "", ErrInvalidTwoFactorCode
+	}
+
+	_ = uc.challengeRepo.Delete(token)
+	return challenge.UserID, nil
+}
+
+func (uc *twoFactorUseCase) ChallengeUser(token string) (string, error) {
+	challenge, err := uc.challengeRepo.Find(token)
+	if err != nil {
+		return "", ErrInvalidTwoFactorChallenge
+	}
+	return challenge.UserID, nil
+}
+
+func (uc *twoFactorUseCase) ResolveChallenge(token string) (string, error) {
+	challenge, err := uc.challengeRepo.Find(token)
+	if err != nil {
+		return "", ErrInvalidTwoFactorChallenge
+	}
+
+	_ = uc.challengeRepo.Delete(token)
+	return challenge.UserID, nil
+}
+
+func (uc *twoFactorUseCase) enabledTwoFactor(userID string) (*domain.UserTwoFactor, error) {
+	twoFactor, err := uc.repo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !twoFactor.Enabled() {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	return twoFactor, nil
+}
+
+// verify menerima kode TOTP atau salah satu recovery code.
+func (uc *twoFactorUseCase) verify(twoFactor *domain.UserTwoFactor, code string) (bool, error) {
+	if uc.verifyTOTP(twoFactor, code) {
+		return true, nil
+	}
+
+	code = strings.ToLower(strings.TrimSpace(code))
+	if !strings.Contains(code, "-") {
+		return false, nil
+	}
+	return uc.repo.ConsumeRecoveryCode(twoFactor.UserID, hashToken(code))
+}
+
+func (uc *twoFactorUseCase) verifyTOTP(twoFactor *domain.UserTwoFactor, code string) bool {
+	step, ok := helper.ValidateTOTP(twoFactor.Secret, code, time.Now())
+	if !ok {
+		return false
+	}
+
+	// Kode yang sama tidak boleh dipakai dua kali selama masih berada di jendela validasi
+	window := time.Duration(2*helper.TOTPSkew+1) * helper.TOTPPeriod * time.Second
+	fresh, err := uc.challengeRepo.MarkCodeUsed(twoFactor.UserID, step, window)
+	return err == nil && fresh
+}
+
+func newRecoveryCodes() ([]string, []string) {
+	codes := helper.GenerateRecoveryCodes(RecoveryCodeCount)
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = hashToken(code)
+	}
+	return codes, hashes
+}
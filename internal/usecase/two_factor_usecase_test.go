@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"fmt"
+	"jti-super-app-go/internal/domain"
+	"jti-super-app-go/pkg/helper"
+	"testing"
+	"time"
+)
+
+// memoryChallengeRepo hanya mengimplementasikan MarkCodeUsed; method lain
+// tidak dipanggil oleh verifyTOTP.
+type memoryChallengeRepo struct {
+	domain.TwoFactorChallengeRepository
+	used map[string]bool
+}
+
+func (r *memoryChallengeRepo) MarkCodeUsed(userID string, step int64, ttl time.Duration) (bool, error) {
+	key := fmt.Sprintf("%s:%d", userID, step)
+	if r.used[key] {
+		return false, nil
+	}
+	r.used[key] = true
+	return true, nil
+}
+
+func TestVerifyTOTPRejectsReplayedCode(t *testing.T) {
+	uc := &twoFactorUseCase{challengeRepo: &memoryChallengeRepo{used: map[string]bool{}}}
+	twoFactor := &domain.UserTwoFactor{UserID: "user-1", Secret: helper.GenerateTOTPSecret()}
+	other := &domain.UserTwoFactor{UserID: "user-2", Secret: twoFactor.Secret}
+
+	step := helper.TOTPStep(time.Now())
+	code, err := helper.TOTPCode(twoFactor.Secret, step)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous, err := helper.TOTPCode(twoFactor.Secret, step-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !uc.verifyTOTP(twoFactor, code) {
+		t.Fatal("verifyTOTP() rejected a fresh code")
+	}
+	if uc.verifyTOTP(twoFactor, code) {
+		t.Fatal("verifyTOTP() accepted the same code twice")
+	}
+	// Kode dari time step lain di dalam jendela masih bisa dipakai sekali
+	if previous != code && !uc.verifyTOTP(twoFactor, previous) {
+		t.Fatal("verifyTOTP() rejected an unused code from the previous step")
+	}
+	// Penanda kode terpisah per user
+	if !uc.verifyTOTP(other, code) {
+		t.Fatal("verifyTOTP() rejected a code already used by another user")
+	}
+}
@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"errors"
+	"jti-super-app-go/config"
+	"jti-super-app-go/internal/domain"
+	"jti-super-app-go/internal/dto"
+	"jti-super-app-go/pkg/helper"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrUserSessionNotFound = errors.New("session not found")
+
+type UserSessionUseCase interface {
+	Start(userID, sessionType, familyID string, meta dto.SessionMetaDTO) (*domain.UserSession, error)
+	List(userID, currentSessionID string) ([]dto.UserSessionResource, error)
+	Touch(sessionID string)
+	Revoke(userID, sessionID string) ([]string, error)
+	RevokeAll(userID string) error
+	// RevokeOthers mengakhiri semua sesi user kecuali sesi yang sedang dipakai.
+	RevokeOthers(userID, currentSessionID string) error
+}
+
+type userSessionUseCase struct {
+	repo         domain.UserSessionRepository
+	tokenUC      TokenUseCase
+	oauthUsecase OauthUsecase
+}
+
+func NewUserSessionUseCase(repo domain.UserSessionRepository, tokenUC TokenUseCase, oauthUsecase OauthUsecase) UserSessionUseCase {
+	return &userSessionUseCase{
+		repo:         repo,
+		tokenUC:      tokenUC,
+		oauthUsecase: oauthUsecase,
+	}
+}
+
+// Start mencatat perangkat baru. Sesi API memakai ID token family agar bisa
+// dicocokkan dengan claim `sid` pada access token.
+func (uc *userSessionUseCase) Start(userID, sessionType, familyID string, meta dto.SessionMetaDTO) (*domain.UserSession, error) {
+	now := time.Now()
+	session := &domain.UserSession{
+		ID:         uuid.NewString(),
+		UserID:     userID,
+		Type:       sessionType,
+		FamilyID:   familyID,
+		Device:     helper.DeviceFromUserAgent(meta.UserAgent),
+		IPAddress:  meta.IPAddress,
+		UserAgent:  meta.UserAgent,
+		CreatedAt:  now,
+		LastSeenAt: now,
+	}
+
+	if sessionType == domain.UserSessionTypeAPI {
+		session.ID = familyID
+		session.ExpiresAt = now.Add(time.Duration(config.AppConfig.RefreshTokenHours) * time.Hour)
+	} else {
+		session.ExpiresAt = now.Add(time.Duration(config.AppConfig.JWTExpirationHours) * time.Hour)
+	}
+
+	if err := uc.repo.Create(session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (uc *userSessionUseCase) List(userID, currentSessionID string) ([]dto.UserSessionResource, error) {
+	sessions, err := uc.repo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]dto.UserSessionResource, 0, len(sessions))
+	for _, session := range sessions {
+		resources = append(resources, dto.UserSessionResource{
+			ID:         session.ID,
+			Type:       session.Type,
+			Device:     session.Device,
+			IPAddress:  session.IPAddress,
+			UserAgent:  session.UserAgent,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			Current:    session.ID == currentSessionID,
+		})
+	}
+	return resources, nil
+}
+
+func (uc *userSessionUseCase) Touch(sessionID string) {
+	if sessionID == "" {
+		return
+	}
+	_ = uc.repo.Touch(sessionID, time.Now())
+}
+
+// Revoke mengakhiri satu sesi milik user. Untuk sesi web, nilai kembaliannya
+// berisi URI front-channel logout client yang perlu dimuat browser.
+func (uc *userSessionUseCase) Revoke(userID, sessionID string) ([]string, error) {
+	session, err := uc.repo.FindByID(sessionID)
+	if err != nil || session.UserID != userID {
+		return nil, ErrUserSessionNotFound
+	}
+
+	return uc.end(session)
+}
+
+// RevokeAll mengakhiri semua sesi user, dipakai admin untuk akun yang disusupi
+// dan setelah password di-reset lewat email.
+func (uc *userSessionUseCase) RevokeAll(userID string) error {
+	sessions, err := uc.repo.FindByUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	for i := range sessions {
+		if _, err := uc.end(&sessions[i]); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("All %d sessions of user %s have been revoked", len(sessions), userID)
+	return nil
+}
+
+func (uc *userSessionUseCase) RevokeOthers(userID, currentSessionID string) error {
+	sessions, err := uc.repo.FindByUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	for i := range sessions {
+		if sessions[i].ID == currentSessionID {
+			continue
+		}
+		if _, err := uc.end(&sessions[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (uc *userSessionUseCase) end(session *domain.UserSession) ([]string, error) {
+	if session.FamilyID != "" {
+		if err := uc.tokenUC.RevokeFamily(session.FamilyID); err != nil {
+			return nil, err
+		}
+	}
+
+	var frontchannelURIs []string
+	if session.Type == domain.UserSessionTypeWeb {
+		uris, err := uc.oauthUsecase.Logout(session.ID)
+		if err != nil {
+			return nil, err
+		}
+		frontchannelURIs = uris
+	}
+
+	if err := uc.repo.Delete(session); err != nil {
+		return nil, err
+	}
+	return frontchannelURIs, nil
+}
@@ -11,11 +11,12 @@ type UserUseCase interface {
 }
 
 type userUseCase struct {
-	repo domain.UserRepository
+	repo            domain.UserRepository
+	authorizationUC AuthorizationUseCase
 }
 
-func NewUserUseCase(repo domain.UserRepository) UserUseCase {
-	return &userUseCase{repo: repo}
+func NewUserUseCase(repo domain.UserRepository, authorizationUC AuthorizationUseCase) UserUseCase {
+	return &userUseCase{repo: repo, authorizationUC: authorizationUC}
 }
 
 func (u *userUseCase) FindAll(params dto.QueryParams) (*[]domain.User, int64, error) {
@@ -28,5 +29,9 @@ func (u *userUseCase) UpdateRoles(id string, roles dto.UpdateUserRolesRequest) e
 		roleList[i] = domain.Role{ID: roleID}
 	}
 
-	return u.repo.UpdateRoles(id, roleList)
+	if err := u.repo.UpdateRoles(id, roleList); err != nil {
+		return err
+	}
+	u.authorizationUC.InvalidateUsers(id)
+	return nil
 }
@@ -0,0 +1,302 @@
+package usecase
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"jti-super-app-go/internal/domain"
+	"jti-super-app-go/internal/dto"
+	"jti-super-app-go/internal/service"
+	"jti-super-app-go/pkg/constants"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WebAuthnSessionTTL sedikit lebih lama dari timeout ceremony di browser.
+const WebAuthnSessionTTL = 2 * time.Minute
+
+var (
+	ErrPasskeyNotFound        = errors.New("passkey not found")
+	ErrPasskeyAlreadyExists   = errors.New("passkey is already registered")
+	ErrInvalidWebAuthnSession = errors.New("passkey session is invalid or has expired")
+	ErrPasskeyLoginFailed     = errors.New("passkey login failed")
+)
+
+type WebAuthnUseCase interface {
+	List(userID string) ([]dto.WebAuthnCredentialResource, error)
+	Delete(userID, id string) error
+	HasPasskeys(userID string) (bool, error)
+	BeginRegistration(userID string) (*dto.WebAuthnBeginResponseDTO, error)
+	FinishRegistration(userID string, req dto.WebAuthnRegisterFinishRequestDTO) (*dto.WebAuthnCredentialResource, error)
+	// BeginLogin memulai login passwordless. Email opsional; tanpa email browser
+	// menawarkan passkey yang tersimpan di perangkat (discoverable credential).
+	// browserID kosong untuk klien API dan harus sama persis saat Finish.
+	BeginLogin(email, browserID string) (*dto.WebAuthnBeginResponseDTO, error)
+	FinishLogin(sessionID, browserID string, credential dto.WebAuthnCredentialDTO) (*domain.User, error)
+	// BeginSecondFactor dan FinishSecondFactor menjawab challenge 2FA dari login password.
+	BeginSecondFactor(challengeToken, browserID string) (*dto.WebAuthnBeginResponseDTO, error)
+	FinishSecondFactor(challengeToken, sessionID, browserID string, credential dto.WebAuthnCredentialDTO) (string, error)
+}
+
+type webAuthnUseCase struct {
+	credentialRepo  domain.WebAuthnCredentialRepository
+	sessionRepo     domain.WebAuthnSessionRepository
+	userRepo        domain.UserRepository
+	authRepo        domain.AuthRepository
+	twoFactorUC     TwoFactorUseCase
+	webAuthnService service.WebAuthnService
+}
+
+func NewWebAuthnUseCase(credentialRepo domain.WebAuthnCredentialRepository, sessionRepo domain.WebAuthnSessionRepository, userRepo domain.UserRepository, authRepo domain.AuthRepository, twoFactorUC TwoFactorUseCase, webAuthnService service.WebAuthnService) WebAuthnUseCase {
+	return &webAuthnUseCase{
+		credentialRepo:  credentialRepo,
+		sessionRepo:     sessionRepo,
+		userRepo:        userRepo,
+		authRepo:        authRepo,
+		twoFactorUC:     twoFactorUC,
+		webAuthnService: webAuthnService,
+	}
+}
+
+func (uc *webAuthnUseCase) List(userID string) ([]dto.WebAuthnCredentialResource, error) {
+	credentials, err := uc.credentialRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]dto.WebAuthnCredentialResource, 0, len(credentials))
+	for _, c := range credentials {
+		resources = append(resources, toWebAuthnCredentialResource(&c))
+	}
+	return resources, nil
+}
+
+func (uc *webAuthnUseCase) Delete(userID, id string) error {
+	err := uc.credentialRepo.Delete(userID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrPasskeyNotFound
+	}
+	return err
+}
+
+func (uc *webAuthnUseCase) HasPasskeys(userID string) (bool, error) {
+	credentials, err := uc.credentialRepo.FindByUserID(userID)
+	if err != nil {
+		return false, err
+	}
+	return len(credentials) > 0, nil
+}
+
+func (uc *webAuthnUseCase) BeginRegistration(userID string) (*dto.WebAuthnBeginResponseDTO, error) {
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := uc.credentialRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := uc.newSession(domain.WebAuthnCeremonyRegistration, userID, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.WebAuthnBeginResponseDTO{
+		SessionID: session.ID,
+		PublicKey: uc.webAuthnService.CreationOptions(session.Challenge, user, existing),
+	}, nil
+}
+
+func (uc *webAuthnUseCase) FinishRegistration(userID string, req dto.WebAuthnRegisterFinishRequestDTO) (*dto.WebAuthnCredentialResource, error) {
+	session, err := uc.consumeSession(req.SessionID, domain.WebAuthnCeremonyRegistration, "")
+	if err != nil || session.UserID != userID {
+		return nil, ErrInvalidWebAuthnSession
+	}
+
+	attested, err := uc.webAuthnService.VerifyRegistration(session.Challenge, req.Credential)
+	if err != nil {
+		return nil, err
+	}
+
+	credentialID := base64.RawURLEncoding.EncodeToString(attested.CredentialID)
+	if _, err := uc.credentialRepo.FindByCredentialID(credentialID); err == nil {
+		return nil, ErrPasskeyAlreadyExists
+	}
+
+	now := time.Now()
+	credential := &domain.WebAuthnCredential{
+		ID:           uuid.NewString(),
+		UserID:       userID,
+		Name:         req.Name,
+		CredentialID: credentialID,
+		PublicKey:    base64.RawURLEncoding.EncodeToString(attested.PublicKey),
+		Algorithm:    attested.Algorithm,
+		SignCount:    attested.SignCount,
+		Transports:   attested.Transports,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if attested.AAGUID != "" {
+		credential.AAGUID = &attested.AAGUID
+	}
+
+	if err := uc.credentialRepo.Create(credential); err != nil {
+		return nil, err
+	}
+
+	resource := toWebAuthnCredentialResource(credential)
+	return &resource, nil
+}
+
+func (uc *webAuthnUseCase) BeginLogin(email, browserID string) (*dto.WebAuthnBeginResponseDTO, error) {
+	var allow []domain.WebAuthnCredential
+	if email != "" {
+		// Email yang tidak terdaftar tetap mendapat options kosong agar tidak
+		// bisa dipakai untuk menebak akun yang ada
+		if user, err := uc.authRepo.FindByEmail(email); err == nil {
+			allow, _ = uc.credentialRepo.FindByUserID(user.ID)
+		}
+	}
+
+	session, err := uc.newSession(domain.WebAuthnCeremonyLogin, "", "", browserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.WebAuthnBeginResponseDTO{
+		SessionID: session.ID,
+		PublicKey: uc.webAuthnService.RequestOptions(session.Challenge, allow),
+	}, nil
+}
+
+func (uc *webAuthnUseCase) FinishLogin(sessionID, browserID string, credential dto.WebAuthnCredentialDTO) (*domain.User, error) {
+	session, err := uc.consumeSession(sessionID, domain.WebAuthnCeremonyLogin, browserID)
+	if err != nil {
+		return nil, ErrInvalidWebAuthnSession
+	}
+
+	stored, err := uc.verifyAssertion(session, credential)
+	if err != nil {
+		return nil, err
+	}
+
+	// userHandle dari discoverable credential harus menunjuk pemilik passkey
+	if credential.Response.UserHandle != "" {
+		handle, err := base64.RawURLEncoding.DecodeString(credential.Response.UserHandle)
+		if err != nil || string(handle) != stored.UserID {
+			return nil, ErrPasskeyLoginFailed
+		}
+	}
+
+	user, err := uc.userRepo.FindByID(stored.UserID)
+	if err != nil || user.Status != constants.StatusActive {
+		return nil, ErrPasskeyLoginFailed
+	}
+	return user, nil
+}
+
+func (uc *webAuthnUseCase) BeginSecondFactor(challengeToken, browserID string) (*dto.WebAuthnBeginResponseDTO, error) {
+	userID, err := uc.twoFactorUC.ChallengeUser(challengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	allow, err := uc.credentialRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(allow) == 0 {
+		return nil, ErrPasskeyNotFound
+	}
+
+	session, err := uc.newSession(domain.WebAuthnCeremonySecondFactor, userID, challengeToken, browserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.WebAuthnBeginResponseDTO{
+		SessionID: session.ID,
+		PublicKey: uc.webAuthnService.RequestOptions(session.Challenge, allow),
+	}, nil
+}
+
+func (uc *webAuthnUseCase) FinishSecondFactor(challengeToken, sessionID, browserID string, credential dto.WebAuthnCredentialDTO) (string, error) {
+	session, err := uc.consumeSession(sessionID, domain.WebAuthnCeremonySecondFactor, browserID)
+	if err != nil || session.ChallengeToken != challengeToken {
+		return "", ErrInvalidWebAuthnSession
+	}
+
+	if _, err := uc.verifyAssertion(session, credential); err != nil {
+		return "", err
+	}
+
+	return uc.twoFactorUC.ResolveChallenge(challengeToken)
+}
+
+func (uc *webAuthnUseCase) verifyAssertion(session *domain.WebAuthnSession, credential dto.WebAuthnCredentialDTO) (*domain.WebAuthnCredential, error) {
+	stored, err := uc.credentialRepo.FindByCredentialID(strings.TrimRight(credential.RawID, "="))
+	if err != nil {
+		return nil, ErrPasskeyLoginFailed
+	}
+	// Ceremony yang terikat ke user hanya menerima passkey milik user itu; dicek
+	// sebelum sign count ditulis agar passkey user lain tidak ikut berubah
+	if session.UserID != "" && stored.UserID != session.UserID {
+		return nil, ErrPasskeyLoginFailed
+	}
+
+	signCount, err := uc.webAuthnService.VerifyAssertion(session.Challenge, credential, stored)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.credentialRepo.UpdateUsage(stored.ID, signCount, time.Now()); err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+func (uc *webAuthnUseCase) newSession(ceremony, userID, challengeToken, browserID string) (*domain.WebAuthnSession, error) {
+	session := &domain.WebAuthnSession{
+		ID:             uuid.NewString(),
+		Ceremony:       ceremony,
+		Challenge:      uc.webAuthnService.NewChallenge(),
+		UserID:         userID,
+		ChallengeToken: challengeToken,
+		BrowserID:      browserID,
+		CreatedAt:      time.Now(),
+	}
+	if err := uc.sessionRepo.Create(session, WebAuthnSessionTTL); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (uc *webAuthnUseCase) consumeSession(id, ceremony, browserID string) (*domain.WebAuthnSession, error) {
+	session, err := uc.sessionRepo.Consume(id)
+	if err != nil {
+		return nil, err
+	}
+	if session.Ceremony != ceremony || subtle.ConstantTimeCompare([]byte(session.BrowserID), []byte(browserID)) != 1 {
+		return nil, ErrInvalidWebAuthnSession
+	}
+	return session, nil
+}
+
+func toWebAuthnCredentialResource(c *domain.WebAuthnCredential) dto.WebAuthnCredentialResource {
+	transports := c.Transports
+	if transports == nil {
+		transports = []string{}
+	}
+	return dto.WebAuthnCredentialResource{
+		ID:         c.ID,
+		Name:       c.Name,
+		Transports: transports,
+		LastUsedAt: c.LastUsedAt,
+		CreatedAt:  c.CreatedAt,
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"jti-super-app-go/delivery"
+	"os"
 	"time"
 
 	"github.com/getsentry/sentry-go"
@@ -9,5 +10,11 @@ import (
 
 func main() {
 	defer sentry.Flush(2 * time.Second)
+
+	if len(os.Args) > 1 {
+		delivery.RunCommand(os.Args[1:])
+		return
+	}
+
 	delivery.Server().Run()
 }
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS `oauth_signing_keys` (
+    `id` varchar(64) NOT NULL,
+    `algorithm` varchar(10) NOT NULL,
+    `private_key` text NOT NULL,
+    `status` enum('ACTIVE','RETIRING','RETIRED') NOT NULL DEFAULT 'ACTIVE',
+    `retiring_at` timestamp NULL DEFAULT NULL,
+    `created_at` timestamp NULL DEFAULT NULL,
+    `updated_at` timestamp NULL DEFAULT NULL,
+    PRIMARY KEY (`id`),
+    KEY `oauth_signing_keys_status_index` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,2 @@
+ALTER TABLE `m_oauth_client`
+    ADD COLUMN `is_public` tinyint(1) NOT NULL DEFAULT 0 AFTER `redirect`;
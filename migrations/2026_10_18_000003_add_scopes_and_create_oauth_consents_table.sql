@@ -0,0 +1,15 @@
+ALTER TABLE `m_oauth_client`
+    ADD COLUMN `scopes` json NULL AFTER `is_public`;
+
+UPDATE `m_oauth_client` SET `scopes` = JSON_ARRAY('openid', 'profile', 'email', 'roles') WHERE `scopes` IS NULL;
+
+CREATE TABLE IF NOT EXISTS `oauth_consents` (
+    `id` char(36) NOT NULL,
+    `m_user_id` char(36) NOT NULL,
+    `m_oauth_client_id` char(36) NOT NULL,
+    `scopes` json NOT NULL,
+    `created_at` timestamp NULL DEFAULT NULL,
+    `updated_at` timestamp NULL DEFAULT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `oauth_consents_user_client_unique` (`m_user_id`, `m_oauth_client_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
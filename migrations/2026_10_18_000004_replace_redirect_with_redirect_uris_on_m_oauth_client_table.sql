@@ -0,0 +1,8 @@
+ALTER TABLE `m_oauth_client`
+    ADD COLUMN `redirect_uris` json NULL AFTER `secret`;
+
+UPDATE `m_oauth_client` SET `redirect_uris` = JSON_ARRAY(`redirect`) WHERE `redirect_uris` IS NULL;
+
+ALTER TABLE `m_oauth_client`
+    MODIFY COLUMN `redirect_uris` json NOT NULL,
+    DROP COLUMN `redirect`;
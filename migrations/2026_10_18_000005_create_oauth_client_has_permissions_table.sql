@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS `oauth_client_has_permissions` (
+    `m_oauth_client_id` char(36) NOT NULL,
+    `permission_id` char(36) NOT NULL,
+    PRIMARY KEY (`m_oauth_client_id`, `permission_id`),
+    KEY `oauth_client_has_permissions_permission_id_index` (`permission_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
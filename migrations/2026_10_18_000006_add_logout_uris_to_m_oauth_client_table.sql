@@ -0,0 +1,3 @@
+ALTER TABLE `m_oauth_client`
+    ADD COLUMN `frontchannel_logout_uri` varchar(255) NULL AFTER `scopes`,
+    ADD COLUMN `backchannel_logout_uri` varchar(255) NULL AFTER `frontchannel_logout_uri`;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS `user_two_factors` (
+    `m_user_id` char(36) NOT NULL,
+    `secret` varchar(64) NOT NULL,
+    `recovery_codes` json NULL,
+    `confirmed_at` timestamp NULL DEFAULT NULL,
+    `created_at` timestamp NULL DEFAULT NULL,
+    `updated_at` timestamp NULL DEFAULT NULL,
+    PRIMARY KEY (`m_user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE `roles`
+    ADD COLUMN `requires_two_factor` tinyint(1) NOT NULL DEFAULT 0 AFTER `guard_name`;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS `webauthn_credentials` (
+    `id` char(36) NOT NULL,
+    `m_user_id` char(36) NOT NULL,
+    `name` varchar(255) NOT NULL,
+    `credential_id` varchar(1024) NOT NULL,
+    `public_key` text NOT NULL,
+    `algorithm` int NOT NULL,
+    `sign_count` int unsigned NOT NULL DEFAULT 0,
+    `aaguid` char(36) NULL,
+    `transports` json NULL,
+    `last_used_at` timestamp NULL DEFAULT NULL,
+    `created_at` timestamp NULL DEFAULT NULL,
+    `updated_at` timestamp NULL DEFAULT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `webauthn_credentials_credential_id_unique` (`credential_id`(255)),
+    KEY `webauthn_credentials_m_user_id_index` (`m_user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
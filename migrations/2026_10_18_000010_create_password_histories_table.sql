@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS `password_histories` (
+    `id` char(36) NOT NULL,
+    `m_user_id` char(36) NOT NULL,
+    `password` varchar(255) NOT NULL,
+    `created_at` timestamp NULL DEFAULT NULL,
+    PRIMARY KEY (`id`),
+    KEY `password_histories_m_user_id_created_at_index` (`m_user_id`, `created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,2 @@
+INSERT IGNORE INTO `permissions` (`uuid`, `name`, `guard_name`, `created_at`, `updated_at`)
+VALUES (UUID(), 'send-verification-emails', 'api', NOW(), NOW());
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS `external_identities` (
+    `id` char(36) NOT NULL,
+    `m_user_id` char(36) NOT NULL,
+    `provider` varchar(50) NOT NULL,
+    `subject` varchar(255) NOT NULL,
+    `email` varchar(255) NULL,
+    `last_login_at` timestamp NULL DEFAULT NULL,
+    `created_at` timestamp NULL DEFAULT NULL,
+    `updated_at` timestamp NULL DEFAULT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `external_identities_provider_subject_unique` (`provider`, `subject`),
+    UNIQUE KEY `external_identities_m_user_id_provider_unique` (`m_user_id`, `provider`),
+    KEY `external_identities_m_user_id_index` (`m_user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
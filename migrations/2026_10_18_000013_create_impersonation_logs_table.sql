@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS `impersonation_logs` (
+    `id` char(36) NOT NULL,
+    `impersonator_id` char(36) NOT NULL,
+    `m_user_id` char(36) NOT NULL,
+    `reason` varchar(500) NOT NULL,
+    `ip_address` varchar(45) NULL,
+    `user_agent` varchar(500) NULL,
+    `started_at` timestamp NOT NULL,
+    `expires_at` timestamp NOT NULL,
+    `ended_at` timestamp NULL DEFAULT NULL,
+    `end_reason` varchar(20) NULL,
+    PRIMARY KEY (`id`),
+    KEY `impersonation_logs_impersonator_id_index` (`impersonator_id`),
+    KEY `impersonation_logs_m_user_id_index` (`m_user_id`),
+    KEY `impersonation_logs_started_at_index` (`started_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+INSERT IGNORE INTO `permissions` (`uuid`, `name`, `guard_name`, `created_at`, `updated_at`)
+VALUES (UUID(), 'impersonate-users', 'api', NOW(), NOW()),
+       (UUID(), 'view-impersonation-logs', 'api', NOW(), NOW());
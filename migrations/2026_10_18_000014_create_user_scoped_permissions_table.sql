@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS `user_scoped_permissions` (
+    `id` char(36) NOT NULL,
+    `m_user_id` char(36) NOT NULL,
+    `permission_id` char(36) NOT NULL,
+    `scope_type` enum('major','study_program','lab') NOT NULL,
+    `scope_id` char(36) NOT NULL,
+    `created_at` timestamp NULL DEFAULT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `user_scoped_permissions_unique` (`m_user_id`, `permission_id`, `scope_type`, `scope_id`),
+    KEY `user_scoped_permissions_permission_id_index` (`permission_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS `personal_access_tokens` (
+    `id` char(36) NOT NULL,
+    `m_user_id` char(36) NOT NULL,
+    `name` varchar(255) NOT NULL,
+    `token_hash` char(64) NOT NULL,
+    `permissions` json NOT NULL,
+    `last_used_at` timestamp NULL DEFAULT NULL,
+    `expires_at` timestamp NULL DEFAULT NULL,
+    `created_at` timestamp NULL DEFAULT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `personal_access_tokens_token_hash_unique` (`token_hash`),
+    KEY `personal_access_tokens_m_user_id_index` (`m_user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
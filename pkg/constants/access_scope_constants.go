@@ -0,0 +1,26 @@
+package constants
+
+// Jenis scope untuk permission yang hanya berlaku pada sebagian data. Scope
+// jurusan ikut mencakup semua program studi dan lab di jurusan tersebut.
+const (
+	AccessScopeMajor        = "major"
+	AccessScopeStudyProgram = "study_program"
+	AccessScopeLab          = "lab"
+)
+
+// ScopablePermissions adalah permission yang handler-nya membatasi data sesuai
+// access scope. Permission lain tidak boleh diberikan secara ber-scope karena
+// grant tersebut akan berlaku seperti grant global.
+var ScopablePermissions = []string{
+	"view-students",
+	"create-students",
+	"send-verification-emails",
+	"view-subjects",
+	"create-subjects",
+	"update-subjects",
+	"delete-subjects",
+	"view-labs",
+	"create-labs",
+	"update-labs",
+	"delete-labs",
+}
@@ -7,4 +7,9 @@ const (
 	DEFAULT_AVATAR    = "avatar-1.png"
 	CALLBACK_FRONTEND = "/auth/callback"
 	CSRF_ID_TOKEN     = "csrf_sid"
+	TOTP_ISSUER       = "JTI Super App"
 )
+
+// LINKED_ACCOUNTS_FRONTEND adalah halaman profil frontend yang menerima hasil
+// penautan identitas eksternal (?link_ticket=... atau ?error=...).
+const LINKED_ACCOUNTS_FRONTEND = "/profile/linked-accounts"
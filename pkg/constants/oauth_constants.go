@@ -0,0 +1,19 @@
+package constants
+
+const (
+	ScopeOpenID   = "openid"
+	ScopeProfile  = "profile"
+	ScopeEmail    = "email"
+	ScopeAcademic = "academic"
+	ScopeRoles    = "roles"
+)
+
+// OauthScopeDescriptions berisi scope yang bisa diberikan ke OAuth client beserta
+// penjelasan yang ditampilkan di halaman consent.
+var OauthScopeDescriptions = map[string]string{
+	ScopeOpenID:   "Mengenali akun Anda di JTI Super App",
+	ScopeProfile:  "Melihat nama dan profil dasar Anda",
+	ScopeEmail:    "Melihat alamat email Anda",
+	ScopeAcademic: "Melihat data akademik Anda (NIM/NIP, program studi, jurusan)",
+	ScopeRoles:    "Melihat role Anda",
+}
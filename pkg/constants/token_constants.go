@@ -0,0 +1,23 @@
+package constants
+
+// Restriction pada access token membatasi token hanya untuk endpoint tertentu
+// sampai user menyelesaikan tindakan yang diwajibkan.
+const (
+	RestrictionTwoFactorEnrollment = "two_factor_enrollment"
+	// RestrictionPasswordChange dipasang selama password default (NIM/NIP) belum diganti.
+	RestrictionPasswordChange = "password_change"
+)
+
+// Metode yang bisa menjawab challenge 2FA saat login.
+const (
+	TwoFactorMethodTOTP    = "totp"
+	TwoFactorMethodPasskey = "passkey"
+)
+
+// PermissionImpersonateUsers mengizinkan admin menerbitkan token atas nama
+// user lain. User yang memiliki permission ini tidak bisa di-impersonate.
+const PermissionImpersonateUsers = "impersonate-users"
+
+// PersonalAccessTokenPrefix membedakan personal access token dari JWT di
+// header Authorization sekaligus memudahkan secret scanner mengenalinya.
+const PersonalAccessTokenPrefix = "jti_pat_"
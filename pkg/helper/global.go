@@ -45,10 +45,12 @@ func GenCode() string {
 	return b64url(b)
 }
 
+// RedirectBackToLogin mengarahkan kembali ke halaman login. returnTo hanya
+// dipertahankan jika berupa path lokal atau berada di origin issuer.
 func RedirectBackToLogin(c *gin.Context, loginPath, returnTo, issuer, errMsg string) {
-	// if !IsSafeReturnTo(returnTo, issuer) {
-	// 	returnTo = "/oauth/authorize" // fallback aman
-	// }
+	if !IsSafeReturnTo(returnTo, []string{Origin(issuer)}) {
+		returnTo = "/"
+	}
 	u := url.URL{Path: loginPath}
 	q := u.Query()
 	q.Set("return_to", returnTo)
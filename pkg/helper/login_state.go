@@ -0,0 +1,46 @@
+package helper
+
+import (
+	"jti-super-app-go/config"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// LoginBrowserCookie mengikat transaksi login ke browser yang memulainya.
+const LoginBrowserCookie = "login_bid"
+
+// LoginBrowserID mengembalikan ID browser dari cookie, membuat yang baru bila
+// belum ada. Umur cookie selalu diperpanjang agar tidak habis sebelum transaksi.
+func LoginBrowserID(c *gin.Context, maxAgeSeconds int) string {
+	id, ok := GetLoginBrowserID(c)
+	if !ok {
+		id = uuid.NewString()
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(LoginBrowserCookie, id, maxAgeSeconds, "/", config.AppConfig.CookieDomain, true, true)
+	return id
+}
+
+func GetLoginBrowserID(c *gin.Context) (string, bool) {
+	id, err := c.Cookie(LoginBrowserCookie)
+	if err != nil || id == "" {
+		return "", false
+	}
+	return id, true
+}
+
+// RedirectToLogin mengarahkan ke halaman login dengan state transaksi yang sedang berjalan.
+func RedirectToLogin(c *gin.Context, state, errMsg string) {
+	q := url.Values{}
+	if state != "" {
+		q.Set("state", state)
+	}
+	if errMsg != "" {
+		q.Set("error", b64url([]byte(errMsg)))
+	}
+	c.Redirect(http.StatusSeeOther, "/login?"+q.Encode())
+}
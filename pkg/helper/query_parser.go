@@ -55,5 +55,17 @@ func ParsePaginationQuery(c *gin.Context) (*dto.QueryParams, bool) {
 		Sort:    SanitizeInput(sort),
 		Order:   order,
 		Filter:  filter,
+		Scope:   AccessScope(c),
 	}, true
 }
+
+// AccessScope mengembalikan batas data pemanggil yang ditetapkan policy route
+// saat permission-nya hanya berlaku untuk sebagian data. Nil berarti tanpa batas.
+func AccessScope(c *gin.Context) *dto.AccessScope {
+	value, ok := c.Get("access_scope")
+	if !ok {
+		return nil
+	}
+	scope, _ := value.(*dto.AccessScope)
+	return scope
+}
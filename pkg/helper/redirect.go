@@ -0,0 +1,41 @@
+package helper
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// IsSafeReturnTo memastikan tujuan redirect tidak membuka celah open redirect.
+// Path relatif di domain sendiri selalu diizinkan, sedangkan URL absolut hanya
+// diizinkan jika origin-nya termasuk dalam allowedOrigins.
+func IsSafeReturnTo(returnTo string, allowedOrigins []string) bool {
+	if returnTo == "" || strings.ContainsAny(returnTo, "\\\r\n\t") {
+		return false
+	}
+
+	u, err := url.Parse(returnTo)
+	if err != nil {
+		return false
+	}
+
+	// "/path" aman, tetapi "//evil.com" adalah URL absolut tanpa skema
+	if u.Scheme == "" && u.Host == "" {
+		return strings.HasPrefix(returnTo, "/") && !strings.HasPrefix(returnTo, "//")
+	}
+
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return false
+	}
+
+	return slices.Contains(allowedOrigins, Origin(returnTo))
+}
+
+// Origin mengembalikan skema dan host (termasuk port) dari sebuah URL absolut.
+func Origin(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return ""
+	}
+	return strings.ToLower(u.Scheme + "://" + u.Host)
+}
@@ -3,6 +3,8 @@ package helper
 import (
 	"errors"
 	"jti-super-app-go/internal/dto"
+	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -31,4 +33,26 @@ func ErrorResponse(c *gin.Context, statusCode int, message string, err error) {
 		Message: message,
 		Errors:  map[string]string{"error": err.Error()},
 	})
-}
\ No newline at end of file
+}
+
+// PasswordPolicyErrorResponse menulis 422 dengan daftar pelanggaran password policy.
+func PasswordPolicyErrorResponse(c *gin.Context, violations []dto.PasswordViolationDTO) {
+	messages := make([]string, len(violations))
+	for i, v := range violations {
+		messages[i] = v.Message
+	}
+
+	c.AbortWithStatusJSON(http.StatusUnprocessableEntity, dto.PasswordPolicyErrorResponse{
+		Message:    "Password does not meet the password policy",
+		Errors:     map[string]string{"password": strings.Join(messages, "; ")},
+		Violations: violations,
+	})
+}
+
+func OauthErrorResponse(c *gin.Context, statusCode int, code, description string) {
+	c.Header("Cache-Control", "no-store")
+	c.AbortWithStatusJSON(statusCode, dto.OauthErrorResponseDTO{
+		Error:            code,
+		ErrorDescription: description,
+	})
+}
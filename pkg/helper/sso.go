@@ -14,17 +14,19 @@ import (
 
 const CookieName = "sso_session"
 
-func SetSSO(c *gin.Context, sub *dto.LoginResponseDTO, maxAgeSeconds int) {
+// SetSSO menyimpan data login di Redis dengan kunci ID sesi, sehingga setiap
+// perangkat punya sesi SSO sendiri.
+func SetSSO(c *gin.Context, sessionID string, sub *dto.LoginResponseDTO, maxAgeSeconds int) {
 	b, _ := json.Marshal(sub)
 
-	// store b to redis with key sub.User.ID and expiry maxAgeSeconds
-	err := config.Rdb.Set(c.Request.Context(), "sso:"+sub.User.ID, b, time.Duration(maxAgeSeconds)*time.Second).Err()
+	// store b to redis with key sessionID and expiry maxAgeSeconds
+	err := config.Rdb.Set(c.Request.Context(), "sso:"+sessionID, b, time.Duration(maxAgeSeconds)*time.Second).Err()
 	if err != nil {
 		// log error, tapi tidak perlu di-handle lebih lanjut
 		fmt.Println("Failed to store SSO session in Redis:", err)
 	}
 
-	val := base64.RawURLEncoding.EncodeToString([]byte(sub.User.ID))
+	val := base64.RawURLEncoding.EncodeToString([]byte(sessionID))
 
 	c.SetSameSite(http.SameSiteLaxMode)
 	c.SetCookie(
@@ -38,6 +40,7 @@ func SetSSO(c *gin.Context, sub *dto.LoginResponseDTO, maxAgeSeconds int) {
 	)
 }
 
+// GetSSO mengembalikan ID sesi SSO dari cookie.
 func GetSSO(c *gin.Context) (string, bool) {
 	val, err := c.Cookie(CookieName)
 	if err != nil || val == "" {
@@ -50,6 +53,25 @@ func GetSSO(c *gin.Context) (string, bool) {
 	return string(b), true
 }
 
+// GetSSOUser membaca cookie SSO beserta data login yang tersimpan di Redis.
+func GetSSOUser(c *gin.Context) (string, *dto.LoginResponseDTO, bool) {
+	sessionID, ok := GetSSO(c)
+	if !ok {
+		return "", nil, false
+	}
+
+	data, err := config.Rdb.Get(c.Request.Context(), "sso:"+sessionID).Result()
+	if err != nil {
+		return "", nil, false
+	}
+
+	var user dto.LoginResponseDTO
+	if err := json.Unmarshal([]byte(data), &user); err != nil {
+		return "", nil, false
+	}
+	return sessionID, &user, true
+}
+
 func ClearSSO(c *gin.Context) {
 	c.SetSameSite(http.SameSiteLaxMode)
 	c.SetCookie(CookieName, "", -1, "/", config.AppConfig.CookieDomain, true, true)
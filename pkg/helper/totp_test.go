@@ -0,0 +1,101 @@
+package helper
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Secret ASCII "12345678901234567890" dari RFC 6238 Appendix B dalam base32.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeRFC6238Vectors(t *testing.T) {
+	// Vektor SHA1 RFC 6238 berisi 8 digit; kode 6 digit adalah enam digit terakhirnya.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "94287082"},
+		{unix: 1111111109, want: "07081804"},
+		{unix: 1111111111, want: "14050471"},
+		{unix: 1234567890, want: "89005924"},
+		{unix: 2000000000, want: "69279037"},
+		{unix: 20000000000, want: "65353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := TOTPCode(rfc6238Secret, TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("TOTPCode() error = %v", err)
+		}
+		if want := tt.want[len(tt.want)-TOTPDigits:]; got != want {
+			t.Errorf("TOTPCode(T=%d) = %s, want %s", tt.unix, got, want)
+		}
+	}
+}
+
+func TestTOTPCodeAcceptsLowercaseAndPaddedSecret(t *testing.T) {
+	want, _ := TOTPCode(rfc6238Secret, 1)
+	for _, secret := range []string{strings.ToLower(rfc6238Secret), rfc6238Secret + "===="} {
+		if got, err := TOTPCode(secret, 1); err != nil || got != want {
+			t.Errorf("TOTPCode(%q) = %s, %v, want %s", secret, got, err, want)
+		}
+	}
+}
+
+func TestTOTPCodeRejectsInvalidSecret(t *testing.T) {
+	if _, err := TOTPCode("not base32!", 1); err == nil {
+		t.Fatal("TOTPCode() expected an error for an invalid secret")
+	}
+}
+
+func TestValidateTOTPWindow(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := TOTPStep(now)
+
+	tests := []struct {
+		name   string
+		offset int64
+		want   bool
+	}{
+		{name: "current step", offset: 0, want: true},
+		{name: "previous step", offset: -TOTPSkew, want: true},
+		{name: "next step", offset: TOTPSkew, want: true},
+		{name: "too old", offset: -TOTPSkew - 1, want: false},
+		{name: "too far ahead", offset: TOTPSkew + 1, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := TOTPCode(rfc6238Secret, current+tt.offset)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			step, ok := ValidateTOTP(rfc6238Secret, code, now)
+			if ok != tt.want {
+				t.Fatalf("ValidateTOTP() ok = %v, want %v", ok, tt.want)
+			}
+			if ok && step != current+tt.offset {
+				t.Errorf("ValidateTOTP() step = %d, want %d", step, current+tt.offset)
+			}
+		})
+	}
+}
+
+func TestValidateTOTPRejectsMalformedCodes(t *testing.T) {
+	now := time.Unix(59, 0)
+	code, _ := TOTPCode(rfc6238Secret, TOTPStep(now))
+
+	if _, ok := ValidateTOTP(rfc6238Secret, " "+code+" ", now); !ok {
+		t.Error("ValidateTOTP() should trim surrounding whitespace")
+	}
+	for _, bad := range []string{"", code[:TOTPDigits-1], code + "0", "abcdef"} {
+		if _, ok := ValidateTOTP(rfc6238Secret, bad, now); ok {
+			t.Errorf("ValidateTOTP(%q) accepted an invalid code", bad)
+		}
+	}
+	if _, ok := ValidateTOTP("not base32!", code, now); ok {
+		t.Error("ValidateTOTP() accepted a code for an invalid secret")
+	}
+}
@@ -0,0 +1,59 @@
+package helper
+
+import (
+	"jti-super-app-go/internal/dto"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SessionMeta mengambil IP dan user agent request untuk dicatat di registry sesi.
+func SessionMeta(c *gin.Context) dto.SessionMetaDTO {
+	return dto.SessionMetaDTO{
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
+
+// DeviceFromUserAgent membuat label perangkat sederhana seperti "Chrome di Windows"
+// untuk ditampilkan di daftar sesi. Tidak dimaksudkan sebagai parser lengkap.
+func DeviceFromUserAgent(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	if ua == "" {
+		return "Perangkat tidak dikenal"
+	}
+
+	browser := "Browser lain"
+	switch {
+	case strings.Contains(ua, "edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "opr/") || strings.Contains(ua, "opera"):
+		browser = "Opera"
+	case strings.Contains(ua, "firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "chrome/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "safari/"):
+		browser = "Safari"
+	case strings.Contains(ua, "okhttp") || strings.Contains(ua, "dart/"):
+		browser = "Aplikasi mobile"
+	case strings.Contains(ua, "postman") || strings.Contains(ua, "curl/"):
+		browser = "Klien API"
+	}
+
+	os := "OS lain"
+	switch {
+	case strings.Contains(ua, "android"):
+		os = "Android"
+	case strings.Contains(ua, "iphone") || strings.Contains(ua, "ipad"):
+		os = "iOS"
+	case strings.Contains(ua, "windows"):
+		os = "Windows"
+	case strings.Contains(ua, "mac os"):
+		os = "macOS"
+	case strings.Contains(ua, "linux"):
+		os = "Linux"
+	}
+
+	return browser + " di " + os
+}
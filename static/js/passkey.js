@@ -0,0 +1,67 @@
+// Helper WebAuthn untuk halaman login. Server mengirim dan menerima nilai biner
+// dalam base64url, sedangkan navigator.credentials memakai ArrayBuffer.
+(function (window) {
+  function b64urlToBuffer(value) {
+    const base64 = value.replace(/-/g, '+').replace(/_/g, '/');
+    const padded = base64 + '='.repeat((4 - (base64.length % 4)) % 4);
+    return Uint8Array.from(atob(padded), c => c.charCodeAt(0)).buffer;
+  }
+
+  function bufferToB64url(buffer) {
+    const bytes = new Uint8Array(buffer);
+    let binary = '';
+    bytes.forEach(b => { binary += String.fromCharCode(b); });
+    return btoa(binary).replace(/\+/g, '-').replace(/\//g, '_').replace(/=+$/, '');
+  }
+
+  async function postJSON(url, body) {
+    const res = await fetch(url, {
+      method: 'POST',
+      credentials: 'same-origin',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify(body),
+    });
+    const json = await res.json().catch(() => ({}));
+    if (!res.ok) {
+      throw new Error(json.message || 'Permintaan gagal');
+    }
+    return json.data;
+  }
+
+  function toRequestOptions(options) {
+    return Object.assign({}, options, {
+      challenge: b64urlToBuffer(options.challenge),
+      allowCredentials: (options.allowCredentials || []).map(c => Object.assign({}, c, { id: b64urlToBuffer(c.id) })),
+    });
+  }
+
+  function serializeAssertion(credential) {
+    return {
+      id: credential.id,
+      rawId: bufferToB64url(credential.rawId),
+      type: credential.type,
+      response: {
+        clientDataJSON: bufferToB64url(credential.response.clientDataJSON),
+        authenticatorData: bufferToB64url(credential.response.authenticatorData),
+        signature: bufferToB64url(credential.response.signature),
+        userHandle: credential.response.userHandle ? bufferToB64url(credential.response.userHandle) : '',
+      },
+    };
+  }
+
+  // authenticate menjalankan ceremony assertion: beginUrl mengembalikan options,
+  // lalu hasilnya dikirim ke finishUrl bersama extra. Mengembalikan data respons finish.
+  async function authenticate(beginUrl, beginBody, finishUrl, extra) {
+    if (!window.PublicKeyCredential) {
+      throw new Error('Browser ini tidak mendukung passkey');
+    }
+    const begin = await postJSON(beginUrl, beginBody);
+    const credential = await navigator.credentials.get({ publicKey: toRequestOptions(begin.public_key) });
+    return postJSON(finishUrl, Object.assign({}, extra, {
+      session_id: begin.session_id,
+      credential: serializeAssertion(credential),
+    }));
+  }
+
+  window.Passkey = { authenticate: authenticate, supported: () => !!window.PublicKeyCredential };
+})(window);